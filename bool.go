@@ -10,8 +10,10 @@ type BoolField struct {
 // The constructor for the protobuf boolean field.
 func Bool(name string) *BoolField {
 	bf := &BoolField{}
+	rules := make(map[string]any)
+	options := make(map[string]any)
 	internal := &protoFieldInternal{
-		name: name, protoType: "bool", goType: "bool",
+		name: name, protoType: "bool", goType: "bool", options: options, rules: rules,
 	}
 	bf.ProtoField = &ProtoField[BoolField]{internal, bf}
 	bf.ConstField = &ConstField[BoolField, bool, bool]{constInternal: internal, self: bf}
@@ -0,0 +1,302 @@
+package protoschema
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// A rule that detects a specific kind of breaking change between two snapshots.
+type BreakingRule string
+
+// Rules for changes that break the wire or JSON compatibility, which means that clients and servers using different versions of the schema will not be able to exchange messages correctly.
+const (
+	FieldNumberChanged         BreakingRule = "FIELD_NUMBER_CHANGED"
+	FieldTypeChanged           BreakingRule = "FIELD_TYPE_CHANGED"
+	FieldCardinalityChanged    BreakingRule = "FIELD_CARDINALITY_CHANGED"
+	FieldOneofChanged          BreakingRule = "FIELD_ONEOF_CHANGED"
+	FieldDeletedUnreserved     BreakingRule = "FIELD_DELETED_UNRESERVED"
+	EnumValueRenamed           BreakingRule = "ENUM_VALUE_RENAMED"
+	EnumValueDeletedUnreserved BreakingRule = "ENUM_VALUE_DELETED_UNRESERVED"
+	ServiceDeleted             BreakingRule = "SERVICE_DELETED"
+	RPCDeleted                 BreakingRule = "RPC_DELETED"
+	RPCRequestTypeChanged      BreakingRule = "RPC_REQUEST_TYPE_CHANGED"
+	RPCResponseTypeChanged     BreakingRule = "RPC_RESPONSE_TYPE_CHANGED"
+)
+
+// Rules for changes that do not affect the wire format, but break the code generated from the schema or the code that depends on it.
+const (
+	FieldRenamed   BreakingRule = "FIELD_RENAMED"
+	MessageDeleted BreakingRule = "MESSAGE_DELETED"
+	MessageMoved   BreakingRule = "MESSAGE_MOVED"
+	EnumDeleted    BreakingRule = "ENUM_DELETED"
+	EnumMoved      BreakingRule = "ENUM_MOVED"
+)
+
+// The severity of a breaking change.
+type Severity int
+
+const (
+	// Changes with this severity are not reported.
+	SeverityIgnore Severity = iota
+	// Changes with this severity are reported, but they do not stop the generation.
+	SeverityWarning
+	// Changes with this severity stop the generation.
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityIgnore:
+		return "ignore"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// Maps each rule to the severity that should be used for the changes that it detects. Rules that are not in the map use the default severity.
+type BreakingPolicy map[BreakingRule]Severity
+
+// The default severities for each rule. Wire-breaking changes are errors, and source-breaking changes are warnings.
+var DefaultBreakingPolicy = BreakingPolicy{
+	FieldNumberChanged:         SeverityError,
+	FieldTypeChanged:           SeverityError,
+	FieldCardinalityChanged:    SeverityError,
+	FieldOneofChanged:          SeverityError,
+	FieldDeletedUnreserved:     SeverityError,
+	EnumValueRenamed:           SeverityError,
+	EnumValueDeletedUnreserved: SeverityError,
+	ServiceDeleted:             SeverityError,
+	RPCDeleted:                 SeverityError,
+	RPCRequestTypeChanged:      SeverityError,
+	RPCResponseTypeChanged:     SeverityError,
+	FieldRenamed:               SeverityWarning,
+	MessageDeleted:             SeverityWarning,
+	MessageMoved:               SeverityWarning,
+	EnumDeleted:                SeverityWarning,
+	EnumMoved:                  SeverityWarning,
+}
+
+// Returns the severity for the given rule, falling back to the default policy if the rule is not defined in this one.
+func (bp BreakingPolicy) Severity(rule BreakingRule) Severity {
+	if s, exists := bp[rule]; exists {
+		return s
+	}
+
+	return DefaultBreakingPolicy[rule]
+}
+
+// A breaking change detected between two snapshots.
+type BreakingChange struct {
+	Rule     BreakingRule
+	Severity Severity
+	// The fully qualified name of the element affected by the change (i.e. "myapp.v1.User.name").
+	Element string
+	Message string
+}
+
+func (bc BreakingChange) String() string {
+	return fmt.Sprintf("[%s] %s: %s", bc.Rule, bc.Element, bc.Message)
+}
+
+// Compares two snapshots and returns the breaking changes that would be introduced by going from the previous snapshot to the current one.
+// Changes whose rule has the ignore severity in the given policy are omitted. If the previous snapshot is nil, no changes are returned.
+func CompareSnapshots(prev, cur *SchemaSnapshot, policy BreakingPolicy) []BreakingChange {
+	var changes []BreakingChange
+
+	if prev == nil || cur == nil {
+		return changes
+	}
+
+	report := func(rule BreakingRule, element, msg string, args ...any) {
+		if severity := policy.Severity(rule); severity != SeverityIgnore {
+			changes = append(changes, BreakingChange{Rule: rule, Severity: severity, Element: element, Message: fmt.Sprintf(msg, args...)})
+		}
+	}
+
+	for _, file := range prev.Files {
+		for _, prevMsg := range file.Messages {
+			fullName := qualifiedName(file.Package, prevMsg.Name)
+			curMsg, curFile := cur.findMessage(fullName)
+
+			if curMsg == nil {
+				report(MessageDeleted, fullName, "Message was deleted.")
+				continue
+			}
+
+			if curFile != file.Name {
+				report(MessageMoved, fullName, "Message was moved from %q to %q.", file.Name, curFile)
+			}
+
+			compareFields(fullName, prevMsg, *curMsg, report)
+		}
+
+		for _, prevEnum := range file.Enums {
+			fullName := qualifiedName(file.Package, prevEnum.Name)
+			curEnum, curFile := cur.findEnum(fullName)
+
+			if curEnum == nil {
+				report(EnumDeleted, fullName, "Enum was deleted.")
+				continue
+			}
+
+			if curFile != file.Name {
+				report(EnumMoved, fullName, "Enum was moved from %q to %q.", file.Name, curFile)
+			}
+
+			compareEnumValues(fullName, prevEnum, *curEnum, report)
+		}
+
+		for _, prevService := range file.Services {
+			fullName := qualifiedName(file.Package, prevService.Name)
+			curService := cur.findService(fullName)
+
+			if curService == nil {
+				report(ServiceDeleted, fullName, "Service was deleted.")
+				continue
+			}
+
+			for _, prevMethod := range prevService.Methods {
+				methodName := fullName + "." + prevMethod.Name
+				idx := slices.IndexFunc(curService.Methods, func(m MethodSnapshot) bool { return m.Name == prevMethod.Name })
+
+				if idx == -1 {
+					report(RPCDeleted, methodName, "RPC was deleted.")
+					continue
+				}
+
+				curMethod := curService.Methods[idx]
+
+				if curMethod.Request != prevMethod.Request {
+					report(RPCRequestTypeChanged, methodName, "Request type changed from %q to %q.", prevMethod.Request, curMethod.Request)
+				}
+
+				if curMethod.Response != prevMethod.Response {
+					report(RPCResponseTypeChanged, methodName, "Response type changed from %q to %q.", prevMethod.Response, curMethod.Response)
+				}
+			}
+		}
+	}
+
+	return changes
+}
+
+func compareFields(msgName string, prev, cur MessageSnapshot, report func(BreakingRule, string, string, ...any)) {
+	for _, prevField := range prev.Fields {
+		element := msgName + "." + prevField.Name
+
+		byNumber := slices.IndexFunc(cur.Fields, func(f FieldSnapshot) bool { return f.Number == prevField.Number })
+		byName := slices.IndexFunc(cur.Fields, func(f FieldSnapshot) bool { return f.Name == prevField.Name })
+
+		// The old number of a renumbered field is still compared with the field that might have taken it, since the same wire number is decoded with the new type
+		renumbered := byName != -1 && cur.Fields[byName].Number != prevField.Number
+		if renumbered {
+			report(FieldNumberChanged, element, "Field number changed from %d to %d.", prevField.Number, cur.Fields[byName].Number)
+		}
+
+		if byNumber == -1 {
+			if renumbered {
+				continue
+			}
+
+			_, inReservedRange := findRange(int64(prevField.Number), cur.ReservedRanges)
+			if !slices.Contains(cur.ReservedNumbers, prevField.Number) && !inReservedRange {
+				report(FieldDeletedUnreserved, element, "Field %d was deleted without reserving its number.", prevField.Number)
+			}
+			continue
+		}
+
+		curField := cur.Fields[byNumber]
+
+		if curField.Name != prevField.Name && !renumbered {
+			report(FieldRenamed, element, "Field %d was renamed from %q to %q.", prevField.Number, prevField.Name, curField.Name)
+		}
+
+		if curField.Type != prevField.Type {
+			report(FieldTypeChanged, element, "Field %d changed type from %q to %q.", prevField.Number, prevField.Type, curField.Type)
+		}
+
+		if curField.Cardinality != prevField.Cardinality {
+			report(FieldCardinalityChanged, element, "Field %d changed cardinality from %s to %s.", prevField.Number, cardinalityLabel(prevField.Cardinality), cardinalityLabel(curField.Cardinality))
+		}
+
+		if curField.Oneof != prevField.Oneof {
+			report(FieldOneofChanged, element, "Field %d moved from oneof %q to oneof %q.", prevField.Number, prevField.Oneof, curField.Oneof)
+		}
+	}
+}
+
+func compareEnumValues(enumName string, prev, cur EnumSnapshot, report func(BreakingRule, string, string, ...any)) {
+	for _, prevValue := range prev.Values {
+		element := enumName + "." + prevValue.Name
+
+		if slices.Contains(cur.Values, prevValue) {
+			continue
+		}
+
+		idx := slices.IndexFunc(cur.Values, func(v EnumValueSnapshot) bool { return v.Number == prevValue.Number })
+
+		if idx == -1 {
+			_, inReservedRange := findRange(int64(prevValue.Number), cur.ReservedRanges)
+			if !slices.Contains(cur.ReservedNumbers, prevValue.Number) && !inReservedRange {
+				report(EnumValueDeletedUnreserved, element, "Enum value %d was deleted without reserving its number.", prevValue.Number)
+			}
+			continue
+		}
+
+		if curValue := cur.Values[idx]; curValue.Name != prevValue.Name {
+			report(EnumValueRenamed, element, "Enum value %d was renamed from %q to %q.", prevValue.Number, prevValue.Name, curValue.Name)
+		}
+	}
+}
+
+func cardinalityLabel(c string) string {
+	if c == "" {
+		return "singular"
+	}
+
+	return c
+}
+
+// Compares the current schema of this package with the snapshot stored in the package's snapshot file, and returns the detected breaking changes.
+// If no snapshot file was defined or the file does not exist yet, it returns no changes.
+func (p *ProtoPackage) CheckBreaking() ([]BreakingChange, error) {
+	filesData, err := p.TryBuildFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.checkBreaking(NewSnapshot(filesData))
+}
+
+func (p *ProtoPackage) checkBreaking(cur *SchemaSnapshot) ([]BreakingChange, error) {
+	if p.snapshotFilePath == "" {
+		return nil, nil
+	}
+
+	prev, err := LoadSnapshot(p.snapshotFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return CompareSnapshots(prev, cur, p.breakingPolicy), nil
+}
+
+// Prints the warnings and returns an error that lists all the changes with the error severity, if there are any.
+func reportBreakingChanges(changes []BreakingChange) error {
+	var errs error
+
+	for _, c := range changes {
+		if c.Severity == SeverityError {
+			errs = errors.Join(errs, errors.New(c.String()))
+		} else {
+			fmt.Printf("Warning: breaking change %s\n", c.String())
+		}
+	}
+
+	return issuesError(indentErrors("Breaking changes detected", errs))
+}
@@ -0,0 +1,117 @@
+package protoschema_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreakingChanges(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	newPackage := func(fields sb.FieldsMap, reserved []uint, members sb.EnumMembers, policy sb.BreakingPolicy) *sb.ProtoPackage {
+		pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+			Name:               "breaking.v1",
+			GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/breakingv1",
+			ProtoRoot:          tmpDir,
+			ConverterOutputDir: filepath.Join(tmpDir, "converter"),
+			SnapshotFile:       filepath.Join(tmpDir, "snapshot.json"),
+			BreakingPolicy:     policy,
+		})
+
+		file := pkg.NewFile(sb.FileSchema{Name: "breaking"})
+		file.NewMessage(sb.MessageSchema{Name: "Item", Fields: fields, ReservedNumbers: reserved})
+		file.NewEnum(sb.EnumGroup{Name: "Status", Members: members})
+
+		return pkg
+	}
+
+	first := newPackage(
+		sb.FieldsMap{1: sb.Int64("id"), 2: sb.String("name"), 3: sb.Repeated("tags", sb.String("")), 4: sb.String("notes")},
+		nil,
+		sb.EnumMembers{0: "STATUS_UNSPECIFIED", 1: "STATUS_ACTIVE"},
+		nil,
+	)
+
+	changes, err := first.CheckBreaking()
+	assert.NoError(t, err)
+	assert.Empty(t, changes, "There should be no changes without a previous snapshot")
+
+	assert.NoError(t, first.Generate())
+	assert.FileExists(t, first.GetSnapshotFilePath())
+
+	second := newPackage(
+		sb.FieldsMap{1: sb.Int32("id"), 2: sb.String("title"), 3: sb.String("tags")},
+		nil,
+		sb.EnumMembers{0: "STATUS_UNSPECIFIED", 1: "STATUS_ENABLED"},
+		nil,
+	)
+
+	changes, err = second.CheckBreaking()
+	assert.NoError(t, err)
+
+	rules := make(map[sb.BreakingRule]sb.Severity)
+	for _, c := range changes {
+		rules[c.Rule] = c.Severity
+	}
+
+	assert.Equal(t, map[sb.BreakingRule]sb.Severity{
+		sb.FieldTypeChanged:        sb.SeverityError,
+		sb.FieldRenamed:            sb.SeverityWarning,
+		sb.FieldCardinalityChanged: sb.SeverityError,
+		sb.FieldDeletedUnreserved:  sb.SeverityError,
+		sb.EnumValueRenamed:        sb.SeverityError,
+	}, rules)
+
+	assert.Error(t, second.Generate(), "Wire-breaking changes should stop the generation")
+
+	// Reserving the deleted field and relaxing the policy
+	third := newPackage(
+		sb.FieldsMap{1: sb.Int64("id"), 2: sb.String("name"), 3: sb.Repeated("tags", sb.String(""))},
+		[]uint{4},
+		sb.EnumMembers{0: "STATUS_UNSPECIFIED", 1: "STATUS_ENABLED"},
+		sb.BreakingPolicy{sb.EnumValueRenamed: sb.SeverityIgnore},
+	)
+
+	changes, err = third.CheckBreaking()
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+	assert.NoError(t, third.Generate())
+
+	// Swapping the numbers of two fields reports the number changes, along with the changes of the fields that now use the old numbers
+	fourth := newPackage(
+		sb.FieldsMap{1: sb.Int64("id"), 2: sb.Repeated("tags", sb.String("")), 3: sb.String("name")},
+		[]uint{4},
+		sb.EnumMembers{0: "STATUS_UNSPECIFIED", 1: "STATUS_ENABLED"},
+		nil,
+	)
+
+	changes, err = fourth.CheckBreaking()
+	assert.NoError(t, err)
+
+	elements := make(map[string][]sb.BreakingRule)
+	for _, c := range changes {
+		elements[c.Element] = append(elements[c.Element], c.Rule)
+	}
+
+	assert.Equal(t, map[string][]sb.BreakingRule{
+		"breaking.v1.Item.name": {sb.FieldNumberChanged, sb.FieldCardinalityChanged},
+		"breaking.v1.Item.tags": {sb.FieldNumberChanged, sb.FieldCardinalityChanged},
+	}, elements)
+
+	// The errors in the schemas are returned instead of exiting
+	invalid := newPackage(
+		sb.FieldsMap{1: sb.Int64("id"), 2: sb.String("id")},
+		nil,
+		sb.EnumMembers{0: "STATUS_UNSPECIFIED"},
+		nil,
+	)
+
+	_, err = invalid.CheckBreaking()
+	assert.Error(t, err)
+
+	_, err = invalid.Snapshot()
+	assert.Error(t, err)
+}
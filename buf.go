@@ -0,0 +1,220 @@
+package protoschema
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// The configuration for the buf files (buf.yaml and buf.gen.yaml) that can be generated in the ProtoRoot.
+type BufConfig struct {
+	// If true, buf.yaml and buf.gen.yaml are written in the ProtoRoot after each generation.
+	Generate bool
+	// (Default: the directory of the go module, which is found by looking for the go.mod file in the ProtoRoot and in its parent directories) The output directory of the go plugins, relative to the ProtoRoot. It should be the root of the GoModule, since the generated files are placed in it according to their go package.
+	GoOut string
+}
+
+// The modules in the buf registry that contain the files with the given import prefixes.
+var bufDependencies = []struct {
+	prefix string
+	module string
+}{
+	{"buf/validate/", "buf.build/bufbuild/protovalidate"},
+	{"google/api/", "buf.build/googleapis/googleapis"},
+	{"google/type/", "buf.build/googleapis/googleapis"},
+	{"google/rpc/", "buf.build/googleapis/googleapis"},
+	{"google/longrunning/", "buf.build/googleapis/googleapis"},
+	{"google/geo/", "buf.build/googleapis/googleapis"},
+}
+
+// Source-breaking rules which, if set to stop the generation, require the FILE category in buf.
+var sourceBreakingRules = []BreakingRule{FieldRenamed, MessageDeleted, MessageMoved, EnumDeleted, EnumMoved}
+
+// The equivalent rules in buf for the FILE and WIRE_JSON categories.
+var bufBreakingRules = map[BreakingRule]struct{ file, wireJSON string }{
+	FieldTypeChanged:           {"FIELD_SAME_TYPE", "FIELD_WIRE_JSON_COMPATIBLE_TYPE"},
+	FieldCardinalityChanged:    {"FIELD_SAME_CARDINALITY", "FIELD_WIRE_JSON_COMPATIBLE_CARDINALITY"},
+	FieldOneofChanged:          {"FIELD_SAME_ONEOF", "FIELD_SAME_ONEOF"},
+	FieldDeletedUnreserved:     {"FIELD_NO_DELETE", "FIELD_NO_DELETE_UNLESS_NUMBER_RESERVED"},
+	EnumValueRenamed:           {"ENUM_VALUE_SAME_NAME", "ENUM_VALUE_SAME_NAME"},
+	EnumValueDeletedUnreserved: {"ENUM_VALUE_NO_DELETE", "ENUM_VALUE_NO_DELETE_UNLESS_NUMBER_RESERVED"},
+	ServiceDeleted:             {"SERVICE_NO_DELETE", ""},
+	RPCDeleted:                 {"RPC_NO_DELETE", ""},
+	RPCRequestTypeChanged:      {"RPC_SAME_REQUEST_TYPE", "RPC_SAME_REQUEST_TYPE"},
+	RPCResponseTypeChanged:     {"RPC_SAME_RESPONSE_TYPE", "RPC_SAME_RESPONSE_TYPE"},
+	FieldRenamed:               {"FIELD_SAME_NAME", ""},
+	MessageDeleted:             {"MESSAGE_NO_DELETE", ""},
+	EnumDeleted:                {"ENUM_NO_DELETE", ""},
+}
+
+type bufFilesData struct {
+	Deps           []string
+	LintUse        string
+	LintExcept     []string
+	BreakingUse    string
+	BreakingExcept []string
+	GoOut          string
+	GoModule       string
+	Connect        bool
+}
+
+// Returns the buf modules that must be declared as dependencies for the given imports.
+func bufDeps(imports Set) []string {
+	var deps []string
+
+	for importPath := range imports {
+		for _, dep := range bufDependencies {
+			if strings.HasPrefix(importPath, dep.prefix) && !slices.Contains(deps, dep.module) {
+				deps = append(deps, dep.module)
+			}
+		}
+	}
+
+	slices.Sort(deps)
+
+	return deps
+}
+
+// Returns the lint settings for buf that match the given configuration.
+func bufLintSettings(conf LintConfig) (string, []string) {
+	// The linter is disabled, so only the rules that are needed to build the module are used
+	if conf.Skip {
+		return "MINIMAL", nil
+	}
+
+	var except []string
+	for _, rule := range conf.Disable {
+		if !slices.Contains(protoschemaLintRules, rule) {
+			except = append(except, string(rule))
+		}
+	}
+
+	return "STANDARD", except
+}
+
+// Returns the breaking change settings for buf that match the given policy.
+func bufBreakingSettings(policy BreakingPolicy) (string, []string) {
+	use := "WIRE_JSON"
+	for _, rule := range sourceBreakingRules {
+		if policy.Severity(rule) == SeverityError {
+			use = "FILE"
+		}
+	}
+
+	var except []string
+	for rule, bufRules := range bufBreakingRules {
+		bufRule := bufRules.wireJSON
+		if use == "FILE" {
+			bufRule = bufRules.file
+		}
+
+		if bufRule != "" && policy.Severity(rule) == SeverityIgnore && !slices.Contains(except, bufRule) {
+			except = append(except, bufRule)
+		}
+	}
+
+	slices.Sort(except)
+
+	return use, except
+}
+
+// Looks for the go.mod file in the given directory and in its parents, and returns the directory where it is found.
+func findGoModuleRoot(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("Could not find the go.mod file in %q or in its parent directories.", dir)
+		}
+
+		dir = parent
+	}
+}
+
+// Writes the buf.yaml and buf.gen.yaml files for the given packages, which share the same ProtoRoot. The configuration of the first package is used for the settings that are not derived from the files.
+func writeBufFiles(packages []*ProtoPackage, filesData [][]FileData) error {
+	p := packages[0]
+	conf := p.bufConfig
+
+	if p.goModule == "" {
+		return fmt.Errorf("The GoModule must be defined in order to generate the buf files for the package %q.", p.GetName())
+	}
+
+	goOut := conf.GoOut
+	if goOut == "" {
+		moduleRoot, err := findGoModuleRoot(p.protoRoot)
+		if err != nil {
+			return fmt.Errorf("Could not determine the output directory for the go plugins: %w", err)
+		}
+
+		protoRoot, err := filepath.Abs(p.protoRoot)
+		if err != nil {
+			return err
+		}
+
+		if goOut, err = filepath.Rel(protoRoot, moduleRoot); err != nil {
+			return err
+		}
+	}
+
+	imports := make(Set)
+	data := bufFilesData{GoOut: filepath.ToSlash(goOut), GoModule: p.goModule}
+
+	for _, files := range filesData {
+		for _, f := range files {
+			for importPath := range f.Imports {
+				imports[importPath] = present
+			}
+
+			if len(f.Services) > 0 {
+				data.Connect = true
+			}
+		}
+	}
+
+	data.Deps = bufDeps(imports)
+	data.LintUse, data.LintExcept = bufLintSettings(p.lintConfig)
+	data.BreakingUse, data.BreakingExcept = bufBreakingSettings(p.breakingPolicy)
+
+	var err error
+
+	for _, file := range []struct{ name, template string }{{"buf.yaml", "bufYaml"}, {"buf.gen.yaml", "bufGenYaml"}} {
+		var outputBuffer bytes.Buffer
+		if tmplErr := p.tmpl.ExecuteTemplate(&outputBuffer, file.template, data); tmplErr != nil {
+			err = errors.Join(err, fmt.Errorf("Failed to execute template: %w", tmplErr))
+			continue
+		}
+
+		outputPath := filepath.Join(p.protoRoot, file.name)
+
+		written, writeErr := p.writeGeneratedFile(outputPath, outputBuffer.Bytes())
+		if writeErr != nil {
+			err = errors.Join(err, writeErr)
+			continue
+		}
+
+		if !written {
+			continue
+		}
+
+		fmt.Printf("✅ Successfully generated %s at: %s\n", file.name, outputPath)
+	}
+
+	if _, statErr := os.Stat(filepath.Join(p.protoRoot, "buf.lock")); len(data.Deps) > 0 && os.IsNotExist(statErr) {
+		fmt.Printf("The buf.lock file is missing. Run `buf dep update` in %q to download the dependencies.\n", p.protoRoot)
+	}
+
+	return err
+}
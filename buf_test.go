@@ -0,0 +1,90 @@
+package protoschema_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBufFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	protoRoot := filepath.Join(tmpDir, "proto")
+
+	assert.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module github.com/Rick-Phoenix/bufapp\n"), 0644))
+
+	pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:               "bufapp.v1",
+		GoPackage:          "github.com/Rick-Phoenix/bufapp/gen/bufappv1",
+		GoModule:           "github.com/Rick-Phoenix/bufapp",
+		ProtoRoot:          protoRoot,
+		ConverterOutputDir: filepath.Join(tmpDir, "converter"),
+		Lint:               sb.LintConfig{Disable: []sb.LintRule{sb.LintEnumZeroValueSuffix, sb.LintCelFieldReference}},
+		BreakingPolicy:     sb.BreakingPolicy{sb.FieldRenamed: sb.SeverityError, sb.RPCDeleted: sb.SeverityIgnore},
+		Buf:                sb.BufConfig{Generate: true},
+	})
+
+	date := &sb.MessageSchema{Name: "Date", Package: &sb.ProtoPackage{Name: "google.type"}, ImportPath: "google/type/date.proto"}
+
+	file := pkg.NewFile(sb.FileSchema{Name: "event"})
+	event := file.NewMessage(sb.MessageSchema{
+		Name: "Event",
+		Fields: sb.FieldsMap{
+			1: sb.String("name").MinLen(1),
+			2: sb.MsgField("date", date),
+		},
+	})
+	file.NewService(sb.ServiceSchema{
+		Resource: "Event",
+		Handlers: sb.HandlersMap{"GetEvent": {Request: event, Response: event}},
+	})
+
+	assert.NoError(t, pkg.Generate())
+
+	bufYaml, err := os.ReadFile(filepath.Join(protoRoot, "buf.yaml"))
+	assert.NoError(t, err)
+
+	for _, expected := range []string{
+		"version: v2",
+		"deps:\n  - buf.build/bufbuild/protovalidate\n  - buf.build/googleapis/googleapis\n",
+		"lint:\n  use:\n    - STANDARD\n  except:\n    - ENUM_ZERO_VALUE_SUFFIX\n",
+		"breaking:\n  use:\n    - FILE\n  except:\n    - RPC_NO_DELETE\n",
+	} {
+		assert.Contains(t, string(bufYaml), expected)
+	}
+
+	bufGenYaml, err := os.ReadFile(filepath.Join(protoRoot, "buf.gen.yaml"))
+	assert.NoError(t, err)
+
+	for _, expected := range []string{
+		"  - local: protoc-gen-go\n    out: ..\n    opt:\n      - module=github.com/Rick-Phoenix/bufapp\n",
+		"  - local: protoc-gen-connect-go\n",
+	} {
+		assert.Contains(t, string(bufGenYaml), expected)
+	}
+
+	plainRoot := t.TempDir()
+	plain := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:               "plain.v1",
+		GoPackage:          "github.com/Rick-Phoenix/bufapp/gen/plainv1",
+		GoModule:           "github.com/Rick-Phoenix/bufapp",
+		ProtoRoot:          plainRoot,
+		ConverterOutputDir: filepath.Join(plainRoot, "converter"),
+		Buf:                sb.BufConfig{Generate: true, GoOut: "../gen"},
+	})
+	plain.NewFile(sb.FileSchema{Name: "plain"}).NewMessage(sb.MessageSchema{Name: "Plain", Fields: sb.FieldsMap{1: sb.Int32("count")}})
+
+	assert.NoError(t, plain.Generate())
+
+	bufYaml, err = os.ReadFile(filepath.Join(plainRoot, "buf.yaml"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(bufYaml), "deps:")
+	assert.Contains(t, string(bufYaml), "breaking:\n  use:\n    - WIRE_JSON\n")
+
+	bufGenYaml, err = os.ReadFile(filepath.Join(plainRoot, "buf.gen.yaml"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(bufGenYaml), "out: ../gen\n")
+	assert.NotContains(t, string(bufGenYaml), "connect")
+}
@@ -1,6 +1,9 @@
 package protoschema
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // A custom validator setting for protovalidate. See the protovalidate docs to learn more about the usage for these.
 type CelOption struct {
@@ -12,6 +15,27 @@ type CelOption struct {
 	Expression string
 }
 
+// A CEL expression that is rendered when the schemas are processed, such as those created with the cel subpackage, which also checks the references to the fields of the messages.
+type CelExpression interface {
+	Build() (string, error)
+}
+
+// A cel rule whose expression is built when the schema is processed, so that it can reference fields that are added after the rule.
+type celRule struct {
+	id, message string
+	expression  CelExpression
+}
+
+// Builds the expression and returns the equivalent CelOption.
+func (r celRule) build() (CelOption, error) {
+	expression, err := r.expression.Build()
+	if err != nil {
+		return CelOption{}, indentErrors(fmt.Sprintf("Errors in the CEL expression of the rule %q", r.id), err)
+	}
+
+	return CelOption{Id: r.id, Message: r.message, Expression: expression}, nil
+}
+
 // A method to add a Cel option to a specific field.
 func (b *ProtoField[BuilderT]) CelOption(id, message, expression string) *BuilderT {
 	opt, err := getProtoOption("(buf.validate.field).cel", CelOption{Id: id, Message: message, Expression: expression})
@@ -20,3 +44,50 @@ func (b *ProtoField[BuilderT]) CelOption(id, message, expression string) *Builde
 
 	return b.self
 }
+
+// Adds a Cel option to this field with an expression that is built (and checked) when the field is processed, such as one created with the cel subpackage.
+func (b *ProtoField[BuilderT]) CelRule(id, message string, expression CelExpression) *BuilderT {
+	b.celRules = append(b.celRules, celRule{id: id, message: message, expression: expression})
+
+	return b.self
+}
+
+// Returns a copy of the repeated options of the field, with the options of its cel rules.
+func (b *protoFieldInternal) buildRepeatedOptions() ([]string, error) {
+	options := make([]string, len(b.repeatedOptions))
+	copy(options, b.repeatedOptions)
+
+	var err error
+
+	for _, rule := range b.celRules {
+		celOpt, buildErr := rule.build()
+		if buildErr != nil {
+			err = errors.Join(err, buildErr)
+			continue
+		}
+
+		opt, optErr := getProtoOption("(buf.validate.field).cel", celOpt)
+		err = errors.Join(err, optErr)
+		options = append(options, opt)
+	}
+
+	return options, err
+}
+
+// Replaces the cel rules in the options of a message with the CelOption built from their expressions.
+func buildCelRules(options []ProtoOption) ([]ProtoOption, error) {
+	out := make([]ProtoOption, len(options))
+	var err error
+
+	for i, opt := range options {
+		if rule, isRule := opt.Value.(celRule); isRule {
+			celOpt, buildErr := rule.build()
+			err = errors.Join(err, buildErr)
+			opt.Value = celOpt
+		}
+
+		out[i] = opt
+	}
+
+	return out, err
+}
@@ -0,0 +1,511 @@
+// Package cel implements a builder for the CEL expressions of the protovalidate rules, which checks the references to the fields of the messages and the types of the operands when the expression is built.
+// The expressions can be added to the messages and fields with their CelRule method, so that they are built when the schemas are processed:
+//
+//	postMsg.CelRule("title_length", "the title must be longer than the slug", cel.Field(postMsg, "title").Size().Gt(cel.Field(postMsg, "slug").Size()))
+package cel
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+)
+
+// The type of the value of an expression, which is used to check the operations that are applied to it.
+type valueType string
+
+const (
+	boolType      valueType = "bool"
+	intType       valueType = "int"
+	uintType      valueType = "uint"
+	doubleType    valueType = "double"
+	stringType    valueType = "string"
+	bytesType     valueType = "bytes"
+	listType      valueType = "list"
+	mapType       valueType = "map"
+	messageType   valueType = "message"
+	timestampType valueType = "google.protobuf.Timestamp"
+	durationType  valueType = "google.protobuf.Duration"
+	// The values whose type is not known, such as "this" in the rules of a field, on which every operation is allowed
+	dynType valueType = "dyn"
+)
+
+// The precedence of the operators, which is used to add the parentheses around the operands.
+const (
+	orPrecedence = iota + 1
+	andPrecedence
+	relationPrecedence
+	unaryPrecedence
+	memberPrecedence
+)
+
+// A CEL expression. It is created with Field, Has, This or Value, and combined with its methods, such as Size, Gt or And.
+// The references to the fields and the types of the operands are checked when the expression is built, so that the fields of the messages can be added after the expression is created.
+type Expr struct {
+	build func() (node, error)
+}
+
+// The rendered source of an expression, with its type and, for messages, the schema used to check the references to their fields.
+type node struct {
+	source     string
+	typ        valueType
+	schema     *sb.MessageSchema
+	precedence int
+}
+
+// Renders the expression, returning an error if it references fields that do not exist or if an operation is used with a value of the wrong type.
+func (e Expr) Build() (string, error) {
+	if e.build == nil {
+		return "", errors.New("The expression is empty.")
+	}
+
+	n, err := e.build()
+	if err != nil {
+		return "", err
+	}
+
+	return n.source, nil
+}
+
+// Renders the expression, ignoring its errors. Use Build to check the references and the types.
+func (e Expr) String() string {
+	source, _ := e.Build()
+	return source
+}
+
+// Returns "this", which is the message in the rules of a message (with the given schema, used to check the references to its fields) or the value of the field in the rules of a field (with a nil schema, so that its type is not checked).
+func This(schema *sb.MessageSchema) Expr {
+	return Expr{build: func() (node, error) {
+		if schema == nil {
+			return node{source: "this", typ: dynType, precedence: memberPrecedence}, nil
+		}
+
+		return node{source: "this", typ: messageType, schema: schema, precedence: memberPrecedence}, nil
+	}}
+}
+
+// Returns a reference to a field of the message (as "this.<name>"), for the rules of the message. The field must exist in the schema when the expression is built.
+func Field(schema *sb.MessageSchema, name string) Expr {
+	return This(schema).Field(name)
+}
+
+// Returns an expression that checks if a field of the message is set (as "has(this.<name>)"), for the rules of the message.
+func Has(schema *sb.MessageSchema, name string) Expr {
+	return This(schema).Has(name)
+}
+
+// Returns a literal value, such as a string, a number, a bool, a time.Duration, a time.Time or a slice of these.
+func Value(v any) Expr {
+	return Expr{build: func() (node, error) { return literal(v, dynType) }}
+}
+
+// Returns an expression that is true if all the expressions are true.
+func And(exprs ...Expr) Expr {
+	return logical("&&", andPrecedence, exprs)
+}
+
+// Returns an expression that is true if any of the expressions is true.
+func Or(exprs ...Expr) Expr {
+	return logical("||", orPrecedence, exprs)
+}
+
+// Returns an expression that negates a bool expression.
+func Not(e Expr) Expr {
+	return Expr{build: func() (node, error) {
+		n, err := e.build()
+		if err != nil {
+			return node{}, err
+		}
+
+		if err := expectType(n, "!", boolType); err != nil {
+			return node{}, err
+		}
+
+		return node{source: "!" + wrap(n, unaryPrecedence), typ: boolType, precedence: unaryPrecedence}, nil
+	}}
+}
+
+// Returns a reference to a field of this message value, which must exist in the schema of the message.
+func (e Expr) Field(name string) Expr {
+	return Expr{build: func() (node, error) {
+		n, err := e.build()
+		if err != nil {
+			return node{}, err
+		}
+
+		return selectField(n, name)
+	}}
+}
+
+// Returns an expression that checks if a field of this message value is set.
+func (e Expr) Has(name string) Expr {
+	return Expr{build: func() (node, error) {
+		n, err := e.build()
+		if err != nil {
+			return node{}, err
+		}
+
+		field, err := selectField(n, name)
+		if err != nil {
+			return node{}, err
+		}
+
+		return node{source: "has(" + field.source + ")", typ: boolType, precedence: memberPrecedence}, nil
+	}}
+}
+
+// Returns the size of a string, bytes, repeated or map value.
+func (e Expr) Size() Expr {
+	return e.method("size", intType, []valueType{stringType, bytesType, listType, mapType})
+}
+
+// Returns an expression that is true if this value is equal to the other value (an expression or a literal).
+func (e Expr) Eq(other any) Expr {
+	return e.compare("==", other, false)
+}
+
+// Returns an expression that is true if this value is not equal to the other value (an expression or a literal).
+func (e Expr) Ne(other any) Expr {
+	return e.compare("!=", other, false)
+}
+
+// Returns an expression that is true if this value is greater than the other value (an expression or a literal).
+func (e Expr) Gt(other any) Expr {
+	return e.compare(">", other, true)
+}
+
+// Returns an expression that is true if this value is greater than or equal to the other value (an expression or a literal).
+func (e Expr) Gte(other any) Expr {
+	return e.compare(">=", other, true)
+}
+
+// Returns an expression that is true if this value is less than the other value (an expression or a literal).
+func (e Expr) Lt(other any) Expr {
+	return e.compare("<", other, true)
+}
+
+// Returns an expression that is true if this value is less than or equal to the other value (an expression or a literal).
+func (e Expr) Lte(other any) Expr {
+	return e.compare("<=", other, true)
+}
+
+// Returns an expression that is true if this value is one of the given values.
+func (e Expr) In(values ...any) Expr {
+	return Expr{build: func() (node, error) {
+		n, err := e.build()
+		if err != nil {
+			return node{}, err
+		}
+
+		items := make([]string, len(values))
+		var errs error
+
+		for i, v := range values {
+			item, itemErr := literal(v, n.typ)
+			if itemErr == nil {
+				itemErr = checkComparable(n, item, "in", false)
+			}
+
+			errs = errors.Join(errs, itemErr)
+			items[i] = item.source
+		}
+
+		if errs != nil {
+			return node{}, errs
+		}
+
+		return node{source: wrap(n, relationPrecedence+1) + " in [" + strings.Join(items, ", ") + "]", typ: boolType, precedence: relationPrecedence}, nil
+	}}
+}
+
+// Returns an expression that is true if this string starts with the prefix.
+func (e Expr) StartsWith(prefix string) Expr {
+	return e.method("startsWith", boolType, []valueType{stringType}, prefix)
+}
+
+// Returns an expression that is true if this string ends with the suffix.
+func (e Expr) EndsWith(suffix string) Expr {
+	return e.method("endsWith", boolType, []valueType{stringType}, suffix)
+}
+
+// Returns an expression that is true if this string contains the substring.
+func (e Expr) Contains(substring string) Expr {
+	return e.method("contains", boolType, []valueType{stringType}, substring)
+}
+
+// Returns an expression that is true if this string matches the regular expression (in the RE2 syntax).
+func (e Expr) Matches(pattern string) Expr {
+	return e.method("matches", boolType, []valueType{stringType}, pattern)
+}
+
+// Returns an expression that is true if this expression and all the others are true.
+func (e Expr) And(others ...Expr) Expr {
+	return And(append([]Expr{e}, others...)...)
+}
+
+// Returns an expression that is true if this expression or any of the others is true.
+func (e Expr) Or(others ...Expr) Expr {
+	return Or(append([]Expr{e}, others...)...)
+}
+
+// Returns the field of a message value with the given name, checking that it exists in its schema.
+func selectField(n node, name string) (node, error) {
+	switch n.typ {
+	case dynType:
+		return node{source: n.source + "." + name, typ: dynType, precedence: memberPrecedence}, nil
+	case messageType:
+	default:
+		return node{}, fmt.Errorf("Cannot select the field %q of %s, which is a %s value.", name, n.source, n.typ)
+	}
+
+	// The messages without fields are only used as references, so their fields are not known
+	if n.schema == nil || len(n.schema.GetFieldNames()) == 0 {
+		return node{source: n.source + "." + name, typ: dynType, precedence: memberPrecedence}, nil
+	}
+
+	field, exists := n.schema.LookupField(name)
+	if !exists {
+		return node{}, fmt.Errorf("The message %q has no field %q (referenced in %s.%s).", n.schema.GetName(), name, n.source, name)
+	}
+
+	typ, schema := fieldType(field.GetData())
+
+	return node{source: n.source + "." + name, typ: typ, schema: schema, precedence: memberPrecedence}, nil
+}
+
+// Returns the type of the value of a field, and the schema of its message if it is a message.
+func fieldType(data sb.FieldData) (valueType, *sb.MessageSchema) {
+	switch {
+	case data.IsMap:
+		return mapType, nil
+	case data.Repeated:
+		return listType, nil
+	case data.EnumRef != nil:
+		return intType, nil
+	}
+
+	switch data.ProtoType {
+	case "bool":
+		return boolType, nil
+	case "string":
+		return stringType, nil
+	case "bytes":
+		return bytesType, nil
+	case "int32", "int64", "sint32", "sint64", "sfixed32", "sfixed64":
+		return intType, nil
+	case "uint32", "uint64", "fixed32", "fixed64":
+		return uintType, nil
+	case "float", "double":
+		return doubleType, nil
+	case "google.protobuf.Timestamp":
+		return timestampType, nil
+	case "google.protobuf.Duration":
+		return durationType, nil
+	}
+
+	if data.MessageRef != nil {
+		return messageType, data.MessageRef
+	}
+
+	return dynType, nil
+}
+
+// Returns an error if the type of the value is not one of the expected types. Dyn values are always accepted.
+func expectType(n node, operation string, expected ...valueType) error {
+	if n.typ == dynType {
+		return nil
+	}
+
+	for _, typ := range expected {
+		if n.typ == typ {
+			return nil
+		}
+	}
+
+	names := make([]string, len(expected))
+	for i, typ := range expected {
+		names[i] = string(typ)
+	}
+
+	return fmt.Errorf("Cannot use %s with %s, which is a %s value (expected %s).", operation, n.source, n.typ, strings.Join(names, " or "))
+}
+
+// Calls a method on this value, which must have one of the given types. The arguments are literals.
+func (e Expr) method(name string, result valueType, receivers []valueType, args ...any) Expr {
+	return Expr{build: func() (node, error) {
+		n, err := e.build()
+		if err != nil {
+			return node{}, err
+		}
+
+		if err := expectType(n, name+"()", receivers...); err != nil {
+			return node{}, err
+		}
+
+		rendered := make([]string, len(args))
+		for i, arg := range args {
+			argNode, err := literal(arg, dynType)
+			if err != nil {
+				return node{}, err
+			}
+			rendered[i] = argNode.source
+		}
+
+		return node{source: wrap(n, memberPrecedence) + "." + name + "(" + strings.Join(rendered, ", ") + ")", typ: result, precedence: memberPrecedence}, nil
+	}}
+}
+
+// Compares this value with another expression or literal. The literals are written with the type of this value (for example, 5 is written as 5.0 for a double).
+func (e Expr) compare(operator string, other any, ordered bool) Expr {
+	return Expr{build: func() (node, error) {
+		n, err := e.build()
+
+		var otherNode node
+		var otherErr error
+
+		if otherExpr, isExpr := other.(Expr); isExpr {
+			otherNode, otherErr = otherExpr.build()
+		} else {
+			otherNode, otherErr = literal(other, n.typ)
+		}
+
+		if err = errors.Join(err, otherErr); err != nil {
+			return node{}, err
+		}
+
+		if err := checkComparable(n, otherNode, operator, ordered); err != nil {
+			return node{}, err
+		}
+
+		return node{source: wrap(n, relationPrecedence+1) + " " + operator + " " + wrap(otherNode, relationPrecedence+1), typ: boolType, precedence: relationPrecedence}, nil
+	}}
+}
+
+// Returns an error if the values cannot be compared with the operator.
+func checkComparable(a, b node, operator string, ordered bool) error {
+	if a.typ == dynType || b.typ == dynType {
+		return nil
+	}
+
+	if a.typ != b.typ {
+		return fmt.Errorf("Cannot compare %s (%s) with %s (%s) using %q.", a.source, a.typ, b.source, b.typ, operator)
+	}
+
+	if ordered {
+		return expectType(a, operator, intType, uintType, doubleType, stringType, bytesType, timestampType, durationType)
+	}
+
+	return nil
+}
+
+func logical(operator string, precedence int, exprs []Expr) Expr {
+	return Expr{build: func() (node, error) {
+		if len(exprs) == 0 {
+			return node{}, fmt.Errorf("Cannot use %s without expressions.", operator)
+		}
+
+		nodes := make([]node, len(exprs))
+		parts := make([]string, len(exprs))
+		var errs error
+
+		for i, e := range exprs {
+			n, err := e.build()
+			if err == nil {
+				err = expectType(n, operator, boolType)
+			}
+
+			errs = errors.Join(errs, err)
+			nodes[i] = n
+			parts[i] = wrap(n, precedence)
+		}
+
+		if errs != nil {
+			return node{}, errs
+		}
+
+		if len(nodes) == 1 {
+			return nodes[0], nil
+		}
+
+		return node{source: strings.Join(parts, " "+operator+" "), typ: boolType, precedence: precedence}, nil
+	}}
+}
+
+// Returns the source of an operand, in parentheses if its operator binds less tightly than the given precedence.
+func wrap(n node, precedence int) string {
+	if n.precedence < precedence {
+		return "(" + n.source + ")"
+	}
+
+	return n.source
+}
+
+// Renders a literal value. The numbers are written with the expected type if possible, so that they can be compared with the fields of that type.
+func literal(v any, expected valueType) (node, error) {
+	lit := func(source string, typ valueType) (node, error) {
+		return node{source: source, typ: typ, precedence: memberPrecedence}, nil
+	}
+
+	switch value := v.(type) {
+	case Expr:
+		return value.build()
+	case string:
+		return lit(strconv.Quote(value), stringType)
+	case []byte:
+		return lit("b"+strconv.Quote(string(value)), bytesType)
+	case bool:
+		return lit(strconv.FormatBool(value), boolType)
+	case time.Duration:
+		if value%time.Second == 0 {
+			return lit(fmt.Sprintf("duration(%q)", fmt.Sprintf("%ds", value/time.Second)), durationType)
+		}
+		return lit(fmt.Sprintf("duration(%q)", fmt.Sprintf("%dns", value.Nanoseconds())), durationType)
+	case time.Time:
+		return lit(fmt.Sprintf("timestamp(%q)", value.UTC().Format(time.RFC3339Nano)), timestampType)
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch {
+	case rv.CanInt():
+		n := rv.Int()
+		switch {
+		case expected == doubleType:
+			return lit(strconv.FormatInt(n, 10)+".0", doubleType)
+		case expected == uintType && n >= 0:
+			return lit(strconv.FormatInt(n, 10)+"u", uintType)
+		}
+		return lit(strconv.FormatInt(n, 10), intType)
+	case rv.CanUint():
+		n := rv.Uint()
+		switch expected {
+		case doubleType:
+			return lit(strconv.FormatUint(n, 10)+".0", doubleType)
+		case intType:
+			return lit(strconv.FormatUint(n, 10), intType)
+		}
+		return lit(strconv.FormatUint(n, 10)+"u", uintType)
+	case rv.CanFloat():
+		source := strconv.FormatFloat(rv.Float(), 'g', -1, 64)
+		if !strings.ContainsAny(source, ".eEn") {
+			source += ".0"
+		}
+		return lit(source, doubleType)
+	case rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array:
+		items := make([]string, rv.Len())
+		for i := range rv.Len() {
+			item, err := literal(rv.Index(i).Interface(), dynType)
+			if err != nil {
+				return node{}, err
+			}
+			items[i] = item.source
+		}
+		return lit("["+strings.Join(items, ", ")+"]", listType)
+	}
+
+	return node{}, fmt.Errorf("Unsupported type for a CEL literal: %T.", v)
+}
@@ -0,0 +1,97 @@
+package cel_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"github.com/Rick-Phoenix/protoschema/cel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCel(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:               "cel.v1",
+		GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/celv1",
+		ProtoRoot:          tmpDir,
+		ConverterOutputDir: filepath.Join(tmpDir, "converter"),
+	})
+
+	file := pkg.NewFile(sb.FileSchema{Name: "post"})
+
+	post := file.NewMessage(sb.MessageSchema{Name: "Post"})
+	author := post.NestedMessage(sb.MessageSchema{Name: "Author", Fields: sb.FieldsMap{1: sb.String("name")}})
+
+	// The rule is created before the fields of the message are defined
+	post.CelRule("title_length", "the title must be longer than 5 characters", cel.Field(post, "title").Size().Gt(5).And(cel.Field(post, "rating").Lte(5)))
+
+	post.Fields = sb.FieldsMap{
+		1: sb.String("title"),
+		2: sb.Double("rating"),
+		3: sb.UInt32("views"),
+		4: sb.MsgField("author", author),
+		5: sb.Repeated("tags", sb.String("")),
+		6: sb.Timestamp("published_at"),
+		7: sb.String("slug").CelRule("slug_format", "the slug must be lowercase", cel.This(nil).Matches("^[a-z-]+$")),
+	}
+
+	post.NewOneof(sb.OneofGroup{Name: "body", Fields: sb.OneofFields{8: sb.String("text")}})
+
+	tests := []struct {
+		expr     cel.Expr
+		expected string
+	}{
+		{cel.Field(post, "title").Size().Gt(5).And(cel.Field(post, "rating").Lte(5)), "this.title.size() > 5 && this.rating <= 5.0"},
+		{cel.Field(post, "views").Gte(10), "this.views >= 10u"},
+		{cel.Field(post, "author").Field("name").StartsWith("Dr. "), `this.author.name.startsWith("Dr. ")`},
+		{cel.Or(cel.Has(post, "text"), cel.Field(post, "tags").Size().Eq(0)).And(cel.Not(cel.Field(post, "title").Eq(""))), `(has(this.text) || this.tags.size() == 0) && !(this.title == "")`},
+		{cel.Field(post, "title").In("a", "b"), `this.title in ["a", "b"]`},
+		{cel.Field(post, "published_at").Lt(time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)), `this.published_at < timestamp("2030-01-02T00:00:00Z")`},
+		{cel.Field(post, "title").Size().Lt(cel.Field(post, "tags").Size()), "this.title.size() < this.tags.size()"},
+	}
+
+	for _, test := range tests {
+		source, err := test.expr.Build()
+		if assert.NoError(t, err) {
+			assert.Equal(t, test.expected, source)
+		}
+	}
+
+	invalid := []struct {
+		expr cel.Expr
+		err  string
+	}{
+		{cel.Field(post, "titel"), `The message "Post" has no field "titel"`},
+		{cel.Field(post, "author").Field("nam"), `The message "Post.Author" has no field "nam"`},
+		{cel.Field(post, "rating").Size(), "Cannot use size() with this.rating, which is a double value"},
+		{cel.Field(post, "title").Gt(5), "Cannot compare this.title (string) with 5 (int)"},
+		{cel.Field(post, "title").And(cel.Has(post, "text")), "Cannot use && with this.title"},
+		{cel.Field(post, "title").Field("length"), `Cannot select the field "length" of this.title`},
+	}
+
+	for _, test := range invalid {
+		_, err := test.expr.Build()
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), test.err)
+		}
+	}
+
+	assert.NoError(t, pkg.Generate())
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "cel/v1/post.proto"))
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(content), `expression: "this.title.size() > 5 && this.rating <= 5.0"`)
+		assert.Contains(t, string(content), `expression: "this.matches(\"^[a-z-]+$\")"`)
+	}
+
+	post.CelRule("invalid", "the rule references a missing field", cel.Field(post, "missing").Eq(""))
+
+	_, err = pkg.TryBuildFiles()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `Errors in the CEL expression of the rule "invalid"`)
+	}
+}
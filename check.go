@@ -0,0 +1,132 @@
+package protoschema
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// The kind of change that the next generation would apply to a proto file.
+type FileChangeKind string
+
+const (
+	FileAdded    FileChangeKind = "added"
+	FileModified FileChangeKind = "modified"
+	FileRemoved  FileChangeKind = "removed"
+)
+
+// A proto file that would be changed by the next generation.
+type FileChange struct {
+	Path string
+	Kind FileChangeKind
+}
+
+// The results of checking the processed files of a package without generating them.
+type CheckReport struct {
+	// The issues found by the linter. The linter always runs, even if it is skipped during the generation.
+	LintIssues []LintIssue
+	// The breaking changes compared to the package's snapshot, if one is defined.
+	BreakingChanges []BreakingChange
+	// The proto files that would be added, modified or removed by the next generation, sorted by path.
+	FileChanges []FileChange
+}
+
+// The error returned by the generation when it is stopped by issues in the schemas (the lint issues, if the linter is in strict mode, or the breaking changes with the error severity), rather than by a failure while writing the files.
+// It can be detected with errors.As, including in the errors returned by the generation of a workspace.
+type IssuesError struct {
+	err error
+}
+
+func (e *IssuesError) Error() string {
+	return e.err.Error()
+}
+
+func (e *IssuesError) Unwrap() error {
+	return e.err
+}
+
+func issuesError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &IssuesError{err: err}
+}
+
+// Returns true if the next generation would be stopped, because of breaking changes with the error severity or (if the linter is in strict mode) lint issues.
+func (r *CheckReport) BlocksGeneration(conf LintConfig) bool {
+	if conf.Strict && len(r.LintIssues) > 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(r.BreakingChanges, func(bc BreakingChange) bool { return bc.Severity == SeverityError })
+}
+
+// Returns the lint configuration of this package.
+func (p *ProtoPackage) GetLintConfig() LintConfig {
+	return p.lintConfig
+}
+
+// Checks the data returned by TryBuildFiles without generating any file. It runs the linter, detects the breaking changes and compares the proto files with the ones generated previously.
+func (p *ProtoPackage) Check(filesData []FileData) (*CheckReport, error) {
+	conf := p.lintConfig
+	conf.Skip = false
+
+	report := &CheckReport{LintIssues: Lint(filesData, conf)}
+
+	breakingChanges, err := p.checkBreaking(NewSnapshot(filesData))
+	if err != nil {
+		return nil, err
+	}
+	report.BreakingChanges = breakingChanges
+
+	if err := p.loadManifest(); err != nil {
+		return nil, err
+	}
+
+	produced := make(Set)
+
+	for _, fileData := range filesData {
+		outputPath := filepath.Join(p.protoOutputDir, strings.ToLower(fileData.Name))
+		key := p.manifestKey(outputPath)
+		produced[key] = present
+
+		content, err := p.renderFile(fileData)
+		if err != nil {
+			return nil, err
+		}
+
+		existing, err := os.ReadFile(outputPath)
+		if errors.Is(err, os.ErrNotExist) {
+			report.FileChanges = append(report.FileChanges, FileChange{Path: outputPath, Kind: FileAdded})
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		prev, exists := p.prevOutputs[key]
+		if !exists || prev.Hash != hashContent(content) || prev.OutputHash != hashContent(existing) {
+			report.FileChanges = append(report.FileChanges, FileChange{Path: outputPath, Kind: FileModified})
+		}
+	}
+
+	manifestDir := filepath.Dir(p.GetManifestFilePath())
+
+	for _, key := range slices.Sorted(maps.Keys(p.prevOutputs)) {
+		if _, exists := produced[key]; exists || !strings.HasSuffix(key, ".proto") {
+			continue
+		}
+
+		stalePath := filepath.Join(manifestDir, filepath.FromSlash(key))
+		if _, err := os.Stat(stalePath); err == nil {
+			report.FileChanges = append(report.FileChanges, FileChange{Path: stalePath, Kind: FileRemoved})
+		}
+	}
+
+	slices.SortFunc(report.FileChanges, func(a, b FileChange) int { return strings.Compare(a.Path, b.Path) })
+
+	return report, nil
+}
@@ -0,0 +1,455 @@
+// Package cli implements the subcommands of the protoschema command, which operate on the packages and workspaces registered with protoschema.Register and protoschema.RegisterWorkspace.
+// It can also be used directly, by calling Run from the main function of a program that imports the schemas.
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"google.golang.org/protobuf/proto"
+)
+
+// The exit codes used by all the commands.
+const (
+	// The command completed successfully, and no issues were found.
+	ExitOK = 0
+	// The command found issues in the schemas (such as processing errors, lint issues or breaking changes that stop the generation), or, for the diff command, there are files that would be changed by the next generation.
+	ExitIssues = 1
+	// The command was used incorrectly.
+	ExitUsage = 2
+	// An error occurred while running the command (such as a failure to write a file).
+	ExitError = 3
+)
+
+const usage = `Usage: protoschema <command> [flags]
+
+Commands:
+  generate   Processes the schemas and generates the files. With -buf, it also runs buf generate when proto files changed.
+  check      Processes the schemas, runs the linter and detects breaking changes, without generating anything.
+  diff       Lists the proto files that would be added, modified or removed by the next generation.
+  lint       Runs the linter on the schemas.
+  export     Exports a package as an OpenAPI document, a JSON Schema document or a descriptor set.
+  inspect    Prints the structure of the registered packages.
+  watch      Generates the files, then regenerates them whenever the go sources or the schema documents change. Only available in the protoschema command.
+
+Exit codes:
+  0  Success
+  1  Issues were found (or, for diff, there are pending changes)
+  2  Invalid usage
+  3  Runtime error
+`
+
+// A group of packages that are processed together: either a registered workspace or a single registered package.
+type target struct {
+	workspace *sb.Workspace
+	packages  []*sb.ProtoPackage
+}
+
+func (t target) build() (map[string][]sb.FileData, error) {
+	if t.workspace != nil {
+		return t.workspace.TryBuildFiles()
+	}
+
+	p := t.packages[0]
+	files, err := p.TryBuildFiles()
+
+	return map[string][]sb.FileData{p.GetName(): files}, err
+}
+
+func (t target) generate(files map[string][]sb.FileData) error {
+	if t.workspace != nil {
+		return t.workspace.GenerateFiles(files)
+	}
+
+	p := t.packages[0]
+
+	return p.GenerateFiles(files[p.GetName()])
+}
+
+func registeredTargets() []target {
+	var targets []target
+
+	for _, w := range sb.RegisteredWorkspaces() {
+		targets = append(targets, target{workspace: w, packages: w.GetPackages()})
+	}
+
+	for _, p := range sb.RegisteredPackages() {
+		targets = append(targets, target{packages: []*sb.ProtoPackage{p}})
+	}
+
+	return targets
+}
+
+type command struct {
+	stdout, stderr io.Writer
+	targets        []target
+}
+
+// Runs the command with the given arguments (excluding the name of the program), and returns its exit code.
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return ExitUsage
+	}
+
+	c := &command{stdout: stdout, stderr: stderr, targets: registeredTargets()}
+
+	name, args := args[0], args[1:]
+
+	if name == "help" || name == "-h" || name == "--help" {
+		fmt.Fprint(stdout, usage)
+		return ExitOK
+	}
+
+	commands := map[string]func([]string) int{
+		"generate": c.generate,
+		"check":    c.check,
+		"diff":     c.diff,
+		"lint":     c.lint,
+		"export":   c.export,
+		"inspect":  c.inspect,
+	}
+
+	run, exists := commands[name]
+	if !exists {
+		fmt.Fprintf(stderr, "Unknown command %q.\n\n%s", name, usage)
+		return ExitUsage
+	}
+
+	if len(c.targets) == 0 {
+		fmt.Fprintln(stderr, "No packages are registered. Register them with protoschema.Register or protoschema.RegisterWorkspace in an init function of the schema package.")
+		return ExitUsage
+	}
+
+	return run(args)
+}
+
+func (c *command) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	return fs
+}
+
+// Parses the flags of a command that takes no arguments, and returns the exit code for invalid usage (or -1 if the flags are valid).
+func (c *command) parseFlags(fs *flag.FlagSet, args []string) int {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+
+	if fs.NArg() > 0 {
+		fmt.Fprintf(c.stderr, "Unexpected arguments: %s\n", strings.Join(fs.Args(), " "))
+		return ExitUsage
+	}
+
+	return -1
+}
+
+// Builds each target and calls the given function with the processed files. Targets with errors are reported and skipped.
+func (c *command) forEachTarget(fn func(t target, files map[string][]sb.FileData) int) int {
+	code := ExitOK
+
+	for _, t := range c.targets {
+		files, err := t.build()
+		if err != nil {
+			fmt.Fprintf(c.stderr, "❌ The following errors occurred:\n%s\n", err.Error())
+			code = max(code, ExitIssues)
+			continue
+		}
+
+		code = max(code, fn(t, files))
+	}
+
+	return code
+}
+
+// Processes the schemas and generates the files of all the registered packages.
+func (c *command) generate(args []string) int {
+	fs := c.newFlagSet("generate")
+	runBuf := fs.Bool("buf", false, "Runs buf generate in the proto root of each package whose proto files were changed.")
+
+	if code := c.parseFlags(fs, args); code != -1 {
+		return code
+	}
+
+	return c.forEachTarget(func(t target, files map[string][]sb.FileData) int {
+		var changedRoots []string
+
+		if *runBuf {
+			for _, p := range t.packages {
+				report, err := p.Check(files[p.GetName()])
+				if err != nil {
+					fmt.Fprintf(c.stderr, "❌ %s\n", err.Error())
+					return ExitError
+				}
+
+				if len(report.FileChanges) > 0 && !slices.Contains(changedRoots, p.GetProtoRoot()) {
+					changedRoots = append(changedRoots, p.GetProtoRoot())
+				}
+			}
+		}
+
+		if err := t.generate(files); err != nil {
+			fmt.Fprintf(c.stderr, "❌ %s\n", err.Error())
+
+			var issuesErr *sb.IssuesError
+			if errors.As(err, &issuesErr) {
+				return ExitIssues
+			}
+
+			return ExitError
+		}
+
+		for _, root := range changedRoots {
+			if err := c.bufGenerate(root); err != nil {
+				fmt.Fprintf(c.stderr, "❌ Failed to run buf generate in %q: %s\n", root, err.Error())
+				return ExitError
+			}
+		}
+
+		return ExitOK
+	})
+}
+
+func (c *command) bufGenerate(dir string) error {
+	if _, err := exec.LookPath("buf"); err != nil {
+		fmt.Fprintln(c.stderr, "Could not run buf generate. Is the buf cli in PATH?")
+		return nil
+	}
+
+	cmd := exec.Command("buf", "generate")
+	cmd.Dir = dir
+	cmd.Stdout = c.stdout
+	cmd.Stderr = c.stderr
+
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "✅ Successfully ran buf generate in: %s\n", dir)
+
+	return nil
+}
+
+// Calls the given function with the check report of each package.
+func (c *command) forEachReport(fn func(p *sb.ProtoPackage, report *sb.CheckReport) int) int {
+	return c.forEachTarget(func(t target, files map[string][]sb.FileData) int {
+		code := ExitOK
+
+		for _, p := range t.packages {
+			report, err := p.Check(files[p.GetName()])
+			if err != nil {
+				fmt.Fprintf(c.stderr, "❌ %s\n", err.Error())
+				code = max(code, ExitError)
+				continue
+			}
+
+			code = max(code, fn(p, report))
+		}
+
+		return code
+	})
+}
+
+func (c *command) check(args []string) int {
+	if code := c.parseFlags(c.newFlagSet("check"), args); code != -1 {
+		return code
+	}
+
+	return c.forEachReport(func(p *sb.ProtoPackage, report *sb.CheckReport) int {
+		for _, issue := range report.LintIssues {
+			fmt.Fprintf(c.stdout, "Lint issue: %s\n", issue)
+		}
+
+		for _, change := range report.BreakingChanges {
+			fmt.Fprintf(c.stdout, "Breaking change (%s): %s\n", change.Severity, change)
+		}
+
+		if report.BlocksGeneration(p.GetLintConfig()) {
+			fmt.Fprintf(c.stdout, "❌ The package %q has issues that would stop the generation.\n", p.GetName())
+			return ExitIssues
+		}
+
+		fmt.Fprintf(c.stdout, "✅ The package %q can be generated.\n", p.GetName())
+		return ExitOK
+	})
+}
+
+func (c *command) diff(args []string) int {
+	if code := c.parseFlags(c.newFlagSet("diff"), args); code != -1 {
+		return code
+	}
+
+	return c.forEachReport(func(p *sb.ProtoPackage, report *sb.CheckReport) int {
+		for _, change := range report.FileChanges {
+			fmt.Fprintf(c.stdout, "%-8s %s\n", change.Kind, change.Path)
+		}
+
+		for _, change := range report.BreakingChanges {
+			fmt.Fprintf(c.stdout, "Breaking change (%s): %s\n", change.Severity, change)
+		}
+
+		if len(report.FileChanges) > 0 {
+			return ExitIssues
+		}
+
+		return ExitOK
+	})
+}
+
+func (c *command) lint(args []string) int {
+	if code := c.parseFlags(c.newFlagSet("lint"), args); code != -1 {
+		return code
+	}
+
+	return c.forEachReport(func(p *sb.ProtoPackage, report *sb.CheckReport) int {
+		for _, issue := range report.LintIssues {
+			fmt.Fprintln(c.stdout, issue)
+		}
+
+		if len(report.LintIssues) > 0 {
+			return ExitIssues
+		}
+
+		return ExitOK
+	})
+}
+
+// Finds the package with the given name among the registered ones, along with its workspace (if it belongs to a registered workspace). If the name is empty, there must be only one registered package.
+func (c *command) findPackage(name string) (*sb.ProtoPackage, *sb.Workspace, error) {
+	var names []string
+
+	for _, t := range c.targets {
+		for _, p := range t.packages {
+			if p.GetName() == name || (name == "" && len(c.targets) == 1 && len(t.packages) == 1) {
+				return p, t.workspace, nil
+			}
+
+			names = append(names, p.GetName())
+		}
+	}
+
+	if name == "" {
+		return nil, nil, fmt.Errorf("More than one package is registered, so the package must be selected with the -package flag (available packages: %s).", strings.Join(names, ", "))
+	}
+
+	return nil, nil, fmt.Errorf("The package %q is not registered (available packages: %s).", name, strings.Join(names, ", "))
+}
+
+func (c *command) export(args []string) int {
+	fs := c.newFlagSet("export")
+	format := fs.String("format", "", "The format of the export: openapi, jsonschema, descriptorset, go or fakes.")
+	pkgName := fs.String("package", "", "The name of the package to export. Required if more than one package is registered.")
+	out := fs.String("out", "", "The path of the output file. If undefined, the output is written to stdout.")
+	seed := fs.Int64("seed", 1, "The seed used to generate the values of the fakes format.")
+
+	if code := c.parseFlags(fs, args); code != -1 {
+		return code
+	}
+
+	p, workspace, err := c.findPackage(*pkgName)
+	if err != nil {
+		fmt.Fprintln(c.stderr, err.Error())
+		return ExitUsage
+	}
+
+	if !slices.Contains([]string{"openapi", "jsonschema", "descriptorset", "go", "fakes"}, *format) {
+		fmt.Fprintf(c.stderr, "Invalid format %q. The format must be one of openapi, jsonschema, descriptorset, go or fakes.\n", *format)
+		return ExitUsage
+	}
+
+	// The schemas are processed once, and the exporters use the processed files. In a workspace, all the packages are processed so that the files of the other packages can be imported
+	var filesData []sb.FileData
+
+	if workspace != nil {
+		workspaceFiles, buildErr := workspace.TryBuildFiles()
+		err = buildErr
+		filesData = workspaceFiles[p.GetName()]
+	} else {
+		filesData, err = p.TryBuildFiles()
+	}
+
+	if err != nil {
+		fmt.Fprintf(c.stderr, "❌ The following errors occurred:\n%s\n", err.Error())
+		return ExitIssues
+	}
+
+	var content []byte
+
+	switch *format {
+	case "openapi":
+		content, err = p.ExportOpenAPIFromFiles(filesData)
+	case "jsonschema":
+		content, err = p.ExportJSONSchemaFromFiles(filesData)
+	case "descriptorset":
+		set, buildErr := p.BuildDescriptorsFromFiles(filesData)
+		if buildErr != nil {
+			err = buildErr
+		} else {
+			content, err = proto.Marshal(set)
+		}
+	case "go":
+		var buf bytes.Buffer
+		err = p.EmitGoFromFiles(&buf, filesData)
+		content = buf.Bytes()
+	case "fakes":
+		var buf bytes.Buffer
+		err = p.EmitFakesFromFiles(&buf, filesData, "fakes", *seed)
+		content = buf.Bytes()
+	}
+
+	if err != nil {
+		fmt.Fprintf(c.stderr, "❌ The following errors occurred:\n%s\n", err.Error())
+		return ExitIssues
+	}
+
+	if *out == "" {
+		if _, err := c.stdout.Write(content); err != nil {
+			return ExitError
+		}
+		return ExitOK
+	}
+
+	if err := os.WriteFile(*out, content, 0644); err != nil {
+		fmt.Fprintf(c.stderr, "❌ Failed to write the output file: %s\n", err.Error())
+		return ExitError
+	}
+
+	return ExitOK
+}
+
+func (c *command) inspect(args []string) int {
+	fs := c.newFlagSet("inspect")
+	pkgName := fs.String("package", "", "The name of the package to inspect. If undefined, all the registered packages are inspected.")
+
+	if code := c.parseFlags(fs, args); code != -1 {
+		return code
+	}
+
+	if *pkgName != "" {
+		if _, _, err := c.findPackage(*pkgName); err != nil {
+			fmt.Fprintln(c.stderr, err.Error())
+			return ExitUsage
+		}
+	}
+
+	return c.forEachTarget(func(t target, files map[string][]sb.FileData) int {
+		for _, p := range t.packages {
+			if *pkgName == "" || p.GetName() == *pkgName {
+				printPackage(c.stdout, p, files[p.GetName()])
+			}
+		}
+
+		return ExitOK
+	})
+}
@@ -0,0 +1,141 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"github.com/Rick-Phoenix/protoschema/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCli(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:               "cli.v1",
+		GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/cliv1",
+		ProtoRoot:          tmpDir,
+		ConverterOutputDir: filepath.Join(tmpDir, "converter"),
+	})
+
+	pkg.NewFile(sb.FileSchema{Name: "item"}).NewMessage(sb.MessageSchema{
+		Name:   "Item",
+		Doc:    "An item in the store.",
+		Fields: sb.FieldsMap{1: sb.String("name"), 2: sb.Int64("price")},
+	})
+
+	sb.Register(pkg)
+
+	run := func(args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := cli.Run(args, &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+
+	code, _, _ := run()
+	assert.Equal(t, cli.ExitUsage, code)
+
+	code, _, _ = run("unknown")
+	assert.Equal(t, cli.ExitUsage, code)
+
+	code, _, _ = run("export", "-format", "yaml")
+	assert.Equal(t, cli.ExitUsage, code)
+
+	code, _, _ = run("export", "-format", "jsonschema", "-package", "other.v1")
+	assert.Equal(t, cli.ExitUsage, code)
+
+	code, out, _ := run("inspect")
+	assert.Equal(t, cli.ExitOK, code)
+	assert.Contains(t, out, "message Item")
+	assert.Contains(t, out, "2 price int64")
+
+	code, out, _ = run("export", "-format", "jsonschema")
+	if assert.Equal(t, cli.ExitOK, code) {
+		var doc map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(out), &doc))
+		assert.Contains(t, doc["$defs"], "cli.v1.Item")
+	}
+
+	code, out, _ = run("export", "-format", "go")
+	assert.Equal(t, cli.ExitOK, code)
+	assert.Contains(t, out, `itemFile.NewMessage(sb.MessageSchema{Name: "Item", Doc: "An item in the store."})`)
+
+	code, out, _ = run("export", "-format", "fakes", "-seed", "3")
+	assert.Equal(t, cli.ExitOK, code)
+	assert.Contains(t, out, "func FakeItem() *cliv1.Item {")
+
+	code, _, _ = run("lint")
+	assert.Equal(t, cli.ExitOK, code)
+
+	// The files have not been generated yet
+	code, out, _ = run("diff")
+	assert.Equal(t, cli.ExitIssues, code)
+	assert.Contains(t, out, "added")
+
+	code, _, _ = run("check")
+	assert.Equal(t, cli.ExitOK, code)
+
+	code, _, _ = run("generate")
+	assert.Equal(t, cli.ExitOK, code)
+
+	_, err := os.Stat(filepath.Join(tmpDir, "cli/v1/item.proto"))
+	assert.NoError(t, err)
+
+	code, out, _ = run("diff")
+	assert.Equal(t, cli.ExitOK, code)
+	assert.Empty(t, out)
+
+	// Nothing changed, so buf generate is not run
+	code, out, _ = run("generate", "-buf")
+	assert.Equal(t, cli.ExitOK, code)
+	assert.NotContains(t, out, "buf generate")
+
+	descriptorsPath := filepath.Join(tmpDir, "descriptors.binpb")
+	code, _, _ = run("export", "-format", "descriptorset", "-out", descriptorsPath)
+	assert.Equal(t, cli.ExitOK, code)
+
+	_, err = os.Stat(descriptorsPath)
+	assert.NoError(t, err)
+
+	// The packages of a workspace are processed only once by the export
+	hookCalls := 0
+
+	workspace := sb.NewWorkspace()
+	wsPkg := workspace.NewPackage(sb.ProtoPackageConfig{
+		Name:               "cliws.v1",
+		GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/cliwsv1",
+		ProtoRoot:          tmpDir,
+		ConverterOutputDir: filepath.Join(tmpDir, "cliws"),
+		MessageHook: func(d sb.MessageData) error {
+			hookCalls++
+			return nil
+		},
+	})
+	wsPkg.NewFile(sb.FileSchema{Name: "order"}).NewMessage(sb.MessageSchema{Name: "Order", Fields: sb.FieldsMap{1: sb.String("id")}})
+
+	sb.RegisterWorkspace(workspace)
+
+	code, _, _ = run("export", "-format", "openapi", "-package", "cliws.v1")
+	assert.Equal(t, cli.ExitOK, code)
+	assert.Equal(t, 1, hookCalls)
+
+	// The generation stopped by the issues in the schemas is reported as such
+	strictPkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:               "clistrict.v1",
+		GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/clistrictv1",
+		ProtoRoot:          tmpDir,
+		ConverterOutputDir: filepath.Join(tmpDir, "clistrict"),
+		Lint:               sb.LintConfig{Strict: true},
+	})
+	strictPkg.NewFile(sb.FileSchema{Name: "item"}).NewMessage(sb.MessageSchema{Name: "bad_item", Fields: sb.FieldsMap{1: sb.String("name")}})
+
+	sb.Register(strictPkg)
+
+	code, _, errOut := run("generate")
+	assert.Equal(t, cli.ExitIssues, code)
+	assert.Contains(t, errOut, "Lint issues found")
+}
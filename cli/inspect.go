@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+)
+
+// Prints the structure of a package's files.
+func printPackage(w io.Writer, p *sb.ProtoPackage, files []sb.FileData) {
+	fmt.Fprintf(w, "package %s (%s)\n", p.GetName(), p.GetGoPackagePath())
+
+	for _, f := range files {
+		fmt.Fprintf(w, "  %s (%s)\n", path.Join(p.GetBasePath(), strings.ToLower(f.Name)), f.Syntax)
+
+		for _, e := range f.Enums {
+			printEnum(w, e, "    ")
+		}
+
+		for _, m := range f.Messages {
+			printMessage(w, p, m, "    ")
+		}
+
+		for _, s := range f.Services {
+			fmt.Fprintf(w, "    service %sService\n", strings.TrimSuffix(s.Resource, "Service"))
+			for _, h := range s.Handlers {
+				fmt.Fprintf(w, "      rpc %s(%s) returns (%s)\n", h.Name, h.Request.GetFullName(p), h.Response.GetFullName(p))
+			}
+		}
+	}
+}
+
+func printEnum(w io.Writer, e sb.EnumGroup, indent string) {
+	fmt.Fprintf(w, "%senum %s\n", indent, e.Name)
+
+	for _, v := range e.Values {
+		fmt.Fprintf(w, "%s  %d %s\n", indent, v.Number, v.Name)
+	}
+}
+
+func printMessage(w io.Writer, p *sb.ProtoPackage, m sb.MessageData, indent string) {
+	fmt.Fprintf(w, "%smessage %s\n", indent, m.Name)
+
+	for _, f := range m.Fields {
+		printField(w, p, f, indent+"  ")
+	}
+
+	for _, of := range m.Oneofs {
+		fmt.Fprintf(w, "%s  oneof %s\n", indent, of.Name)
+		for _, f := range of.Fields {
+			printField(w, p, f, indent+"    ")
+		}
+	}
+
+	for _, e := range m.Enums {
+		printEnum(w, e, indent+"  ")
+	}
+
+	for _, nested := range m.Messages {
+		printMessage(w, p, nested, indent+"  ")
+	}
+}
+
+func printField(w io.Writer, p *sb.ProtoPackage, f sb.FieldData, indent string) {
+	fieldType := f.ProtoType
+	if f.MessageRef != nil {
+		fieldType = f.MessageRef.GetFullName(p)
+	} else if f.EnumRef != nil {
+		fieldType = f.EnumRef.GetFullName(p)
+	}
+
+	if f.Label != "" {
+		fieldType = f.Label + " " + fieldType
+	}
+
+	fmt.Fprintf(w, "%s%d %s %s\n", indent, f.FieldNr, f.Name, fieldType)
+}
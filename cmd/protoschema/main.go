@@ -0,0 +1,160 @@
+// The protoschema command runs the subcommands of the cli package on the schemas defined in a go package.
+//
+// The schema package must register its packages (or workspaces) in an init function, with protoschema.Register or protoschema.RegisterWorkspace.
+// Since the schemas are defined in go, the command builds and runs a temporary program that imports the schema package, so it must be run from within the go module that contains it.
+//
+// Usage:
+//
+//	protoschema [-schema <package>] <command> [flags]
+//	protoschema [-schema <package>] watch [-buf] [-interval <duration>] [-debounce <duration>] [-path <path>]...
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/Rick-Phoenix/protoschema/cli"
+)
+
+const runnerTemplate = `// Code generated by protoschema. DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+
+	"github.com/Rick-Phoenix/protoschema/cli"
+
+	_ %q
+)
+
+func main() {
+	os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+}
+`
+
+// A temporary program that imports the schema package and runs the commands of the cli package.
+type runner struct {
+	schema string
+	dir    string
+	bin    string
+}
+
+// Creates the source of the runner inside the current module, so that it can import the schema package.
+func newRunner(schema string) (*runner, error) {
+	importPath, err := exec.Command("go", "list", "-f", "{{ .ImportPath }}", schema).Output()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find the schema package %q: %s", schema, commandError(err))
+	}
+
+	dir, err := os.MkdirTemp(".", ".protoschema-")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create the runner: %w", err)
+	}
+
+	r := &runner{schema: schema, dir: dir}
+
+	source := fmt.Sprintf(runnerTemplate, strings.TrimSpace(string(importPath)))
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(source), 0644); err != nil {
+		r.remove()
+		return nil, fmt.Errorf("Failed to create the runner: %w", err)
+	}
+
+	r.bin, err = filepath.Abs(filepath.Join(dir, "runner"))
+	if err != nil {
+		r.remove()
+		return nil, fmt.Errorf("Failed to create the runner: %w", err)
+	}
+
+	return r, nil
+}
+
+// Builds the runner with the current version of the schema package. The compiler errors are printed to stderr.
+func (r *runner) build() error {
+	cmd := exec.Command("go", "build", "-o", r.bin, "./"+filepath.ToSlash(r.dir))
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("Failed to build the schema package %q.", r.schema)
+	}
+
+	return nil
+}
+
+// Runs a command with the runner, and returns its exit code.
+func (r *runner) run(args ...string) int {
+	cmd := exec.Command(r.bin, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+
+		fmt.Fprintf(os.Stderr, "❌ Failed to run the schema package %q: %s\n", r.schema, err.Error())
+		return cli.ExitError
+	}
+
+	return cli.ExitOK
+}
+
+func (r *runner) remove() {
+	os.RemoveAll(r.dir)
+}
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	fs := flag.NewFlagSet("protoschema", flag.ContinueOnError)
+	schema := fs.String("schema", "./schema", "The go package where the schemas are defined and registered, as an import path or a relative path.")
+
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return cli.Run([]string{"help"}, os.Stdout, os.Stderr)
+		}
+		return cli.ExitUsage
+	}
+
+	if fs.NArg() == 0 {
+		return cli.Run(nil, os.Stdout, os.Stderr)
+	}
+
+	r, err := newRunner(*schema)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %s\n", err.Error())
+		return cli.ExitError
+	}
+	defer r.remove()
+
+	if fs.Arg(0) == "watch" {
+		return watch(r, fs.Args()[1:])
+	}
+
+	if err := r.build(); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %s\n", err.Error())
+		return cli.ExitError
+	}
+
+	return r.run(fs.Args()...)
+}
+
+// Returns the stderr output of a failed command, if available.
+func commandError(err error) string {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return strings.TrimSpace(string(exitErr.Stderr))
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"github.com/Rick-Phoenix/protoschema/cli"
+)
+
+// Watches the go packages of the current module that the schema package depends on (including itself), and regenerates the files whenever their source changes.
+// The schema documents (the files with the extensions in SchemaDocumentExtensions) inside these packages are also watched, along with the files and directories added with -path, for the documents that are loaded from other locations.
+// The changes are detected by polling, and they are debounced so that saving several files at once causes a single generation.
+// Since the generation is incremental, only the diagnostics and the changed files are printed.
+func watch(r *runner, args []string) int {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	runBuf := fs.Bool("buf", false, "Runs buf generate after the proto files are changed.")
+	interval := fs.Duration("interval", 500*time.Millisecond, "How often the source files are checked for changes.")
+	debounce := fs.Duration("debounce", 300*time.Millisecond, "How long to wait after the last change before regenerating.")
+
+	var paths []string
+	fs.Func("path", "A file or directory with schema documents to watch, besides the go packages. Can be repeated.", func(path string) error {
+		paths = append(paths, path)
+		return nil
+	})
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return cli.ExitOK
+		}
+		return cli.ExitUsage
+	}
+
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Unexpected arguments: %s\n", strings.Join(fs.Args(), " "))
+		return cli.ExitUsage
+	}
+
+	if *interval <= 0 || *debounce < 0 {
+		fmt.Fprintln(os.Stderr, "The interval must be positive, and the debounce cannot be negative.")
+		return cli.ExitUsage
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	generateArgs := []string{"generate"}
+	if *runBuf {
+		generateArgs = append(generateArgs, "-buf")
+	}
+
+	var dirs []string
+
+	regenerate := func() {
+		if err := r.build(); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %s\n", err.Error())
+		} else {
+			r.run(generateArgs...)
+		}
+
+		// The dependencies of the schema package may have changed
+		newDirs, err := watchedDirs(r.schema)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %s\n", err.Error())
+		} else {
+			dirs = newDirs
+		}
+	}
+
+	regenerate()
+
+	if len(dirs) == 0 {
+		fmt.Fprintf(os.Stderr, "❌ Could not find the source files of the schema package %q.\n", r.schema)
+		return cli.ExitError
+	}
+
+	fmt.Printf("👀 Watching %d package(s) and %d other path(s) for changes. Press Ctrl+C to stop.\n", len(dirs), len(paths))
+
+	lastState := sourceState(dirs, paths)
+	var changedAt time.Time
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return cli.ExitOK
+		case <-ticker.C:
+		}
+
+		if state := sourceState(dirs, paths); !maps.Equal(state, lastState) {
+			lastState = state
+			changedAt = time.Now()
+			continue
+		}
+
+		if !changedAt.IsZero() && time.Since(changedAt) >= *debounce {
+			changedAt = time.Time{}
+			regenerate()
+			// Changes caused by the generation itself (such as converters in a watched package) do not trigger another one
+			lastState = sourceState(dirs, paths)
+		}
+	}
+}
+
+// Returns the directories of the packages of the main module that the schema package depends on.
+func watchedDirs(schema string) ([]string, error) {
+	out, err := exec.Command("go", "list", "-deps", "-f", "{{ if and .Module .Module.Main }}{{ .Dir }}{{ end }}", schema).Output()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list the dependencies of the schema package %q: %s", schema, commandError(err))
+	}
+
+	var dirs []string
+	for line := range strings.SplitSeq(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			dirs = append(dirs, line)
+		}
+	}
+
+	return dirs, nil
+}
+
+// Returns the modification time and size of each go source file and schema document in the directories, and of the files inside the other paths, which are compared to detect changes.
+func sourceState(dirs []string, paths []string) map[string]string {
+	state := make(map[string]string)
+
+	addFile := func(path string) {
+		if info, err := os.Stat(path); err == nil {
+			state[path] = fmt.Sprintf("%d:%d", info.ModTime().UnixNano(), info.Size())
+		}
+	}
+
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || strings.HasSuffix(name, "_test.go") {
+				continue
+			}
+
+			if strings.HasSuffix(name, ".go") || isSchemaDocument(name) {
+				addFile(filepath.Join(dir, name))
+			}
+		}
+	}
+
+	// A path can be a single document (with any extension) or a directory, whose documents are watched recursively
+	for _, root := range paths {
+		filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() && (path == root || isSchemaDocument(path)) {
+				addFile(path)
+			}
+			return nil
+		})
+	}
+
+	return state
+}
+
+func isSchemaDocument(name string) bool {
+	return slices.Contains(sb.SchemaDocumentExtensions, filepath.Ext(name))
+}
@@ -0,0 +1,378 @@
+package protoschema
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The seed and the time used for the values of the conformance tests that are generated with the files, so that they only change when the schemas change. The timestamps with rules relative to the current time are still computed when the tests run.
+var (
+	conformanceTestsSeed int64 = 1
+	conformanceTestsTime       = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// Writes a go test file (in the package with the given name) with a test for each message of this package that has rules, such as TestPostRules or TestPostLocationRules for a nested message.
+// Each test uses protovalidate (from buf.build/go/protovalidate, which must be a dependency of the go module of the tests) to check that an instance of the generated type with the values created by Fake is accepted, and that each of the invalid variants created by FakeInvalid is rejected with the expected field path and rule id.
+// The values and the expected violations are derived from the rules defined with the builders of the fields, rather than from the compiled options, so the tests detect the differences between the rules defined in the schemas and the rules that are enforced by the compiled options. The tests are generated next to the converter when ConformanceTests is true in the package config.
+func (p *ProtoPackage) EmitConformanceTests(w io.Writer, packageName string, seed int64) error {
+	filesData, err := p.TryBuildFiles()
+	if err != nil {
+		return err
+	}
+
+	return p.writeConformanceTests(w, filesData, packageName, seed)
+}
+
+func (p *ProtoPackage) writeConformanceTests(w io.Writer, filesData []FileData, packageName string, seed int64) error {
+	files, err := p.compileFiles(filesData)
+	if err != nil {
+		return err
+	}
+
+	rules, err := p.builderRules(files)
+	if err != nil {
+		return err
+	}
+
+	f := newFaker(seed)
+	f.now = conformanceTestsTime
+	f.rules = rules
+	src := &fakeSource{imports: make(map[string]string), now: f.now, rules: rules}
+
+	var addMessages func(messages protoreflect.MessageDescriptors)
+	addMessages = func(messages protoreflect.MessageDescriptors) {
+		for i := range messages.Len() {
+			desc := messages.Get(i)
+			if desc.IsMapEntry() {
+				continue
+			}
+
+			src.conformanceTest(desc, f)
+			addMessages(desc.Messages())
+		}
+	}
+
+	for _, file := range files {
+		addMessages(file.Messages())
+	}
+
+	if src.body.Len() > 0 {
+		src.conformanceHelper()
+	}
+
+	formatted, err := src.source(packageName)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(formatted)
+	return err
+}
+
+// Returns the rules of the fields of the compiled messages as they are defined with the builders of their schemas, mapped by the full name of the field.
+// The fields of the messages that are not defined in this package keep the rules of their compiled options.
+func (p *ProtoPackage) builderRules(files []protoreflect.FileDescriptor) (fieldRulesOverride, error) {
+	schemas := make(map[protoreflect.FullName]*MessageSchema)
+
+	var addSchemas func(messages []*MessageSchema)
+	addSchemas = func(messages []*MessageSchema) {
+		for _, m := range messages {
+			schemas[protoreflect.FullName(p.Name+"."+m.GetName())] = m
+			addSchemas(m.messages)
+		}
+	}
+
+	for _, file := range p.fileSchemas {
+		addSchemas(file.messages)
+	}
+
+	out := make(fieldRulesOverride)
+	var err error
+
+	var addMessages func(messages protoreflect.MessageDescriptors)
+	addMessages = func(messages protoreflect.MessageDescriptors) {
+		for i := range messages.Len() {
+			desc := messages.Get(i)
+			addMessages(desc.Messages())
+
+			schema, exists := schemas[desc.FullName()]
+			if !exists {
+				continue
+			}
+
+			fields := desc.Fields()
+			for j := range fields.Len() {
+				fd := fields.Get(j)
+
+				builder, exists := schema.LookupField(string(fd.Name()))
+				if !exists {
+					continue
+				}
+
+				rules, rulesErr := builderFieldRules(builder)
+				if rulesErr != nil {
+					err = errors.Join(err, fmt.Errorf("Failed to read the rules of the field %q: %w", fd.FullName(), rulesErr))
+					continue
+				}
+
+				out[fd.FullName()] = rules
+			}
+		}
+	}
+
+	for _, file := range files {
+		addMessages(file.Messages())
+	}
+
+	return out, err
+}
+
+// Returns the rules of a field as they are defined with its builder, in the format of the protovalidate FieldRules. The rules of the items of repeated fields and of the keys and values of maps are included.
+func builderFieldRules(f FieldBuilder) (protoreflect.Message, error) {
+	rules := (&validate.FieldRules{}).ProtoReflect()
+	var err error
+
+	if internal, ok := f.(interface{ fieldInternal() *protoFieldInternal }); ok {
+		options := internal.fieldInternal().options
+
+		for _, name := range slices.Sorted(maps.Keys(options)) {
+			// The cel expressions are not evaluated natively
+			path, isRule := strings.CutPrefix(name, "(buf.validate.field).")
+			if !isRule || path == "cel" {
+				continue
+			}
+
+			err = errors.Join(err, setRule(rules, strings.Split(path, "."), options[name]))
+		}
+	}
+
+	switch b := f.(type) {
+	case *RepeatedField:
+		err = errors.Join(err, setTypeRules(rules, []string{"repeated", "items"}, b.field))
+	case *MapField:
+		err = errors.Join(err, setTypeRules(rules, []string{"map", "keys"}, b.keys), setTypeRules(rules, []string{"map", "values"}, b.values))
+	default:
+		err = errors.Join(err, setTypeRules(rules, nil, f))
+	}
+
+	return rules, err
+}
+
+// Sets the rules of the type of a field (such as string.min_len), inside the rules at the given path.
+func setTypeRules(rules protoreflect.Message, path []string, f FieldBuilder) error {
+	// The errors of the field were already reported when the files were processed
+	data, _ := f.Build(0, make(Set))
+	if len(data.Rules) == 0 {
+		return nil
+	}
+
+	// The rules of the well-known types are named after the type, such as any for google.protobuf.Any
+	typeName := data.ProtoBaseType
+	if rules.Descriptor().Fields().ByName(protoreflect.Name(typeName)) == nil {
+		typeName = strings.ToLower(typeName[strings.LastIndex(typeName, ".")+1:])
+	}
+
+	var err error
+	for _, name := range slices.Sorted(maps.Keys(data.Rules)) {
+		err = errors.Join(err, setRule(rules, slices.Concat(path, []string{typeName, name}), data.Rules[name]))
+	}
+
+	return err
+}
+
+// Sets the value of the rule at the given path (such as repeated.min_items), converting it to the type of the rule.
+func setRule(rules protoreflect.Message, path []string, value any) error {
+	ruleName := strings.Join(path, ".")
+
+	for _, name := range path[:len(path)-1] {
+		fd := rules.Descriptor().Fields().ByName(protoreflect.Name(name))
+		if fd == nil || fd.Message() == nil || fd.IsList() {
+			return fmt.Errorf("Unknown rule %q.", ruleName)
+		}
+
+		rules = rules.Mutable(fd).Message()
+	}
+
+	fd := rules.Descriptor().Fields().ByName(protoreflect.Name(path[len(path)-1]))
+	if fd == nil {
+		return fmt.Errorf("Unknown rule %q.", ruleName)
+	}
+
+	if !fd.IsList() {
+		v, err := ruleProtoValue(fd, value)
+		if err != nil {
+			return fmt.Errorf("Invalid value for the rule %q: %w", ruleName, err)
+		}
+
+		rules.Set(fd, v)
+		return nil
+	}
+
+	values := reflect.ValueOf(value)
+	if values.Kind() != reflect.Slice {
+		return fmt.Errorf("The value of the rule %q must be a list.", ruleName)
+	}
+
+	list := rules.Mutable(fd).List()
+	for i := range values.Len() {
+		v, err := ruleProtoValue(fd, values.Index(i).Interface())
+		if err != nil {
+			return fmt.Errorf("Invalid value for the rule %q: %w", ruleName, err)
+		}
+
+		list.Append(v)
+	}
+
+	return nil
+}
+
+// Converts the value of a rule, as it is stored by a builder, to the type of the rule.
+func ruleProtoValue(fd protoreflect.FieldDescriptor, value any) (protoreflect.Value, error) {
+	rv := reflect.ValueOf(value)
+	isNumber := rv.IsValid() && rv.Kind() != reflect.String && rv.Kind() != reflect.Bool && rv.CanConvert(reflect.TypeFor[float64]())
+
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		if b, ok := value.(bool); ok {
+			return protoreflect.ValueOfBool(b), nil
+		}
+	case protoreflect.StringKind:
+		if s, ok := value.(string); ok {
+			return protoreflect.ValueOfString(s), nil
+		}
+	case protoreflect.BytesKind:
+		switch b := value.(type) {
+		case []byte:
+			return protoreflect.ValueOfBytes(b), nil
+		case string:
+			return protoreflect.ValueOfBytes([]byte(b)), nil
+		}
+	case protoreflect.EnumKind:
+		if name, ok := value.(string); ok {
+			if ev := fd.Enum().Values().ByName(protoreflect.Name(name)); ev != nil {
+				return protoreflect.ValueOfEnum(ev.Number()), nil
+			}
+		} else if isNumber {
+			return protoreflect.ValueOfEnum(protoreflect.EnumNumber(rv.Convert(reflect.TypeFor[int64]()).Int())), nil
+		}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		if isNumber {
+			return protoreflect.ValueOfInt32(int32(rv.Convert(reflect.TypeFor[int64]()).Int())), nil
+		}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		if isNumber {
+			return protoreflect.ValueOfInt64(rv.Convert(reflect.TypeFor[int64]()).Int()), nil
+		}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		if isNumber {
+			return protoreflect.ValueOfUint32(uint32(rv.Convert(reflect.TypeFor[uint64]()).Uint())), nil
+		}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		if isNumber {
+			return protoreflect.ValueOfUint64(rv.Convert(reflect.TypeFor[uint64]()).Uint()), nil
+		}
+	case protoreflect.FloatKind:
+		if isNumber {
+			return protoreflect.ValueOfFloat32(float32(rv.Convert(reflect.TypeFor[float64]()).Float())), nil
+		}
+	case protoreflect.DoubleKind:
+		if isNumber {
+			return protoreflect.ValueOfFloat64(rv.Convert(reflect.TypeFor[float64]()).Float()), nil
+		}
+	case protoreflect.MessageKind:
+		switch v := value.(type) {
+		case *durationpb.Duration:
+			return protoreflect.ValueOfMessage(v.ProtoReflect()), nil
+		case *timestamppb.Timestamp:
+			return protoreflect.ValueOfMessage(v.ProtoReflect()), nil
+		case time.Duration:
+			return protoreflect.ValueOfMessage(durationpb.New(v).ProtoReflect()), nil
+		case string:
+			if d, err := time.ParseDuration(v); err == nil && fd.Message().FullName() == "google.protobuf.Duration" {
+				return protoreflect.ValueOfMessage(durationpb.New(d).ProtoReflect()), nil
+			}
+		}
+	}
+
+	return protoreflect.Value{}, fmt.Errorf("Unexpected value %v (%T) for a field of type %s.", value, value, fd.Kind())
+}
+
+// Writes the test for a message, if it has rules that can be violated.
+func (s *fakeSource) conformanceTest(desc protoreflect.MessageDescriptor, f *faker) {
+	valid := f.message(desc)
+	invalid := f.invalidFakes(desc, valid)
+
+	if len(invalid) == 0 {
+		return
+	}
+
+	cases := make([]string, len(invalid))
+	for i, fake := range invalid {
+		cases[i] = fmt.Sprintf("{%s, %s, %s},", strconv.Quote(fake.Field), strconv.Quote(fake.RuleId), s.message(fake.Message))
+	}
+
+	funcName := "Test" + strings.ReplaceAll(goIdentName(desc), "_", "") + "Rules"
+
+	fmt.Fprintf(&s.body, "\nfunc %s(t *testing.T) {\n\tcheckRules(t, %s, []ruleCase{\n%s\n})\n}\n", funcName, s.message(valid), strings.Join(cases, "\n"))
+}
+
+// Writes the types and the function shared by the tests of the messages.
+func (s *fakeSource) conformanceHelper() {
+	tests := s.body.String()
+	s.body.Reset()
+
+	s.importPackage("testing")
+	s.importPackage("errors")
+	s.importPackage("buf.build/go/protovalidate")
+	s.importPackage("google.golang.org/protobuf/proto")
+
+	s.body.WriteString(`
+// An instance of a message that must be rejected, with the path of the field and the id of the rule that it violates.
+type ruleCase struct {
+	field  string
+	ruleId string
+	msg    proto.Message
+}
+
+// Checks that the valid message is accepted and that each case is rejected with the expected violation.
+func checkRules(t *testing.T, valid proto.Message, cases []ruleCase) {
+	t.Helper()
+
+	if err := protovalidate.Validate(valid); err != nil {
+		t.Errorf("The valid message was rejected: %v", err)
+	}
+
+	for _, c := range cases {
+		var validationErr *protovalidate.ValidationError
+		if !errors.As(protovalidate.Validate(c.msg), &validationErr) {
+			t.Errorf("The message for %s (%s) was accepted.", c.field, c.ruleId)
+			continue
+		}
+
+		found := false
+		for _, violation := range validationErr.Violations {
+			if protovalidate.FieldPathString(violation.Proto.GetField()) == c.field && violation.Proto.GetRuleId() == c.ruleId {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Errorf("The message for %s (%s) was rejected with other violations: %v", c.field, c.ruleId, validationErr)
+		}
+	}
+}
+`)
+	s.body.WriteString(tests)
+}
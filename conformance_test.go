@@ -0,0 +1,236 @@
+package protoschema_test
+
+import (
+	"bytes"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"github.com/stretchr/testify/assert"
+	gengo "google.golang.org/protobuf/cmd/protoc-gen-go/internal_gengo"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// A field whose compiled options do not include the rules of its builder, as with a mistake in the rendering of the options.
+type driftField struct {
+	sb.FieldBuilder
+}
+
+func (d driftField) Build(fieldNr uint32, imports sb.Set) (sb.FieldData, error) {
+	data, err := d.FieldBuilder.Build(fieldNr, imports)
+	data.Options = nil
+	return data, err
+}
+
+func TestConformanceTests(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:               "rules.v1",
+		GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/rulesv1",
+		ProtoRoot:          tmpDir,
+		ConverterOutputDir: filepath.Join(tmpDir, "converter"),
+		ConformanceTests:   true,
+	})
+
+	file := pkg.NewFile(sb.FileSchema{Name: "post"})
+
+	post := file.NewMessage(sb.MessageSchema{Name: "Post"})
+	location := post.NestedMessage(sb.MessageSchema{
+		Name:   "Location",
+		Fields: sb.FieldsMap{1: sb.Double("lat").Gte(-90).Lte(90)},
+	})
+
+	post.Fields = sb.FieldsMap{
+		1: sb.String("title").MinLen(3).MaxLen(50),
+		2: sb.Map("labels", sb.String("").MinLen(2), sb.Int32("").Gt(0)),
+		3: sb.MsgField("location", location),
+		4: sb.Timestamp("published_at").LtNow(),
+		5: sb.Repeated("tags", sb.String("").MinLen(2)).MinItems(1),
+		6: driftField{sb.String("code").MinLen(4)},
+	}
+
+	file.NewMessage(sb.MessageSchema{Name: "Empty", Fields: sb.FieldsMap{1: sb.String("note")}})
+
+	var first, second bytes.Buffer
+	assert.NoError(t, pkg.EmitConformanceTests(&first, "converter_test", 1))
+	assert.NoError(t, pkg.EmitConformanceTests(&second, "converter_test", 1))
+
+	// The values only change with the schemas, since those relative to the current time are computed when the tests run
+	assert.Equal(t, first.String(), second.String())
+
+	typeCheckConformanceTests(t, pkg, first.Bytes())
+
+	src := first.String()
+	assert.Contains(t, src, sb.GeneratedHeader)
+	assert.Contains(t, src, "package converter_test")
+	assert.Contains(t, src, `"buf.build/go/protovalidate"`)
+	assert.Contains(t, src, "func TestPostRules(t *testing.T) {")
+	assert.Contains(t, src, "func TestPostLocationRules(t *testing.T) {")
+	assert.NotContains(t, src, "TestEmptyRules")
+	assert.Contains(t, src, `{"title", "string.min_len", &rulesv1.Post{`)
+	assert.Contains(t, src, `{"title", "string.max_len", &rulesv1.Post{`)
+	assert.Contains(t, src, `{"labels[\"a\"]", "string.min_len", &rulesv1.Post{`)
+	assert.Contains(t, src, `"int32.gt", &rulesv1.Post{`)
+	assert.Contains(t, src, `{"location.lat", "double.gte_lte", &rulesv1.Post{`)
+	assert.Contains(t, src, `{"published_at", "timestamp.lt_now", &rulesv1.Post{`)
+	assert.Contains(t, src, "PublishedAt: timestamppb.New(time.Now().Add(")
+	assert.Contains(t, src, `{"tags", "repeated.min_items", &rulesv1.Post{`)
+	assert.Contains(t, src, `{"tags[0]", "string.min_len", &rulesv1.Post{`)
+
+	// The expected violations come from the rules of the builders, so the rules that are missing from the compiled options are still tested
+	assert.Contains(t, src, `{"code", "string.min_len", &rulesv1.Post{`)
+
+	assert.NoError(t, pkg.Generate())
+
+	generated, err := os.ReadFile(filepath.Join(tmpDir, "converter", "converter_rules_test.go"))
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(generated), "func TestPostRules(t *testing.T) {")
+	}
+}
+
+// The api of buf.build/go/protovalidate that is used by the conformance tests, which is not a dependency of this module.
+const protovalidateStub = `package protovalidate
+
+import (
+	"buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	"google.golang.org/protobuf/proto"
+)
+
+type Violation struct {
+	Proto *validate.Violation
+}
+
+type ValidationError struct {
+	Violations []*Violation
+}
+
+func (e *ValidationError) Error() string { return "" }
+
+type ValidationOption interface{}
+
+func Validate(msg proto.Message, options ...ValidationOption) error { return nil }
+
+func FieldPathString(path *validate.FieldPath) string { return "" }
+`
+
+// An importer that resolves the packages that were type-checked by the test before the others.
+type checkedImporter struct {
+	packages map[string]*types.Package
+	fallback types.Importer
+}
+
+func (i checkedImporter) Import(importPath string) (*types.Package, error) {
+	if pkg, exists := i.packages[importPath]; exists {
+		return pkg, nil
+	}
+
+	return i.fallback.Import(importPath)
+}
+
+// Type-checks the conformance tests of a package, along with the go types that protoc-gen-go generates for its files and the protovalidate api.
+func typeCheckConformanceTests(t *testing.T, pkg *sb.ProtoPackage, src []byte) {
+	t.Helper()
+
+	set, err := pkg.BuildDescriptors()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// The request for protoc-gen-go includes the imported files, which must precede the files that import them
+	request := &pluginpb.CodeGeneratorRequest{}
+	added := make(map[string]bool)
+
+	var addFile func(file *descriptorpb.FileDescriptorProto)
+	addFile = func(file *descriptorpb.FileDescriptorProto) {
+		if added[file.GetName()] {
+			return
+		}
+		added[file.GetName()] = true
+
+		for _, dep := range file.GetDependency() {
+			desc, err := protoregistry.GlobalFiles.FindFileByPath(dep)
+			if err == nil {
+				addFile(protodesc.ToFileDescriptorProto(desc))
+			}
+		}
+
+		request.ProtoFile = append(request.ProtoFile, file)
+	}
+
+	for _, file := range set.File {
+		request.FileToGenerate = append(request.FileToGenerate, file.GetName())
+		addFile(file)
+	}
+
+	plugin, err := protogen.Options{}.New(request)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, file := range plugin.Files {
+		if file.Generate {
+			gengo.GenerateFile(plugin, file)
+		}
+	}
+
+	response := plugin.Response()
+	if !assert.Empty(t, response.GetError()) {
+		return
+	}
+
+	fset := token.NewFileSet()
+	imp := checkedImporter{packages: make(map[string]*types.Package), fallback: importer.ForCompiler(fset, "source", nil)}
+
+	check := func(importPath string, sources map[string]string) bool {
+		var files []*ast.File
+		for name, content := range sources {
+			file, err := parser.ParseFile(fset, name, content, 0)
+			if !assert.NoError(t, err) {
+				return false
+			}
+			files = append(files, file)
+		}
+
+		checked, err := (&types.Config{Importer: imp}).Check(importPath, fset, files, nil)
+		if !assert.NoError(t, err, importPath) {
+			return false
+		}
+
+		imp.packages[importPath] = checked
+		return true
+	}
+
+	if !check("buf.build/go/protovalidate", map[string]string{"protovalidate.go": protovalidateStub}) {
+		return
+	}
+
+	// The generated files are named after the import path of their go package
+	generated := make(map[string]map[string]string)
+	for _, file := range response.GetFile() {
+		importPath := path.Dir(file.GetName())
+		if generated[importPath] == nil {
+			generated[importPath] = make(map[string]string)
+		}
+		generated[importPath][file.GetName()] = file.GetContent()
+	}
+
+	for importPath, sources := range generated {
+		if !check(importPath, sources) {
+			return
+		}
+	}
+
+	check("converter_test", map[string]string{"converter_rules_test.go": string(src)})
+}
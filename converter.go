@@ -1,6 +1,9 @@
 package protoschema
 
-import "reflect"
+import (
+	"fmt"
+	"reflect"
+)
 
 // By default, this package will try to automatically generate functions that can convert messages with specific models (like database items) into their respective message type. If this function is defined, it will take over that role, and it will receive the data for each message field.
 type ConverterFunc func(ConverterFuncData)
@@ -17,6 +20,8 @@ type ConverterFuncData struct {
 type modelFieldData struct {
 	Name       string
 	IsInternal bool
+	// The function that converts the value of this field, if it is a message with a converter (prefixed with the converter's package, if it belongs to another package of the workspace).
+	Converter string
 }
 
 type messageConverter struct {
@@ -49,12 +54,34 @@ func (m *MessageSchema) createFieldConverter(converter *messageConverter, modelF
 		if msgRef := pfield.GetMessageRef(); msgRef != nil && msgRef.Model != nil {
 			if msgRef.IsInternal(m.Package) {
 				fieldConvData.IsInternal = true
+				fieldConvData.Converter = converterFuncName(msgRef.Name, pfield.IsRepeated())
 				if pfield.IsRepeated() {
 					m.Package.converter.RepeatedConverters[msgRef.Name] = present
 				}
+			} else if m.Package.sharesWorkspace(msgRef.Package) && msgRef.Package.converterFunc == nil {
+				// Messages from other packages in the workspace are converted with the converter of their package
+				if importPath := msgRef.Package.getConverterImportPath(); importPath != "" {
+					fieldConvData.IsInternal = true
+					fieldConvData.Converter = msgRef.Package.converterPackage + "." + converterFuncName(msgRef.Name, pfield.IsRepeated())
+					m.Package.converter.Imports[importPath] = present
+					if pfield.IsRepeated() {
+						msgRef.Package.converter.RepeatedConverters[msgRef.Name] = present
+					}
+				} else {
+					fmt.Printf("Warning: the field %q of the message %q will not be converted with the converter of the package %q, because its import path cannot be determined. Define the GoModule and a relative ConverterOutputDir for that package.\n", modelField.Name, m.GetName(), msgRef.Package.GetName())
+				}
 			}
 		}
 	}
 
 	converter.Fields = append(converter.Fields, fieldConvData)
 }
+
+// Returns the name of the converter function for the given message.
+func converterFuncName(resource string, repeated bool) string {
+	if repeated {
+		return resource + "sTo" + resource + "sMsg"
+	}
+
+	return resource + "To" + resource + "Msg"
+}
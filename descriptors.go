@@ -0,0 +1,162 @@
+package protoschema
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	_ "google.golang.org/protobuf/types/gofeaturespb"
+)
+
+// Returns the path of the file, relative to the ProtoRoot.
+func (p *ProtoPackage) getFilePath(fileData FileData) string {
+	return path.Join(p.GetBasePath(), strings.ToLower(fileData.Name))
+}
+
+// Renders the proto file for the given file data.
+func (p *ProtoPackage) renderFile(fileData FileData) ([]byte, error) {
+	delete(fileData.Imports, p.getFilePath(fileData))
+
+	var outputBuffer bytes.Buffer
+	if err := p.tmpl.ExecuteTemplate(&outputBuffer, "protoFile", fileData); err != nil {
+		return nil, fmt.Errorf("Failed to execute template: %w", err)
+	}
+
+	return outputBuffer.Bytes(), nil
+}
+
+// Processes the schemas of the package and compiles the resulting proto files in memory, returning their descriptors.
+// The descriptors include the SourceCodeInfo, which contains the comments defined in the schemas (and which is used by protoc-gen-go for the doc comments of the generated code).
+// The imports are resolved from the files of this package, the files in the ProtoRoot, the well-known types and the files that are registered in the global protobuf registry (such as buf/validate/validate.proto).
+func (p *ProtoPackage) BuildDescriptors() (*descriptorpb.FileDescriptorSet, error) {
+	filesData, err := p.TryBuildFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.BuildDescriptorsFromFiles(filesData)
+}
+
+// Compiles the data returned by TryBuildFiles and returns the descriptors of BuildDescriptors, so that the schemas are not processed again.
+func (p *ProtoPackage) BuildDescriptorsFromFiles(filesData []FileData) (*descriptorpb.FileDescriptorSet, error) {
+	files, err := p.compileFiles(filesData)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+
+	for _, f := range files {
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(f))
+	}
+
+	return set, nil
+}
+
+// Renders the given files and compiles them in memory, returning their linked descriptors.
+func (p *ProtoPackage) compileFiles(filesData []FileData) ([]protoreflect.FileDescriptor, error) {
+	sources, paths, err := p.renderSources(filesData)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.compileSources(sources, paths)
+}
+
+// Renders the given files, returning the sources that can be used to compile them (including the files of the other packages in the workspace) and the paths of the rendered files.
+func (p *ProtoPackage) renderSources(filesData []FileData) (map[string]string, []string, error) {
+	sources := make(map[string]string)
+	var paths []string
+
+	// The files of the other packages in the workspace can be imported even if they have not been generated yet
+	if p.workspace != nil {
+		maps.Copy(sources, p.workspace.sources)
+	}
+
+	for _, fileData := range filesData {
+		content, err := p.renderFile(fileData)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		filePath := p.getFilePath(fileData)
+		sources[filePath] = string(content)
+		paths = append(paths, filePath)
+	}
+
+	return sources, paths, nil
+}
+
+// Compiles the files at the given paths in memory, resolving them from the given sources, the proto root and the global registry.
+func (p *ProtoPackage) compileSources(sources map[string]string, paths []string) ([]protoreflect.FileDescriptor, error) {
+	resolvers := protocompile.CompositeResolver{
+		&protocompile.SourceResolver{Accessor: protocompile.SourceAccessorFromMap(sources)},
+	}
+
+	if p.protoRoot != "" {
+		resolvers = append(resolvers, &protocompile.SourceResolver{ImportPaths: []string{p.protoRoot}})
+	}
+
+	resolvers = append(resolvers, protocompile.ResolverFunc(func(filePath string) (protocompile.SearchResult, error) {
+		desc, err := protoregistry.GlobalFiles.FindFileByPath(filePath)
+		if err != nil {
+			return protocompile.SearchResult{}, err
+		}
+
+		return protocompile.SearchResult{Desc: desc}, nil
+	}))
+
+	compiler := protocompile.Compiler{
+		Resolver:       protocompile.WithStandardImports(resolvers),
+		SourceInfoMode: protocompile.SourceInfoStandard,
+	}
+
+	files, err := compiler.Compile(context.Background(), paths...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to compile the proto files: %w", err)
+	}
+
+	out := make([]protoreflect.FileDescriptor, len(files))
+	for i, f := range files {
+		out[i] = f
+	}
+
+	return out, nil
+}
+
+// Writes the descriptors for the given files to the path of the descriptor set file, in the binary format.
+func (p *ProtoPackage) writeDescriptorSet(filesData []FileData) error {
+	set, err := p.BuildDescriptorsFromFiles(filesData)
+	if err != nil {
+		return err
+	}
+
+	content, err := proto.Marshal(set)
+	if err != nil {
+		return fmt.Errorf("Failed to encode the descriptor set: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p.descriptorSetPath), 0755); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(p.descriptorSetPath, content, 0644); err != nil {
+		return fmt.Errorf("Failed to write the descriptor set: %w", err)
+	}
+
+	fmt.Printf("✅ Successfully generated the descriptor set at: %s\n", p.descriptorSetPath)
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package protoschema_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestComments(t *testing.T) {
+	tmpDir := t.TempDir()
+	descriptorSetPath := filepath.Join(tmpDir, "descriptors.binpb")
+
+	pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:               "docs.v1",
+		GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/docsv1",
+		ProtoRoot:          tmpDir,
+		ConverterOutputDir: filepath.Join(tmpDir, "converter"),
+		DescriptorSetFile:  descriptorSetPath,
+	})
+
+	file := pkg.NewFile(sb.FileSchema{Name: "docs", Doc: "The documented file."})
+
+	file.NewEnum(sb.EnumGroup{
+		Name: "Status",
+		Doc:  "The status of an item.",
+		Values: []sb.EnumValue{
+			{Name: "STATUS_UNSPECIFIED", Number: 0},
+			{Name: "STATUS_ACTIVE", Number: 1, Doc: "The item is active.", TrailingComment: "The default status."},
+		},
+	})
+
+	item := file.NewMessage(sb.MessageSchema{
+		Name:            "Item",
+		Doc:             "An item.\nIt has two lines.",
+		TrailingComment: "The main message.",
+		Fields: sb.FieldsMap{
+			1: sb.Int64("id").Doc("The id of the item."),
+			2: sb.String("name").TrailingComment("The name of the item."),
+		},
+	})
+
+	item.NewOneof(sb.OneofGroup{Name: "source", Doc: "Where the item comes from.", Fields: sb.OneofFields{3: sb.String("url"), 4: sb.String("path")}})
+
+	file.NewService(sb.ServiceSchema{
+		Resource: "Item",
+		Doc:      "The service for items.",
+		Handlers: sb.HandlersMap{
+			"GetItem": {Request: item, Response: item, Doc: "Returns an item."},
+		},
+	})
+
+	assert.NoError(t, pkg.Generate())
+
+	generated, err := os.ReadFile(filepath.Join(tmpDir, "docs/v1/docs.proto"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(generated), "// An item.\n// It has two lines.\nmessage Item { // The main message.")
+	assert.Contains(t, string(generated), "STATUS_ACTIVE = 1; // The default status.")
+
+	set, err := pkg.BuildDescriptors()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	files, err := protodesc.NewFiles(set)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	desc, err := files.FindFileByPath("docs/v1/docs.proto")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	leading := func(d protoreflect.Descriptor) string {
+		return strings.TrimSpace(desc.SourceLocations().ByDescriptor(d).LeadingComments)
+	}
+
+	msg := desc.Messages().ByName("Item")
+	assert.Equal(t, "An item.\n It has two lines.", leading(msg))
+	assert.Equal(t, "The main message.", strings.TrimSpace(desc.SourceLocations().ByDescriptor(msg).TrailingComments))
+	assert.Equal(t, "The id of the item.", leading(msg.Fields().ByName("id")))
+	assert.Equal(t, "The name of the item.", strings.TrimSpace(desc.SourceLocations().ByDescriptor(msg.Fields().ByName("name")).TrailingComments))
+	assert.Equal(t, "Where the item comes from.", leading(msg.Oneofs().ByName("source")))
+
+	enum := desc.Enums().ByName("Status")
+	assert.Equal(t, "The status of an item.", leading(enum))
+	assert.Equal(t, "The item is active.", leading(enum.Values().ByName("STATUS_ACTIVE")))
+
+	service := desc.Services().ByName("ItemService")
+	assert.Equal(t, "The service for items.", leading(service))
+	assert.Equal(t, "Returns an item.", leading(service.Methods().ByName("GetItem")))
+
+	content, err := os.ReadFile(descriptorSetPath)
+	assert.NoError(t, err)
+
+	written := &descriptorpb.FileDescriptorSet{}
+	assert.NoError(t, proto.Unmarshal(content, written))
+	if assert.Len(t, written.GetFile(), 1) {
+		assert.NotEmpty(t, written.GetFile()[0].GetSourceCodeInfo().GetLocation())
+	}
+}
@@ -0,0 +1,358 @@
+package protoschema
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// The syntax of the files that use editions. It is set automatically when a FileSchema has an Edition.
+const SyntaxEditions = "editions"
+
+// The editions supported for proto files.
+const (
+	Edition2023 = "2023"
+	Edition2024 = "2024"
+)
+
+var supportedEditions = []string{Edition2023, Edition2024}
+
+const goFeaturesImport = "google/protobuf/go_features.proto"
+
+// The values for the field_presence feature.
+type FieldPresence string
+
+const (
+	FieldPresenceExplicit       FieldPresence = "EXPLICIT"
+	FieldPresenceImplicit       FieldPresence = "IMPLICIT"
+	FieldPresenceLegacyRequired FieldPresence = "LEGACY_REQUIRED"
+)
+
+// The values for the enum_type feature.
+type EnumType string
+
+const (
+	EnumTypeOpen   EnumType = "OPEN"
+	EnumTypeClosed EnumType = "CLOSED"
+)
+
+// The values for the repeated_field_encoding feature.
+type RepeatedFieldEncoding string
+
+const (
+	RepeatedFieldEncodingPacked   RepeatedFieldEncoding = "PACKED"
+	RepeatedFieldEncodingExpanded RepeatedFieldEncoding = "EXPANDED"
+)
+
+// The values for the utf8_validation feature.
+type Utf8Validation string
+
+const (
+	Utf8ValidationVerify Utf8Validation = "VERIFY"
+	Utf8ValidationNone   Utf8Validation = "NONE"
+)
+
+// The values for the (pb.go).api_level feature.
+type GoAPILevel string
+
+const (
+	GoAPILevelOpen   GoAPILevel = "API_OPEN"
+	GoAPILevelHybrid GoAPILevel = "API_HYBRID"
+	GoAPILevelOpaque GoAPILevel = "API_OPAQUE"
+)
+
+// The feature settings for a file, message, enum or field that uses editions. Empty values are not rendered, so the element inherits them from its parent (or from the edition's defaults).
+// Features can only be set on the elements that they apply to:
+// field_presence, repeated_field_encoding and utf8_validation can be set on files and fields, enum_type on files and enums, and the Go api_level on files and messages.
+type Features struct {
+	FieldPresence         FieldPresence
+	EnumType              EnumType
+	RepeatedFieldEncoding RepeatedFieldEncoding
+	Utf8Validation        Utf8Validation
+	GoAPILevel            GoAPILevel
+}
+
+type featureTarget string
+
+const (
+	featureTargetFile    featureTarget = "files"
+	featureTargetMessage featureTarget = "messages"
+	featureTargetEnum    featureTarget = "enums"
+	featureTargetField   featureTarget = "fields"
+)
+
+type featureSpec struct {
+	name    string
+	value   func(Features) any
+	allowed []string
+	targets []featureTarget
+}
+
+var featureSpecs = []featureSpec{
+	{
+		name:    "field_presence",
+		value:   func(f Features) any { return f.FieldPresence },
+		allowed: []string{string(FieldPresenceExplicit), string(FieldPresenceImplicit), string(FieldPresenceLegacyRequired)},
+		targets: []featureTarget{featureTargetFile, featureTargetField},
+	},
+	{
+		name:    "enum_type",
+		value:   func(f Features) any { return f.EnumType },
+		allowed: []string{string(EnumTypeOpen), string(EnumTypeClosed)},
+		targets: []featureTarget{featureTargetFile, featureTargetEnum},
+	},
+	{
+		name:    "repeated_field_encoding",
+		value:   func(f Features) any { return f.RepeatedFieldEncoding },
+		allowed: []string{string(RepeatedFieldEncodingPacked), string(RepeatedFieldEncodingExpanded)},
+		targets: []featureTarget{featureTargetFile, featureTargetField},
+	},
+	{
+		name:    "utf8_validation",
+		value:   func(f Features) any { return f.Utf8Validation },
+		allowed: []string{string(Utf8ValidationVerify), string(Utf8ValidationNone)},
+		targets: []featureTarget{featureTargetFile, featureTargetField},
+	},
+	{
+		name:    "(pb.go).api_level",
+		value:   func(f Features) any { return f.GoAPILevel },
+		allowed: []string{string(GoAPILevelOpen), string(GoAPILevelHybrid), string(GoAPILevelOpaque)},
+		targets: []featureTarget{featureTargetFile, featureTargetMessage},
+	},
+}
+
+// Returns true if none of the features are set.
+func (f Features) IsZero() bool {
+	return f == Features{}
+}
+
+// Returns the options that set these features.
+func (f Features) options() []ProtoOption {
+	var out []ProtoOption
+
+	for _, spec := range featureSpecs {
+		if val := spec.value(f); fmt.Sprint(val) != "" {
+			out = append(out, ProtoOption{Name: "features." + spec.name, Value: val})
+		}
+	}
+
+	return out
+}
+
+// Returns these features, using the parent's features for the values that are not set.
+func (f Features) inherit(parent Features) Features {
+	if f.FieldPresence == "" {
+		f.FieldPresence = parent.FieldPresence
+	}
+	if f.EnumType == "" {
+		f.EnumType = parent.EnumType
+	}
+	if f.RepeatedFieldEncoding == "" {
+		f.RepeatedFieldEncoding = parent.RepeatedFieldEncoding
+	}
+	if f.Utf8Validation == "" {
+		f.Utf8Validation = parent.Utf8Validation
+	}
+	if f.GoAPILevel == "" {
+		f.GoAPILevel = parent.GoAPILevel
+	}
+
+	return f
+}
+
+// Checks that the features have valid values and that they can be set on the given type of element.
+func (f Features) validate(target featureTarget) error {
+	var err error
+
+	for _, spec := range featureSpecs {
+		val := fmt.Sprint(spec.value(f))
+		if val == "" {
+			continue
+		}
+
+		if !slices.Contains(spec.allowed, val) {
+			err = errors.Join(err, fmt.Errorf("Invalid value %q for the %s feature.", val, spec.name))
+		}
+
+		if !slices.Contains(spec.targets, target) {
+			err = errors.Join(err, fmt.Errorf("The %s feature cannot be set on %s.", spec.name, target))
+		}
+	}
+
+	if target == featureTargetFile && f.FieldPresence == FieldPresenceLegacyRequired {
+		err = errors.Join(err, fmt.Errorf("The LEGACY_REQUIRED field presence cannot be set as the default for a file."))
+	}
+
+	return err
+}
+
+// Returns the features set on the file. When a file with an Edition also has a proto2 or proto3 Syntax, it is migrated to editions, and the features that preserve the semantics of the original syntax are added to those that were not set explicitly.
+func (f *FileSchema) resolvedFeatures() Features {
+	if f == nil {
+		return Features{}
+	}
+
+	if f.Edition == "" {
+		return f.Features
+	}
+
+	switch f.Syntax {
+	case SyntaxProto3:
+		return f.Features.inherit(Features{FieldPresence: FieldPresenceImplicit})
+	case SyntaxProto2:
+		return f.Features.inherit(Features{
+			EnumType:              EnumTypeClosed,
+			RepeatedFieldEncoding: RepeatedFieldEncodingExpanded,
+			Utf8Validation:        Utf8ValidationNone,
+		})
+	}
+
+	return f.Features
+}
+
+// Checks the edition and the features of the file.
+func (f *FileSchema) checkEdition() error {
+	if f.Edition == "" {
+		if !f.Features.IsZero() {
+			return fmt.Errorf("Features can only be set in files that use editions.")
+		}
+
+		return nil
+	}
+
+	var err error
+
+	if !slices.Contains(supportedEditions, f.Edition) {
+		err = errors.Join(err, fmt.Errorf("Unsupported edition %q.", f.Edition))
+	}
+
+	if f.Syntax != "" && f.Syntax != SyntaxProto2 && f.Syntax != SyntaxProto3 && f.Syntax != SyntaxEditions {
+		err = errors.Join(err, fmt.Errorf("Cannot migrate to editions from the unsupported syntax %q.", f.Syntax))
+	}
+
+	return errors.Join(err, f.Features.validate(featureTargetFile))
+}
+
+// Returns true if the enum is closed. Enums in proto2 files are always closed, while enums in editions files are closed if they (or their file) have the CLOSED enum_type.
+func (e *EnumGroup) isClosed() bool {
+	switch e.File.GetSyntax() {
+	case SyntaxProto2:
+		return true
+	case SyntaxEditions:
+		return e.Features.inherit(e.File.resolvedFeatures()).EnumType == EnumTypeClosed
+	}
+
+	return false
+}
+
+// Checks the features of a field in a file that uses editions, translating the optional keyword and the required label to the equivalent field_presence feature.
+func checkFieldFeatures(f *FieldData, file *FileSchema, inOneof bool) error {
+	var err error
+
+	presence := f.Features.FieldPresence
+	isMessage := f.IsNonScalar && !f.Repeated && !f.IsMap
+	setPresence := func(p FieldPresence) {
+		presence = p
+		opt, _ := getProtoOption("features.field_presence", p)
+		f.Options = append(f.Options, opt)
+	}
+
+	if presence != "" && inOneof {
+		err = errors.Join(err, fmt.Errorf("Field %q cannot specify a field presence because it is part of a oneof.", f.Name))
+	}
+
+	if f.Optional && !f.Repeated {
+		if presence != "" && presence != FieldPresenceExplicit {
+			err = errors.Join(err, fmt.Errorf("Field %q cannot be optional because it has the %s field presence.", f.Name, presence))
+		} else if presence == "" && !isMessage && file.resolvedFeatures().FieldPresence == FieldPresenceImplicit {
+			setPresence(FieldPresenceExplicit)
+		}
+	}
+
+	if f.LabelRequired {
+		if inOneof {
+			err = errors.Join(err, fmt.Errorf("Field %q cannot have the required label because it is part of a oneof.", f.Name))
+		}
+
+		if presence != "" && presence != FieldPresenceLegacyRequired {
+			err = errors.Join(err, fmt.Errorf("Field %q cannot be required because it has the %s field presence.", f.Name, presence))
+		} else if presence == "" {
+			setPresence(FieldPresenceLegacyRequired)
+		}
+	}
+
+	if presence == "" {
+		presence = file.resolvedFeatures().FieldPresence
+	}
+
+	if presence == FieldPresenceImplicit && !isMessage && !f.Repeated && !f.IsMap && !inOneof {
+		if f.Default != nil {
+			err = errors.Join(err, fmt.Errorf("Field %q cannot have a default value because it has implicit presence.", f.Name))
+		}
+
+		if f.EnumRef != nil && f.EnumRef.isClosed() {
+			err = errors.Join(err, fmt.Errorf("Field %q has implicit presence, so it cannot use the closed enum %q.", f.Name, f.EnumRef.GetName()))
+		}
+	}
+
+	return err
+}
+
+var packableTypes = []string{"int32", "int64", "uint32", "uint64", "sint32", "sint64", "fixed32", "fixed64", "sfixed32", "sfixed64", "float", "double", "bool"}
+
+// Checks that the features set on a field can be applied to its type.
+func checkFieldFeatureTypes(f FieldData) error {
+	if f.Features.IsZero() {
+		return nil
+	}
+
+	err := f.Features.validate(featureTargetField)
+	isMessage := f.IsNonScalar && !f.Repeated && !f.IsMap
+
+	if f.Features.FieldPresence != "" {
+		if f.Repeated || f.IsMap {
+			err = errors.Join(err, fmt.Errorf("Repeated and map fields cannot specify a field presence."))
+		} else if isMessage && f.Features.FieldPresence == FieldPresenceImplicit {
+			err = errors.Join(err, fmt.Errorf("Message fields cannot have implicit presence."))
+		}
+	}
+
+	if f.Features.RepeatedFieldEncoding != "" {
+		if !f.Repeated {
+			err = errors.Join(err, fmt.Errorf("Only repeated fields can specify a repeated field encoding."))
+		} else if f.Features.RepeatedFieldEncoding == RepeatedFieldEncodingPacked && f.EnumRef == nil && !slices.Contains(packableTypes, f.ProtoType) {
+			err = errors.Join(err, fmt.Errorf("Only repeated fields with numeric, bool or enum types can use the packed encoding."))
+		}
+	}
+
+	if f.Features.Utf8Validation != "" {
+		isString := f.ProtoType == "string" || (f.IsMap && strings.Contains(f.ProtoType, "string"))
+		if !isString {
+			err = errors.Join(err, fmt.Errorf("Only string fields can specify the utf8 validation."))
+		}
+	}
+
+	return err
+}
+
+// Returns a copy of the extensions without the optional keyword, which is not allowed in editions (extension fields always have explicit presence).
+func (e Extensions) withoutOptional() Extensions {
+	stripOptional := func(fields []ExtensionField) []ExtensionField {
+		out := slices.Clone(fields)
+		for i := range out {
+			out[i].Optional = false
+		}
+		return out
+	}
+
+	return Extensions{
+		Service:   stripOptional(e.Service),
+		Message:   stripOptional(e.Message),
+		Field:     stripOptional(e.Field),
+		File:      stripOptional(e.File),
+		OneOf:     stripOptional(e.OneOf),
+		Enum:      stripOptional(e.Enum),
+		EnumValue: stripOptional(e.EnumValue),
+	}
+}
@@ -0,0 +1,190 @@
+package protoschema_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"github.com/bufbuild/protocompile"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestEditions(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:               "editions.v1",
+		GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/editionsv1",
+		ProtoRoot:          tmpDir,
+		ConverterOutputDir: filepath.Join(tmpDir, "converter"),
+	})
+
+	file := pkg.NewFile(sb.FileSchema{
+		Name:     "editions",
+		Edition:  sb.Edition2023,
+		Features: sb.Features{Utf8Validation: sb.Utf8ValidationNone, GoAPILevel: sb.GoAPILevelOpaque},
+	})
+
+	status := file.NewEnum(sb.EnumGroup{
+		Name:     "Status",
+		Members:  sb.EnumMembers{1: "STATUS_ACTIVE"},
+		Features: sb.Features{EnumType: sb.EnumTypeClosed},
+	})
+
+	file.NewMessage(sb.MessageSchema{
+		Name: "Item",
+		Fields: sb.FieldsMap{
+			1: sb.Int64("id").RequiredLabel(),
+			2: sb.String("name").Features(sb.Features{FieldPresence: sb.FieldPresenceImplicit}),
+			3: sb.Repeated("scores", sb.Int32("")).Features(sb.Features{RepeatedFieldEncoding: sb.RepeatedFieldEncodingExpanded}),
+			4: sb.EnumField("status", status).Default("STATUS_ACTIVE"),
+		},
+		ReservedNames: []string{"old_name"},
+	})
+
+	assert.NoError(t, pkg.Generate())
+
+	// Compiling the file checks the features against the edition and the elements where they are used
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: []string{tmpDir}}),
+	}
+
+	results, err := compiler.Compile(context.Background(), "editions/v1/editions.proto")
+	if assert.NoError(t, err) {
+		item := results[0].Messages().ByName("Item")
+		assert.Equal(t, protoreflect.Required, item.Fields().ByName("id").Cardinality())
+		assert.False(t, item.Fields().ByName("name").HasPresence())
+		assert.False(t, item.Fields().ByName("scores").IsPacked())
+		assert.True(t, item.Fields().ByName("status").Enum().IsClosed())
+	}
+
+	generated, err := os.ReadFile(filepath.Join(tmpDir, "editions/v1/editions.proto"))
+	assert.NoError(t, err)
+
+	for _, expected := range []string{
+		`edition = "2023";`,
+		`import "google/protobuf/go_features.proto";`,
+		`option features.utf8_validation = NONE;`,
+		`option features.(pb.go).api_level = API_OPAQUE;`,
+		`option features.enum_type = CLOSED;`,
+		`features.field_presence = LEGACY_REQUIRED`,
+		`features.field_presence = IMPLICIT`,
+		`features.repeated_field_encoding = EXPANDED`,
+		`reserved old_name;`,
+	} {
+		assert.Contains(t, string(generated), expected)
+	}
+
+	assert.NotContains(t, string(generated), "optional ")
+	assert.NotContains(t, string(generated), "required ")
+}
+
+func TestEditionsMigration(t *testing.T) {
+	newFile := func(schema sb.FileSchema) *sb.FileSchema {
+		return sb.NewProtoPackage(sb.ProtoPackageConfig{
+			Name:      "editions.v1",
+			GoPackage: "github.com/Rick-Phoenix/protoschema/gen/editionsv1",
+			ProtoRoot: t.TempDir(),
+		}).NewFile(schema)
+	}
+
+	proto3File := newFile(sb.FileSchema{Name: "migrated", Syntax: sb.SyntaxProto3, Edition: sb.Edition2023})
+	proto3File.NewMessage(sb.MessageSchema{
+		Name:   "Item",
+		Fields: sb.FieldsMap{1: sb.String("name"), 2: sb.String("description").Optional()},
+	})
+
+	files, err := proto3File.Package.TryBuildFiles()
+	if assert.NoError(t, err) {
+		assert.Equal(t, sb.SyntaxEditions, files[0].Syntax)
+		assert.Contains(t, files[0].Options, sb.ProtoOption{Name: "features.field_presence", Value: sb.FieldPresenceImplicit})
+		fields := files[0].Messages[0].Fields
+		assert.Empty(t, fields[0].Label)
+		assert.Empty(t, fields[0].Options)
+		assert.Empty(t, fields[1].Label)
+		assert.Equal(t, []string{"features.field_presence = EXPLICIT"}, fields[1].Options)
+	}
+
+	proto2File := newFile(sb.FileSchema{Name: "migrated", Syntax: sb.SyntaxProto2, Edition: sb.Edition2023})
+	proto2File.NewEnum(sb.EnumGroup{Name: "Kind", Members: sb.EnumMembers{1: "KIND_A"}})
+
+	files, err = proto2File.Package.TryBuildFiles()
+	if assert.NoError(t, err, "Closed enums can start with a non-zero value") {
+		for _, name := range []string{"features.enum_type", "features.repeated_field_encoding", "features.utf8_validation"} {
+			assert.Contains(t, files[0].Options, sb.ProtoOption{Name: name, Value: map[string]any{
+				"features.enum_type":               sb.EnumTypeClosed,
+				"features.repeated_field_encoding": sb.RepeatedFieldEncodingExpanded,
+				"features.utf8_validation":         sb.Utf8ValidationNone,
+			}[name]})
+		}
+	}
+
+	shouldFail := map[string]func() *sb.FileSchema{
+		"unsupported edition": func() *sb.FileSchema {
+			return newFile(sb.FileSchema{Name: "invalid", Edition: "2020"})
+		},
+		"features without editions": func() *sb.FileSchema {
+			return newFile(sb.FileSchema{Name: "invalid", Features: sb.Features{FieldPresence: sb.FieldPresenceImplicit}})
+		},
+		"field features without editions": func() *sb.FileSchema {
+			f := newFile(sb.FileSchema{Name: "invalid"})
+			f.NewMessage(sb.MessageSchema{Name: "Item", Fields: sb.FieldsMap{1: sb.String("name").Features(sb.Features{FieldPresence: sb.FieldPresenceExplicit})}})
+			return f
+		},
+		"invalid feature value": func() *sb.FileSchema {
+			return newFile(sb.FileSchema{Name: "invalid", Edition: sb.Edition2023, Features: sb.Features{EnumType: "HALF_OPEN"}})
+		},
+		"legacy required file default": func() *sb.FileSchema {
+			return newFile(sb.FileSchema{Name: "invalid", Edition: sb.Edition2023, Features: sb.Features{FieldPresence: sb.FieldPresenceLegacyRequired}})
+		},
+		"feature on the wrong element": func() *sb.FileSchema {
+			f := newFile(sb.FileSchema{Name: "invalid", Edition: sb.Edition2023})
+			f.NewMessage(sb.MessageSchema{Name: "Item", Fields: sb.FieldsMap{1: sb.String("name")}, Features: sb.Features{FieldPresence: sb.FieldPresenceImplicit}})
+			return f
+		},
+		"implicit message field": func() *sb.FileSchema {
+			f := newFile(sb.FileSchema{Name: "invalid", Edition: sb.Edition2023})
+			f.NewMessage(sb.MessageSchema{Name: "Item", Fields: sb.FieldsMap{1: sb.Timestamp("created_at").Features(sb.Features{FieldPresence: sb.FieldPresenceImplicit})}})
+			return f
+		},
+		"optional with implicit presence": func() *sb.FileSchema {
+			f := newFile(sb.FileSchema{Name: "invalid", Edition: sb.Edition2023})
+			f.NewMessage(sb.MessageSchema{Name: "Item", Fields: sb.FieldsMap{1: sb.String("name").Optional().Features(sb.Features{FieldPresence: sb.FieldPresenceImplicit})}})
+			return f
+		},
+		"default with implicit presence": func() *sb.FileSchema {
+			f := newFile(sb.FileSchema{Name: "invalid", Edition: sb.Edition2023, Features: sb.Features{FieldPresence: sb.FieldPresenceImplicit}})
+			f.NewMessage(sb.MessageSchema{Name: "Item", Fields: sb.FieldsMap{1: sb.String("name").Default("x")}})
+			return f
+		},
+		"closed enum with implicit presence": func() *sb.FileSchema {
+			f := newFile(sb.FileSchema{Name: "invalid", Edition: sb.Edition2023, Features: sb.Features{FieldPresence: sb.FieldPresenceImplicit}})
+			kind := f.NewEnum(sb.EnumGroup{Name: "Kind", Members: sb.EnumMembers{0: "KIND_UNSPECIFIED"}, Features: sb.Features{EnumType: sb.EnumTypeClosed}})
+			f.NewMessage(sb.MessageSchema{Name: "Item", Fields: sb.FieldsMap{1: sb.EnumField("kind", kind)}})
+			return f
+		},
+		"open enum without a zero value": func() *sb.FileSchema {
+			f := newFile(sb.FileSchema{Name: "invalid", Edition: sb.Edition2023})
+			f.NewEnum(sb.EnumGroup{Name: "Kind", Members: sb.EnumMembers{1: "KIND_A"}})
+			return f
+		},
+		"packed encoding for strings": func() *sb.FileSchema {
+			f := newFile(sb.FileSchema{Name: "invalid", Edition: sb.Edition2023})
+			f.NewMessage(sb.MessageSchema{Name: "Item", Fields: sb.FieldsMap{1: sb.Repeated("tags", sb.String("")).Features(sb.Features{RepeatedFieldEncoding: sb.RepeatedFieldEncodingPacked})}})
+			return f
+		},
+		"utf8 validation for a number": func() *sb.FileSchema {
+			f := newFile(sb.FileSchema{Name: "invalid", Edition: sb.Edition2023})
+			f.NewMessage(sb.MessageSchema{Name: "Item", Fields: sb.FieldsMap{1: sb.Int32("count").Features(sb.Features{Utf8Validation: sb.Utf8ValidationNone})}})
+			return f
+		},
+	}
+
+	for name, build := range shouldFail {
+		_, err := build().Package.TryBuildFiles()
+		assert.Error(t, err, name)
+	}
+}
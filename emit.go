@@ -0,0 +1,103 @@
+package protoschema
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Writes the go source code that recreates the schemas of this package with the builders of this library, such as NewFile, NewMessage (with a FieldsMap and the chained rule methods), NestedMessage, NewEnum, NewOneof and NewService.
+// The code declares the package in a variable named pkg (in a go package named schemas), followed by a variable for each file, message and enum (such as itemFile, itemMsg or statusEnum), so both can be renamed as needed.
+// The schemas are recreated from the compiled proto files, so the output is the same for schemas written in go, loaded from documents or imported from proto files. This makes it useful for codemods, for reviewing snapshots of the schemas and for scaffolding maintainable go schemas.
+// Since the source is derived from the proto output, the elements that do not appear in it are not included: mixins and the fields of a FieldsList are added to the FieldsMap with their numbers, and models, hooks and converter functions are omitted.
+func (p *ProtoPackage) EmitGo(w io.Writer) error {
+	filesData, err := p.TryBuildFiles()
+	if err != nil {
+		return err
+	}
+
+	return p.EmitGoFromFiles(w, filesData)
+}
+
+// Writes the go source of EmitGo for the data returned by TryBuildFiles, so that the schemas are not processed again.
+func (p *ProtoPackage) EmitGoFromFiles(w io.Writer, filesData []FileData) error {
+	files, err := p.compileFiles(filesData)
+	if err != nil {
+		return err
+	}
+
+	// The schemas are created in a separate package, so that this one is not modified
+	scratch := &ProtoPackage{
+		Name:            p.Name,
+		BasePath:        p.BasePath,
+		GoPackagePath:   p.GoPackagePath,
+		GoPackageName:   p.GoPackageName,
+		languageOptions: p.languageOptions,
+	}
+
+	schemas, err := scratch.importFiles(files)
+	if err != nil {
+		return err
+	}
+
+	source, err := schemas.source.format(fmt.Sprintf("The schemas of the %s package, emitted by protoschema.", p.Name), "schemas", "pkg", p.configLiteral(schemas.source.imports))
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(source)
+	return err
+}
+
+// Returns the go expression that creates a package with the same configuration as this one, adding the imports that it needs. Only the values that can be written as literals are included.
+func (p *ProtoPackage) configLiteral(imports Set) string {
+	conf := structLiteral{typeName: "sb.ProtoPackageConfig"}
+	conf.addString("Name", p.Name)
+	conf.addString("GoPackage", p.GoPackagePath)
+	conf.addString("ProtoRoot", p.protoRoot)
+	conf.addString("GoModule", p.goModule)
+
+	if p.converterOutputDir != "gen/converter" {
+		conf.addString("ConverterOutputDir", p.converterOutputDir)
+	}
+
+	if p.conformanceTests {
+		conf.add("ConformanceTests", "true")
+	}
+
+	opts := p.languageOptions
+	langLit := structLiteral{typeName: "sb.LanguageOptions"}
+	langLit.addString("GoPackage", opts.GoPackage)
+
+	if opts.DisableGoPackage != nil {
+		imports["google.golang.org/protobuf/proto"] = present
+		langLit.add("DisableGoPackage", fmt.Sprintf("proto.Bool(%t)", *opts.DisableGoPackage))
+	}
+
+	if len(opts.Languages) > 0 {
+		var languages []string
+		for _, l := range opts.Languages {
+			languages = append(languages, "sb.Language"+strings.ToUpper(string(l)[:1])+string(l)[1:])
+		}
+		langLit.add("Languages", "[]sb.Language{"+strings.Join(languages, ", ")+"}")
+	}
+
+	langLit.addString("JavaPackage", opts.JavaPackage)
+
+	if opts.JavaMultipleFiles != nil {
+		imports["google.golang.org/protobuf/proto"] = present
+		langLit.add("JavaMultipleFiles", fmt.Sprintf("proto.Bool(%t)", *opts.JavaMultipleFiles))
+	}
+
+	langLit.addString("CsharpNamespace", opts.CsharpNamespace)
+	langLit.addString("ObjcClassPrefix", opts.ObjcClassPrefix)
+	langLit.addString("PhpNamespace", opts.PhpNamespace)
+	langLit.addString("RubyPackage", opts.RubyPackage)
+	langLit.addString("SwiftPrefix", opts.SwiftPrefix)
+
+	if len(langLit.fields) > 0 {
+		conf.add("LanguageOptions", langLit.String())
+	}
+
+	return "sb.NewProtoPackage(" + conf.String() + ")"
+}
@@ -0,0 +1,94 @@
+package protoschema_test
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmitGo(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:               "emit.v1",
+		GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/emitv1",
+		ProtoRoot:          tmpDir,
+		ConverterOutputDir: filepath.Join(tmpDir, "converter"),
+		LanguageOptions:    sb.LanguageOptions{Languages: []sb.Language{sb.LanguageJava}},
+	})
+
+	file := pkg.NewFile(sb.FileSchema{Name: "item", Doc: "The items."})
+
+	status := file.NewEnum(sb.EnumGroup{
+		Name:   "Status",
+		Values: []sb.EnumValue{{Name: "STATUS_UNSPECIFIED", Number: 0}, {Name: "STATUS_ACTIVE", Number: 1}},
+	})
+
+	item := file.NewMessage(sb.MessageSchema{Name: "Item", Doc: "An item."})
+	location := item.NestedMessage(sb.MessageSchema{
+		Name:   "Location",
+		Fields: sb.FieldsMap{1: sb.String("shelf").In("A", "B")},
+	})
+
+	item.Fields = sb.FieldsMap{
+		1: sb.Int64("id").Gt(0),
+		2: sb.String("email").Email().Doc("The contact email."),
+		3: sb.EnumField("status", status).DefinedOnly(),
+		4: sb.Repeated("tags", sb.String("").MaxLen(10)).Unique(),
+		5: sb.MsgField("location", location),
+	}
+	item.FieldsList = sb.FieldsList{sb.Bool("available")}
+
+	item.NewOneof(sb.OneofGroup{
+		Name:     "discount",
+		Required: true,
+		Fields:   sb.OneofFields{10: sb.UInt32("percentage").Lte(100), 11: sb.Double("amount")},
+	})
+
+	getRequest := file.NewMessage(sb.MessageSchema{Name: "GetItemRequest", Fields: sb.FieldsMap{1: sb.Int64("id")}})
+
+	file.NewService(sb.ServiceSchema{
+		Resource: "Item",
+		Handlers: sb.HandlersMap{"GetItem": {Request: getRequest, Response: item}},
+	})
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, pkg.EmitGo(&buf)) {
+		return
+	}
+
+	source := buf.String()
+
+	_, err := parser.ParseFile(token.NewFileSet(), "emit.go", source, 0)
+	assert.NoError(t, err)
+
+	expected := []string{
+		"package schemas",
+		`sb.NewProtoPackage(sb.ProtoPackageConfig{Name: "emit.v1", GoPackage: "github.com/Rick-Phoenix/protoschema/gen/emitv1", ProtoRoot: "` + tmpDir + `", ConverterOutputDir: "` + filepath.Join(tmpDir, "converter") + `", LanguageOptions: sb.LanguageOptions{Languages: []sb.Language{sb.LanguageJava}}})`,
+		`pkg.NewFile(sb.FileSchema{Name: "item", Syntax: "proto3", Doc: "The items."})`,
+		`itemFile.NewEnum(sb.EnumGroup{Name: "Status", Values: []sb.EnumValue{{Name: "STATUS_UNSPECIFIED", Number: 0}, {Name: "STATUS_ACTIVE", Number: 1}}})`,
+		`itemFile.NewMessage(sb.MessageSchema{Name: "Item", Doc: "An item."})`,
+		`itemMsg.NestedMessage(sb.MessageSchema{Name: "Location"})`,
+		`1: sb.Int64("id").Gt(0)`,
+		`2: sb.String("email").Email().Doc("The contact email.")`,
+		`3: sb.EnumField("status", statusEnum).DefinedOnly()`,
+		`4: sb.Repeated("tags", sb.String("").MaxLen(10)).Unique()`,
+		`5: sb.MsgField("location", itemLocationMsg)`,
+		`12: sb.Bool("available")`,
+		`itemLocationMsg.Fields = sb.FieldsMap{1: sb.String("shelf").In("A", "B")}`,
+		`itemMsg.NewOneof(sb.OneofGroup{Name: "discount", Required: true, Fields: sb.OneofFields{10: sb.UInt32("percentage").Lte(100), 11: sb.Double("amount")}})`,
+		`itemFile.NewService(sb.ServiceSchema{Resource: "Item", Handlers: sb.HandlersMap{"GetItem": {Request: getItemRequestMsg, Response: itemMsg}}})`,
+	}
+
+	for _, exp := range expected {
+		assert.Contains(t, source, exp)
+	}
+
+	// The options derived from the package are not repeated in the files
+	assert.NotContains(t, source, "JavaPackage")
+}
@@ -2,7 +2,10 @@ package protoschema
 
 import (
 	"errors"
+	"fmt"
 	"maps"
+	"math"
+	"slices"
 
 	"github.com/labstack/gommon/log"
 )
@@ -10,17 +13,40 @@ import (
 // The members of an enum group.
 type EnumMembers map[int32]string
 
+// A single value of an enum. Unlike EnumMembers, a list of values preserves the order of declaration, and it can contain aliases (values with the same number), which are only allowed if the enum has the allow_alias option.
+type EnumValue struct {
+	Name   string
+	Number int32
+	// Custom options for this value, such as deprecated, debug_redact or custom enum value extensions.
+	Options []ProtoOption
+	// A comment that is rendered above this value in the proto file.
+	Doc string
+	// A comment that is rendered on the same line as this value. Line breaks are replaced with spaces.
+	TrailingComment string
+}
+
 // The schema for a protobuf Enum.This should be created with the constructor from the FileSchema or MessageSchema instances to automatically populate the Package, File and Message fields. It can also be used as a struct to define an Enum that was not defined by using this library.
 type EnumGroup struct {
 	// The enum's name. If this enum was defined in a message, the GetName method will automatically prepend the parent message's name.
 	Name string
 	// The members of this enum group.
-	Members         EnumMembers
+	Members EnumMembers
+	// An ordered list of member names that can be used instead of (or together with) the Members map. Their numbers are assigned automatically (starting from zero) and recorded in the package's lockfile, and the members that are removed from this list will have their names and numbers reserved automatically.
+	MembersList []string
+	// An ordered list of values that can be used instead of (or together with) the Members map. Values in this list can have their own options and comments, and they can be aliases of other values if the allow_alias option is set.
+	// When the enum is processed, this list contains all of its values, starting with those from the Members map (sorted by number), followed by those in the MembersList and then by those defined here.
+	Values          []EnumValue
 	ReservedNames   []string
 	ReservedNumbers []int32
 	ReservedRanges  []Range
 	// Custom options for this enum. A preset for the allow_alias option is available in this package under Options.AllowAlias.
 	Options []ProtoOption
+	// The features for this enum (editions only). Only the enum_type can be set on enums.
+	Features Features
+	// A comment that is rendered above this enum in the proto file, and that is used as its leading comment in the descriptor's SourceCodeInfo.
+	Doc string
+	// A comment that is rendered on the same line as the enum's opening brace. Line breaks are replaced with spaces.
+	TrailingComment string
 	// The package that this enum belongs to. Automatically set when using the constructors.
 	Package *ProtoPackage
 	// The file that this enum belongs to. Automatically set when using the constructors.
@@ -61,7 +87,7 @@ func (e *EnumGroup) GetFullName(p *ProtoPackage) string {
 		return e.Message.GetFullName(p) + "." + e.Name
 	}
 
-	return e.Package.GetName() + "." + e.GetName()
+	return referenceName(p, e.Package, e.GetName())
 }
 
 // Returns the import path of the file that this enum belongs to (if one is defined).
@@ -77,6 +103,139 @@ func (e *EnumGroup) GetImportPath() string {
 	return e.ImportPath
 }
 
+// Processes the enum's schema and returns a copy of it where the members inside the MembersList have been assigned their numbers, and where the Values list contains all of the enum's values.
+// The Members map of the copy maps each number to the first value that uses it.
+func (e *EnumGroup) build() (EnumGroup, error) {
+	out := *e
+
+	values, reservedNumbers, reservedNames, err := e.collectValues()
+
+	out.Values = values
+	out.Members = make(EnumMembers)
+
+	for _, v := range out.Values {
+		if _, exists := out.Members[v.Number]; !exists {
+			out.Members[v.Number] = v.Name
+		}
+	}
+
+	out.ReservedNumbers = slices.Concat(e.ReservedNumbers, reservedNumbers)
+	out.ReservedNames = slices.Concat(e.ReservedNames, reservedNames)
+	out.Options = slices.Concat(e.Options, e.Features.options())
+
+	err = errors.Join(err, checkEnumNumbers(out))
+	err = errors.Join(err, checkEnumSyntax(out))
+
+	return out, err
+}
+
+// Returns all the values of the enum, starting with those in the Members map (sorted by number), followed by those in the MembersList (with their assigned numbers) and then by those in the Values list.
+// It also returns the numbers and names of the members that were removed from the MembersList, which must be reserved.
+func (e *EnumGroup) collectValues() ([]EnumValue, []int32, []string, error) {
+	numbers, reservedNumbers, reservedNames, err := e.assignMemberNumbers()
+
+	var values []EnumValue
+
+	for _, nr := range slices.Sorted(maps.Keys(e.Members)) {
+		values = append(values, EnumValue{Name: e.Members[nr], Number: nr})
+	}
+
+	for _, name := range e.MembersList {
+		if nr, ok := numbers[name]; ok {
+			values = append(values, EnumValue{Name: name, Number: nr})
+		}
+	}
+
+	values = append(values, e.Values...)
+
+	return values, reservedNumbers, reservedNames, err
+}
+
+// Resolves a value to its number. The value can be the name of one of the enum's values, an EnumValue or an integer. Reserved numbers cause an error.
+func (e *EnumGroup) resolveValue(value any) (int32, error) {
+	var nr int64
+
+	switch v := value.(type) {
+	case string:
+		values, _, _, _ := e.collectValues()
+
+		idx := slices.IndexFunc(values, func(ev EnumValue) bool { return ev.Name == v })
+		if idx == -1 {
+			return 0, fmt.Errorf("Enum %q has no value named %q.", e.GetName(), v)
+		}
+
+		return values[idx].Number, nil
+	case EnumValue:
+		nr = int64(v.Number)
+	case int:
+		nr = int64(v)
+	case int8:
+		nr = int64(v)
+	case int16:
+		nr = int64(v)
+	case int32:
+		nr = int64(v)
+	case int64:
+		nr = v
+	case uint:
+		nr = int64(v)
+	case uint8:
+		nr = int64(v)
+	case uint16:
+		nr = int64(v)
+	case uint32:
+		nr = int64(v)
+	default:
+		return 0, fmt.Errorf("Invalid value %v (%T) for enum %q. Enum values must be names or numbers.", value, value, e.GetName())
+	}
+
+	if nr < math.MinInt32 || nr > math.MaxInt32 {
+		return 0, fmt.Errorf("Value %d is out of range for enum %q.", nr, e.GetName())
+	}
+
+	if slices.Contains(e.ReservedNumbers, int32(nr)) {
+		return 0, fmt.Errorf("Value %d is reserved in enum %q.", nr, e.GetName())
+	}
+
+	if r, inRange := findRange(nr, e.ReservedRanges); inRange {
+		return 0, fmt.Errorf("Value %d is inside the reserved range from %d to %d in enum %q.", nr, r[0], r[1], e.GetName())
+	}
+
+	return int32(nr), nil
+}
+
+// Resolves a value (a name or a number) to the name of one of the enum's values.
+func (e *EnumGroup) resolveName(value any) (string, error) {
+	nr, err := e.resolveValue(value)
+	if err != nil {
+		return "", err
+	}
+
+	if name, isName := value.(string); isName {
+		return name, nil
+	}
+
+	values, _, _, _ := e.collectValues()
+
+	idx := slices.IndexFunc(values, func(ev EnumValue) bool { return ev.Number == nr })
+	if idx == -1 {
+		return "", fmt.Errorf("Enum %q has no value with the number %d.", e.GetName(), nr)
+	}
+
+	return values[idx].Name, nil
+}
+
+// Returns true if the enum has the allow_alias option.
+func (e *EnumGroup) allowsAlias() bool {
+	for _, o := range e.Options {
+		if o.Name == Options.AllowAlias.Name && o.Value == true {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Returns true if the argument package is the same as this enum's.
 func (e *EnumGroup) IsInternal(p *ProtoPackage) bool {
 	if e == nil || p == nil {
@@ -89,8 +248,11 @@ func (e *EnumGroup) IsInternal(p *ProtoPackage) bool {
 // A message field with an enum type.
 type ProtoEnumField struct {
 	*ProtoField[ProtoEnumField]
-	*ConstField[ProtoEnumField, int32, int32]
 	*OptionalField[ProtoEnumField]
+	constVal any
+	in       []any
+	notIn    []any
+	examples []any
 }
 
 // The constructor for an enum field.
@@ -117,40 +279,87 @@ func EnumField(name string, enum *EnumGroup) *ProtoEnumField {
 	ef.ProtoField = &ProtoField[ProtoEnumField]{
 		protoFieldInternal: internal, self: ef,
 	}
-	ef.ConstField = &ConstField[ProtoEnumField, int32, int32]{constInternal: internal, self: ef}
 	ef.OptionalField = &OptionalField[ProtoEnumField]{optionalInternal: internal, self: ef}
 
 	return ef
 }
 
 // The method that processes the field's schema and returns its data. Used to satisfy the FieldBuilder interface. Mostly for internal use.
+// The values used in the rules are resolved against the field's enum at this stage, so that the names of the members with automatically assigned numbers can also be used.
 func (ef *ProtoEnumField) Build(fieldNr uint32, imports Set) (FieldData, error) {
-	data := FieldData{Name: ef.name, ProtoType: ef.protoType, GoType: ef.goType, FieldNr: fieldNr, Rules: ef.rules, Optional: ef.optional, ProtoBaseType: "enum"}
+	data := FieldData{Name: ef.name, ProtoType: ef.protoType, GoType: ef.goType, FieldNr: fieldNr, Optional: ef.optional, ProtoBaseType: "enum", EnumRef: ef.enumRef, LabelRequired: ef.labelRequired, Features: ef.features, ExtensionOptions: extensionOptions(ef.options), Doc: ef.doc, TrailingComment: ef.trailingComment}
 
 	var errAgg error
 	errAgg = errors.Join(errAgg, ef.errors)
+	errAgg = errors.Join(errAgg, checkFieldFeatureTypes(data))
 
-	options := make([]string, len(ef.repeatedOptions))
-	copy(options, ef.repeatedOptions)
+	options, celErr := ef.buildRepeatedOptions()
+	errAgg = errors.Join(errAgg, celErr)
 
 	optsCollector := make(map[string]any)
 	maps.Copy(optsCollector, ef.options)
 
-	if len(ef.rules) > 0 {
-		imports["buf/validate/validate.proto"] = present
-		enumRules := make(map[string]any)
+	rules := maps.Clone(ef.rules)
+
+	resolveAll := func(vals []any) []int32 {
+		out := make([]int32, 0, len(vals))
 
-		if list, exists := ef.rules["in"]; exists {
-			enumRules["in"] = list
+		for _, v := range vals {
+			nr, err := ef.enumRef.resolveValue(v)
+			if err != nil {
+				errAgg = errors.Join(errAgg, err)
+				continue
+			}
+			out = append(out, nr)
 		}
 
-		if list, exists := ef.rules["not_in"]; exists {
-			enumRules["not_in"] = list
+		return out
+	}
+
+	if ef.isConst {
+		nr, err := ef.enumRef.resolveValue(ef.constVal)
+		errAgg = errors.Join(errAgg, err)
+		rules["const"] = nr
+
+		if len(ef.rules) > 0 || len(ef.in) > 0 || len(ef.notIn) > 0 {
+			errAgg = errors.Join(errAgg, fmt.Errorf("A constant field cannot have extra rules."))
+		}
+
+		if ef.optional {
+			errAgg = errors.Join(errAgg, fmt.Errorf("A constant field cannot be optional."))
 		}
+	}
+
+	if len(ef.in) > 0 {
+		rules["in"] = resolveAll(ef.in)
+	}
+
+	if len(ef.notIn) > 0 {
+		rules["not_in"] = resolveAll(ef.notIn)
+	}
 
-		optsCollector["(buf.validate.field).enum"] = enumRules
+	if in, notIn := rules["in"], rules["not_in"]; in != nil && notIn != nil && sliceIntersects(in.([]int32), notIn.([]int32)) {
+		errAgg = errors.Join(errAgg, fmt.Errorf("A field cannot be inside of 'in' and 'not_in' at the same time."))
 	}
 
+	if len(ef.examples) > 0 {
+		rules["example"] = resolveAll(ef.examples)
+	}
+
+	if ef.defaultValue != nil {
+		name, err := ef.enumRef.resolveName(ef.defaultValue)
+		errAgg = errors.Join(errAgg, err)
+		data.Default = name
+		options = append(options, "default = "+name)
+	}
+
+	if len(rules) > 0 {
+		imports["buf/validate/validate.proto"] = present
+		optsCollector["(buf.validate.field).enum"] = rules
+	}
+
+	data.Rules = rules
+
 	options, err := getOptions(optsCollector, options)
 	errAgg = errors.Join(errAgg, err)
 
@@ -163,6 +372,31 @@ func (ef *ProtoEnumField) Build(fieldNr uint32, imports Set) (FieldData, error)
 	return data, nil
 }
 
+// Rule: this field can only be this specific value. The value can be the name of one of the enum's values or a number. This will cause an error if it is used with other rules.
+func (ef *ProtoEnumField) Const(val any) *ProtoEnumField {
+	ef.constVal = val
+	ef.isConst = true
+	return ef
+}
+
+// Rule: the field's value must be among those listed in order to be accepted. The values can be the names of the enum's values or numbers.
+func (ef *ProtoEnumField) In(vals ...any) *ProtoEnumField {
+	ef.in = vals
+	return ef
+}
+
+// Rule: the field's value must not be present among those listed in order to be accepted. The values can be the names of the enum's values or numbers.
+func (ef *ProtoEnumField) NotIn(vals ...any) *ProtoEnumField {
+	ef.notIn = vals
+	return ef
+}
+
+// An example value for this field. It can be the name of one of the enum's values or a number. More than one example can be provided by calling this method multiple times.
+func (ef *ProtoEnumField) Example(val any) *ProtoEnumField {
+	ef.examples = append(ef.examples, val)
+	return ef
+}
+
 // Rule: this field must contain one of the defined values for its enum type.
 func (ef *ProtoEnumField) DefinedOnly() *ProtoEnumField {
 	ef.rules["defined_only"] = true
@@ -0,0 +1,153 @@
+package protoschema_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"github.com/bufbuild/protocompile/parser"
+	"github.com/bufbuild/protocompile/reporter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnumValues(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:               "enums.v1",
+		GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/enumsv1",
+		ProtoRoot:          tmpDir,
+		ConverterOutputDir: filepath.Join(tmpDir, "converter"),
+	})
+
+	file := pkg.NewFile(sb.FileSchema{
+		Name: "enums",
+		Extensions: sb.Extensions{
+			EnumValue: []sb.ExtensionField{{Name: "display_name", Type: "string", FieldNr: 50000}},
+		},
+	})
+
+	file.NewEnum(sb.EnumGroup{
+		Name:    "Status",
+		Options: []sb.ProtoOption{sb.Options.AllowAlias},
+		Values: []sb.EnumValue{
+			{Name: "STATUS_UNSPECIFIED", Number: 0},
+			{Name: "STATUS_ACTIVE", Number: 1, Doc: "The item is active.", Options: []sb.ProtoOption{{Name: "(display_name)", Value: "Active"}}},
+			{Name: "STATUS_ENABLED", Number: 1},
+			{Name: "STATUS_LEGACY", Number: 2, Options: []sb.ProtoOption{sb.Options.ProtoDeprecated}},
+		},
+	})
+
+	msg := file.NewMessage(sb.MessageSchema{Name: "Item", Fields: sb.FieldsMap{1: sb.Int64("id")}})
+	msg.NewEnum(sb.EnumGroup{
+		Name:   "Kind",
+		Values: []sb.EnumValue{{Name: "KIND_UNSPECIFIED", Number: 0}, {Name: "KIND_PHYSICAL", Number: 2}, {Name: "KIND_DIGITAL", Number: 1}},
+	})
+
+	assert.NoError(t, pkg.Generate())
+
+	outputPath := filepath.Join(tmpDir, "enums/v1/enums.proto")
+	content, err := os.Open(outputPath)
+	assert.NoError(t, err)
+	defer content.Close()
+
+	handler := reporter.NewHandler(nil)
+	ast, err := parser.Parse(outputPath, content, handler)
+	assert.NoError(t, err)
+	result, err := parser.ResultFromAST(ast, false, handler)
+	assert.NoError(t, err)
+
+	desc := result.FileDescriptorProto()
+
+	status := desc.GetEnumType()[0]
+	var names []string
+	var numbers []int32
+	for _, v := range status.GetValue() {
+		names = append(names, v.GetName())
+		numbers = append(numbers, v.GetNumber())
+	}
+	assert.Equal(t, []string{"STATUS_UNSPECIFIED", "STATUS_ACTIVE", "STATUS_ENABLED", "STATUS_LEGACY"}, names)
+	assert.Equal(t, []int32{0, 1, 1, 2}, numbers)
+	assert.Len(t, status.GetValue()[1].GetOptions().GetUninterpretedOption(), 1)
+	assert.Len(t, status.GetValue()[3].GetOptions().GetUninterpretedOption(), 1)
+
+	names = nil
+	for _, v := range desc.GetMessageType()[0].GetEnumType()[0].GetValue() {
+		names = append(names, v.GetName())
+	}
+	assert.Equal(t, []string{"KIND_UNSPECIFIED", "KIND_PHYSICAL", "KIND_DIGITAL"}, names, "Values should keep their declaration order")
+
+	assert.Equal(t, "google.protobuf.EnumValueOptions", desc.GetExtension()[0].GetExtendee())
+
+	generated, err := os.ReadFile(outputPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(generated), "// The item is active.")
+
+	aliasFile := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:      "enums.v1",
+		GoPackage: "github.com/Rick-Phoenix/protoschema/gen/enumsv1",
+		ProtoRoot: t.TempDir(),
+	}).NewFile(sb.FileSchema{Name: "alias"})
+
+	aliasFile.NewEnum(sb.EnumGroup{
+		Name:   "Color",
+		Values: []sb.EnumValue{{Name: "COLOR_UNSPECIFIED", Number: 0}, {Name: "COLOR_RED", Number: 1}, {Name: "COLOR_CRIMSON", Number: 1}},
+	})
+
+	_, err = aliasFile.Package.TryBuildFiles()
+	if assert.Error(t, err, "Aliases should not be allowed without the allow_alias option") {
+		assert.Contains(t, err.Error(), "allow_alias")
+	}
+
+	unusedAliasFile := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:      "enums.v1",
+		GoPackage: "github.com/Rick-Phoenix/protoschema/gen/enumsv1",
+		ProtoRoot: t.TempDir(),
+	}).NewFile(sb.FileSchema{Name: "alias"})
+
+	unusedAliasFile.NewEnum(sb.EnumGroup{
+		Name:    "Color",
+		Options: []sb.ProtoOption{sb.Options.AllowAlias},
+		Members: sb.EnumMembers{0: "COLOR_UNSPECIFIED", 1: "COLOR_RED"},
+	})
+
+	_, err = unusedAliasFile.Package.TryBuildFiles()
+	if assert.Error(t, err, "The allow_alias option should not be set without aliases") {
+		assert.Contains(t, err.Error(), "no aliases")
+	}
+}
+
+func TestEnumFieldRules(t *testing.T) {
+	status := &sb.EnumGroup{
+		Name:            "Status",
+		Members:         sb.EnumMembers{0: "STATUS_UNSPECIFIED", 1: "STATUS_ACTIVE", 2: "STATUS_PENDING"},
+		ReservedNumbers: []int32{5},
+	}
+
+	data, err := sb.EnumField("status", status).In("STATUS_ACTIVE", 2).DefinedOnly().Example("STATUS_PENDING").Build(1, sb.Set{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"(buf.validate.field).enum = {defined_only: true, example: [2], in: [1, 2]}"}, data.Options)
+	assert.Equal(t, []int32{1, 2}, data.Rules["in"])
+
+	data, err = sb.EnumField("status", status).Const("STATUS_ACTIVE").Build(1, sb.Set{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"(buf.validate.field).enum = {const: 1}"}, data.Options)
+
+	data, err = sb.Repeated("statuses", sb.EnumField("", status).NotIn("STATUS_UNSPECIFIED")).Build(1, sb.Set{})
+	assert.NoError(t, err)
+	assert.Contains(t, data.Options, "(buf.validate.field).repeated.items = {enum: {not_in: [0]}}")
+
+	shouldFail := map[string]sb.FieldBuilder{
+		"unknown name":       sb.EnumField("status", status).In("STATUS_DELETED"),
+		"reserved number":    sb.EnumField("status", status).NotIn(5),
+		"const with rules":   sb.EnumField("status", status).Const(1).DefinedOnly(),
+		"in and not_in":      sb.EnumField("status", status).In("STATUS_ACTIVE").NotIn(1),
+		"invalid value type": sb.EnumField("status", status).Example(1.5),
+	}
+
+	for name, field := range shouldFail {
+		_, err := field.Build(1, sb.Set{})
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,308 @@
+package protoschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// The JSON schemas for the well-known types, which have a special representation in the JSON mapping of protobuf.
+var wellKnownJSONSchemas = map[protoreflect.FullName]map[string]any{
+	"google.protobuf.Timestamp":   {"type": "string", "format": "date-time"},
+	"google.protobuf.Duration":    {"type": "string", "pattern": `^-?[0-9]+(\.[0-9]+)?s$`},
+	"google.protobuf.FieldMask":   {"type": "string"},
+	"google.protobuf.Empty":       {"type": "object"},
+	"google.protobuf.Struct":      {"type": "object"},
+	"google.protobuf.Value":       {},
+	"google.protobuf.ListValue":   {"type": "array"},
+	"google.protobuf.Any":         {"type": "object", "properties": map[string]any{"@type": map[string]any{"type": "string"}}, "required": []string{"@type"}},
+	"google.protobuf.BoolValue":   {"type": "boolean"},
+	"google.protobuf.StringValue": {"type": "string"},
+	"google.protobuf.BytesValue":  {"type": "string", "contentEncoding": "base64"},
+	"google.protobuf.Int32Value":  {"type": "integer", "format": "int32"},
+	"google.protobuf.UInt32Value": {"type": "integer", "format": "uint32"},
+	"google.protobuf.Int64Value":  {"type": []string{"integer", "string"}, "format": "int64"},
+	"google.protobuf.UInt64Value": {"type": []string{"integer", "string"}, "format": "uint64"},
+	"google.protobuf.FloatValue":  {"type": "number", "format": "float"},
+	"google.protobuf.DoubleValue": {"type": "number", "format": "double"},
+}
+
+// Collects the JSON schemas for the messages and enums of a package (and for the ones they reference), following the JSON mapping of protobuf.
+type jsonSchemaBuilder struct {
+	refPrefix string
+	defs      map[string]any
+}
+
+// Returns the leading comment of an element, which is used as its description.
+func descriptorDoc(d protoreflect.Descriptor) string {
+	return strings.TrimSpace(d.ParentFile().SourceLocations().ByDescriptor(d).LeadingComments)
+}
+
+func (b *jsonSchemaBuilder) ref(name protoreflect.FullName) map[string]any {
+	return map[string]any{"$ref": b.refPrefix + string(name)}
+}
+
+func (b *jsonSchemaBuilder) addEnum(e protoreflect.EnumDescriptor) {
+	if _, exists := b.defs[string(e.FullName())]; exists {
+		return
+	}
+
+	var names []string
+	values := e.Values()
+	for i := range values.Len() {
+		names = append(names, string(values.Get(i).Name()))
+	}
+
+	schema := map[string]any{"type": "string", "enum": names}
+	if doc := descriptorDoc(e); doc != "" {
+		schema["description"] = doc
+	}
+
+	b.defs[string(e.FullName())] = schema
+}
+
+func (b *jsonSchemaBuilder) addMessage(m protoreflect.MessageDescriptor) {
+	if _, exists := b.defs[string(m.FullName())]; exists {
+		return
+	}
+
+	if m.IsMapEntry() {
+		return
+	}
+
+	if wkt, isWellKnown := wellKnownJSONSchemas[m.FullName()]; isWellKnown {
+		b.defs[string(m.FullName())] = wkt
+		return
+	}
+
+	properties := make(map[string]any)
+	schema := map[string]any{"type": "object", "properties": properties}
+	// Added before the fields to handle recursive messages
+	b.defs[string(m.FullName())] = schema
+
+	if doc := descriptorDoc(m); doc != "" {
+		schema["description"] = doc
+	}
+
+	var required []string
+
+	fields := m.Fields()
+	for i := range fields.Len() {
+		f := fields.Get(i)
+
+		var fieldSchema map[string]any
+
+		switch {
+		case f.IsMap():
+			fieldSchema = map[string]any{"type": "object", "additionalProperties": b.valueSchema(f.MapValue())}
+		case f.IsList():
+			fieldSchema = map[string]any{"type": "array", "items": b.valueSchema(f)}
+		default:
+			fieldSchema = b.valueSchema(f)
+		}
+
+		if doc := descriptorDoc(f); doc != "" {
+			// Keywords next to $ref are allowed in draft 2020-12 and in OpenAPI 3.1
+			fieldSchema = maps.Clone(fieldSchema)
+			fieldSchema["description"] = doc
+		}
+
+		properties[f.JSONName()] = fieldSchema
+
+		if f.Cardinality() == protoreflect.Required {
+			required = append(required, f.JSONName())
+		}
+	}
+
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+}
+
+// Returns the schema for a single value of the field (ignoring its cardinality).
+func (b *jsonSchemaBuilder) valueSchema(f protoreflect.FieldDescriptor) map[string]any {
+	switch f.Kind() {
+	case protoreflect.BoolKind:
+		return map[string]any{"type": "boolean"}
+	case protoreflect.StringKind:
+		return map[string]any{"type": "string"}
+	case protoreflect.BytesKind:
+		return map[string]any{"type": "string", "contentEncoding": "base64"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return map[string]any{"type": "integer", "format": "uint32"}
+	// 64-bit integers are encoded as strings, but integers are also accepted when parsing
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return map[string]any{"type": []string{"integer", "string"}, "format": "int64"}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return map[string]any{"type": []string{"integer", "string"}, "format": "uint64"}
+	case protoreflect.FloatKind:
+		return map[string]any{"type": "number", "format": "float"}
+	case protoreflect.DoubleKind:
+		return map[string]any{"type": "number", "format": "double"}
+	case protoreflect.EnumKind:
+		b.addEnum(f.Enum())
+		return b.ref(f.Enum().FullName())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		b.addMessage(f.Message())
+		return b.ref(f.Message().FullName())
+	}
+
+	return map[string]any{}
+}
+
+// Adds the schemas for all the messages and enums in the files (including the nested ones).
+func (b *jsonSchemaBuilder) addFiles(files []protoreflect.FileDescriptor) {
+	var addMessages func(messages protoreflect.MessageDescriptors)
+	var addEnums func(enums protoreflect.EnumDescriptors)
+
+	addEnums = func(enums protoreflect.EnumDescriptors) {
+		for i := range enums.Len() {
+			b.addEnum(enums.Get(i))
+		}
+	}
+
+	addMessages = func(messages protoreflect.MessageDescriptors) {
+		for i := range messages.Len() {
+			m := messages.Get(i)
+			b.addMessage(m)
+			addEnums(m.Enums())
+			addMessages(m.Messages())
+		}
+	}
+
+	for _, f := range files {
+		addEnums(f.Enums())
+		addMessages(f.Messages())
+	}
+}
+
+// Processes the files of this package and returns a JSON Schema (draft 2020-12) document, which contains a definition (in $defs) for each message and enum of the package, as well as for the ones they reference.
+// The schemas follow the JSON mapping of protobuf, and the comments of the elements are used as their descriptions.
+func (p *ProtoPackage) ExportJSONSchema() ([]byte, error) {
+	filesData, err := p.TryBuildFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.ExportJSONSchemaFromFiles(filesData)
+}
+
+// Returns the JSON Schema document of ExportJSONSchema for the data returned by TryBuildFiles, so that the schemas are not processed again.
+func (p *ProtoPackage) ExportJSONSchemaFromFiles(filesData []FileData) ([]byte, error) {
+	files, err := p.exportedFiles(filesData)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &jsonSchemaBuilder{refPrefix: "#/$defs/", defs: make(map[string]any)}
+	b.addFiles(files)
+
+	doc := map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id":     p.GetName(),
+		"$defs":   b.defs,
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// Processes the files of this package and returns an OpenAPI 3.1 document, which describes the methods of the package's services as they are exposed by connect (as POST requests to "/<package>.<Service>/<Method>", with JSON bodies).
+// The schemas of the messages and enums are the same ones used by ExportJSONSchema.
+func (p *ProtoPackage) ExportOpenAPI() ([]byte, error) {
+	filesData, err := p.TryBuildFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.ExportOpenAPIFromFiles(filesData)
+}
+
+// Returns the OpenAPI document of ExportOpenAPI for the data returned by TryBuildFiles, so that the schemas are not processed again.
+func (p *ProtoPackage) ExportOpenAPIFromFiles(filesData []FileData) ([]byte, error) {
+	files, err := p.exportedFiles(filesData)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &jsonSchemaBuilder{refPrefix: "#/components/schemas/", defs: make(map[string]any)}
+	b.addFiles(files)
+
+	paths := make(map[string]any)
+	var tags []map[string]any
+
+	for _, f := range files {
+		services := f.Services()
+		for i := range services.Len() {
+			s := services.Get(i)
+
+			tag := map[string]any{"name": string(s.Name())}
+			if doc := descriptorDoc(s); doc != "" {
+				tag["description"] = doc
+			}
+			tags = append(tags, tag)
+
+			methods := s.Methods()
+			for j := range methods.Len() {
+				m := methods.Get(j)
+				b.addMessage(m.Input())
+				b.addMessage(m.Output())
+
+				operation := map[string]any{
+					"operationId": string(s.FullName()) + "." + string(m.Name()),
+					"tags":        []string{string(s.Name())},
+					"requestBody": map[string]any{
+						"required": true,
+						"content":  map[string]any{"application/json": map[string]any{"schema": b.ref(m.Input().FullName())}},
+					},
+					"responses": map[string]any{
+						"200": map[string]any{
+							"description": "Success",
+							"content":     map[string]any{"application/json": map[string]any{"schema": b.ref(m.Output().FullName())}},
+						},
+					},
+				}
+
+				if doc := descriptorDoc(m); doc != "" {
+					operation["description"] = doc
+				}
+
+				paths["/"+string(s.FullName())+"/"+string(m.Name())] = map[string]any{"post": operation}
+			}
+		}
+	}
+
+	version := "1.0.0"
+	if segments := strings.Split(p.GetName(), "."); versionSegmentRegex.MatchString(segments[len(segments)-1]) {
+		version = segments[len(segments)-1]
+	}
+
+	doc := map[string]any{
+		"openapi":    "3.1.0",
+		"info":       map[string]any{"title": p.GetName(), "version": version},
+		"paths":      paths,
+		"components": map[string]any{"schemas": b.defs},
+	}
+
+	if len(tags) > 0 {
+		slices.SortFunc(tags, func(a, b map[string]any) int { return strings.Compare(a["name"].(string), b["name"].(string)) })
+		doc["tags"] = tags
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// Compiles the processed files of the package, for the exporters.
+func (p *ProtoPackage) exportedFiles(filesData []FileData) ([]protoreflect.FileDescriptor, error) {
+	files, err := p.compileFiles(filesData)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to export the package %q: %w", p.GetName(), err)
+	}
+
+	return files, nil
+}
@@ -1,19 +1,410 @@
 package protoschema
 
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+)
+
 // The protobuf extensions for a given file.
 type Extensions struct {
-	Service []ExtensionField
-	Message []ExtensionField
-	Field   []ExtensionField
-	File    []ExtensionField
-	OneOf   []ExtensionField
+	Service   []ExtensionField
+	Message   []ExtensionField
+	Field     []ExtensionField
+	File      []ExtensionField
+	OneOf     []ExtensionField
+	Enum      []ExtensionField
+	EnumValue []ExtensionField
 }
 
 // A field belonging to a protobuf extension.
+// The type of the field can be a scalar type (set with Type), a message (set with Message) or an enum (set with Enum).
 type ExtensionField struct {
-	Name     string
-	Type     string
+	Name string
+	// The scalar type of this field (such as "string" or "int32").
+	Type string
+	// The message type of this field, if it is defined with this library.
+	Message *MessageSchema
+	// The enum type of this field, if it is defined with this library.
+	Enum     *EnumGroup
 	FieldNr  int
 	Optional bool
 	Repeated bool
 }
+
+// Returns the type of the extension field, as it should be written in a file that belongs to the given package.
+func (e ExtensionField) GetType(p *ProtoPackage) string {
+	switch {
+	case e.Message != nil:
+		return e.Message.GetFullName(p)
+	case e.Enum != nil:
+		return e.Enum.GetFullName(p)
+	}
+
+	return e.Type
+}
+
+// Returns an option that sets this extension to the given value.
+// For enum extensions, the value can be the name or the number of one of the enum's values, and it will be rendered as the value's name.
+// The option's name is relative to the package where the extension is declared. To use it in another package, the name must be qualified with the extension's package.
+func (e ExtensionField) Option(value any) ProtoOption {
+	if e.Enum != nil {
+		if name, err := e.Enum.resolveName(value); err == nil {
+			value = identifier(name)
+		}
+	}
+
+	return ProtoOption{Name: "(" + e.Name + ")", Value: value}
+}
+
+var scalarExtensionTypes = []string{"string", "bytes", "bool", "float", "double", "int32", "sint32", "sfixed32", "int64", "sint64", "sfixed64", "uint32", "fixed32", "uint64", "fixed64"}
+
+// The categories of options that can be extended, along with the name of the options message that they extend.
+var extensionCategories = []struct {
+	name    string
+	message string
+	get     func(Extensions) []ExtensionField
+}{
+	{"files", "google.protobuf.FileOptions", func(e Extensions) []ExtensionField { return e.File }},
+	{"services", "google.protobuf.ServiceOptions", func(e Extensions) []ExtensionField { return e.Service }},
+	{"messages", "google.protobuf.MessageOptions", func(e Extensions) []ExtensionField { return e.Message }},
+	{"fields", "google.protobuf.FieldOptions", func(e Extensions) []ExtensionField { return e.Field }},
+	{"oneofs", "google.protobuf.OneofOptions", func(e Extensions) []ExtensionField { return e.OneOf }},
+	{"enums", "google.protobuf.EnumOptions", func(e Extensions) []ExtensionField { return e.Enum }},
+	{"enum values", "google.protobuf.EnumValueOptions", func(e Extensions) []ExtensionField { return e.EnumValue }},
+}
+
+// Returns the total number of extensions.
+func (e Extensions) len() int {
+	total := 0
+	for _, c := range extensionCategories {
+		total += len(c.get(e))
+	}
+
+	return total
+}
+
+// Checks the declarations of the extensions and adds the imports for their message and enum types.
+func (e Extensions) build(imports Set) error {
+	var err error
+
+	for _, c := range extensionCategories {
+		for _, ext := range c.get(e) {
+			typesCount := 0
+			if ext.Type != "" {
+				typesCount++
+			}
+
+			if ext.Message != nil {
+				typesCount++
+				if importPath := ext.Message.GetImportPath(); importPath != "" {
+					imports[importPath] = present
+				}
+			}
+
+			if ext.Enum != nil {
+				typesCount++
+				if importPath := ext.Enum.GetImportPath(); importPath != "" {
+					imports[importPath] = present
+				}
+			}
+
+			if typesCount != 1 {
+				err = errors.Join(err, fmt.Errorf("Extension %q for %s must have exactly one of Type, Message or Enum.", ext.Name, c.message))
+			}
+		}
+	}
+
+	return err
+}
+
+type declaredExtension struct {
+	field    ExtensionField
+	category string
+}
+
+// Collects the extensions declared in all the files of the package, mapped by their full name.
+func (p *ProtoPackage) declaredExtensions() map[string]declaredExtension {
+	out := make(map[string]declaredExtension)
+
+	if p == nil {
+		return out
+	}
+
+	for _, f := range p.fileSchemas {
+		for _, c := range extensionCategories {
+			for _, ext := range c.get(f.Extensions) {
+				out[p.GetName()+"."+ext.Name] = declaredExtension{field: ext, category: c.name}
+			}
+		}
+	}
+
+	return out
+}
+
+// Checks the options that use the extensions declared in this package.
+type extensionOptionsChecker struct {
+	pkg        string
+	extensions map[string]declaredExtension
+}
+
+// Finds the extension used by an option, following the protobuf scoping rules. Returns false if the option does not use an extension declared in this package.
+func (c extensionOptionsChecker) find(optName string) (declaredExtension, bool, bool) {
+	if !strings.HasPrefix(optName, "(") {
+		return declaredExtension{}, false, false
+	}
+
+	end := strings.Index(optName, ")")
+	if end == -1 {
+		return declaredExtension{}, false, false
+	}
+
+	name := optName[1:end]
+	isSubField := end != len(optName)-1
+
+	if fullName, ok := strings.CutPrefix(name, "."); ok {
+		ext, found := c.extensions[fullName]
+		return ext, found, isSubField
+	}
+
+	scope := c.pkg
+	for {
+		candidate := name
+		if scope != "" {
+			candidate = scope + "." + name
+		}
+
+		if ext, found := c.extensions[candidate]; found {
+			return ext, true, isSubField
+		}
+
+		if scope == "" {
+			return declaredExtension{}, false, isSubField
+		}
+
+		if idx := strings.LastIndex(scope, "."); idx != -1 {
+			scope = scope[:idx]
+		} else {
+			scope = ""
+		}
+	}
+}
+
+// Checks that the options that use a declared extension are set on the right type of element, and that their values match the extension's type.
+func (c extensionOptionsChecker) check(category string, opts []ProtoOption) error {
+	var err error
+
+	for _, o := range opts {
+		ext, found, isSubField := c.find(o.Name)
+		if !found {
+			continue
+		}
+
+		if ext.category != category {
+			err = errors.Join(err, fmt.Errorf("The option %q uses an extension for %s, so it cannot be used on %s.", o.Name, ext.category, category))
+			continue
+		}
+
+		if isSubField {
+			continue
+		}
+
+		if valueErr := ext.field.checkValue(o.Value); valueErr != nil {
+			err = errors.Join(err, fmt.Errorf("Invalid value for the option %q: %w", o.Name, valueErr))
+		}
+	}
+
+	return err
+}
+
+// Checks the options of the fields, which are collected from their ExtensionOptions.
+func (c extensionOptionsChecker) checkFields(fields []FieldData) error {
+	var err error
+
+	for _, f := range fields {
+		if fieldErr := c.check("fields", f.ExtensionOptions); fieldErr != nil {
+			err = errors.Join(err, indentErrors(fmt.Sprintf("Errors for field %q", f.Name), fieldErr))
+		}
+	}
+
+	return err
+}
+
+func (c extensionOptionsChecker) checkMessage(m MessageData) error {
+	err := c.check("messages", m.Options)
+	err = errors.Join(err, c.checkFields(m.Fields))
+
+	for _, of := range m.Oneofs {
+		err = errors.Join(err, c.check("oneofs", of.Options))
+		err = errors.Join(err, c.checkFields(of.Fields))
+	}
+
+	for _, e := range m.Enums {
+		err = errors.Join(err, c.checkEnum(e))
+	}
+
+	for _, nested := range m.Messages {
+		if nestedErr := c.checkMessage(nested); nestedErr != nil {
+			err = errors.Join(err, indentErrors(fmt.Sprintf("Errors for nested message %q", nested.Name), nestedErr))
+		}
+	}
+
+	return err
+}
+
+func (c extensionOptionsChecker) checkEnum(e EnumGroup) error {
+	err := c.check("enums", e.Options)
+
+	for _, v := range e.Values {
+		err = errors.Join(err, c.check("enum values", v.Options))
+	}
+
+	return err
+}
+
+// Checks all the options in the file that use the extensions declared in its package.
+func (c extensionOptionsChecker) checkFile(file FileData) error {
+	err := c.check("files", file.Options)
+
+	for _, e := range file.Enums {
+		err = errors.Join(err, c.checkEnum(e))
+	}
+
+	for _, m := range file.Messages {
+		err = errors.Join(err, c.checkMessage(m))
+	}
+
+	for _, ext := range file.Extends {
+		err = errors.Join(err, c.checkFields(ext.Fields))
+	}
+
+	for _, s := range file.Services {
+		err = errors.Join(err, c.check("services", s.Options))
+	}
+
+	if err != nil {
+		return indentErrors("Invalid extension options", err)
+	}
+
+	return nil
+}
+
+// Checks that a value can be assigned to this extension field.
+func (e ExtensionField) checkValue(value any) error {
+	if e.Repeated {
+		val := reflect.ValueOf(value)
+		if (val.Kind() == reflect.Slice || val.Kind() == reflect.Array) && val.Type().Elem().Kind() != reflect.Uint8 {
+			var err error
+			for i := range val.Len() {
+				err = errors.Join(err, e.checkSingleValue(val.Index(i).Interface()))
+			}
+
+			return err
+		}
+	}
+
+	return e.checkSingleValue(value)
+}
+
+func (e ExtensionField) checkSingleValue(value any) error {
+	switch {
+	case e.Enum != nil:
+		name, isIdentifier := value.(identifier)
+		if !isIdentifier {
+			if _, err := e.Enum.resolveValue(value); err != nil {
+				return err
+			}
+
+			return fmt.Errorf("Expected a value of the enum %q, found %v (%T). Use the Option method of the extension to set enum values.", e.Enum.GetName(), value, value)
+		}
+
+		_, err := e.Enum.resolveValue(string(name))
+		return err
+	case e.Message != nil:
+		val := reflect.ValueOf(value)
+		if val.Kind() == reflect.Pointer {
+			val = val.Elem()
+		}
+
+		if val.Kind() != reflect.Map && val.Kind() != reflect.Struct {
+			return fmt.Errorf("Expected a map or a struct for the message %q, found %v (%T).", e.Message.GetName(), value, value)
+		}
+
+		return nil
+	}
+
+	// Free-form types that are not scalars (such as imported messages) cannot be checked
+	if !slices.Contains(scalarExtensionTypes, e.Type) {
+		return nil
+	}
+
+	mismatch := fmt.Errorf("Expected a value of type %s, found %v (%T).", e.Type, value, value)
+	val := reflect.ValueOf(value)
+
+	switch e.Type {
+	case "string":
+		if val.Kind() != reflect.String {
+			return mismatch
+		}
+	case "bytes":
+		if _, isBytes := value.([]byte); !isBytes && val.Kind() != reflect.String {
+			return mismatch
+		}
+	case "bool":
+		if val.Kind() != reflect.Bool {
+			return mismatch
+		}
+	case "float", "double":
+		if !val.CanFloat() && !val.CanInt() && !val.CanUint() {
+			return mismatch
+		}
+	case "int32", "sint32", "sfixed32":
+		return checkIntegerValue(value, e.Type, math.MinInt32, math.MaxInt32)
+	case "int64", "sint64", "sfixed64":
+		return checkIntegerValue(value, e.Type, math.MinInt64, math.MaxInt64)
+	case "uint32", "fixed32":
+		return checkIntegerValue(value, e.Type, 0, math.MaxUint32)
+	case "uint64", "fixed64":
+		return checkIntegerValue(value, e.Type, 0, math.MaxUint64)
+	}
+
+	return nil
+}
+
+// Checks that the value is an integer between min and max.
+func checkIntegerValue(value any, protoType string, minVal int64, maxVal uint64) error {
+	val := reflect.ValueOf(value)
+
+	switch {
+	case val.CanInt():
+		n := val.Int()
+		if n < minVal || (n > 0 && uint64(n) > maxVal) {
+			return fmt.Errorf("The value %d is out of range for the type %s.", n, protoType)
+		}
+	case val.CanUint():
+		if n := val.Uint(); n > maxVal {
+			return fmt.Errorf("The value %d is out of range for the type %s.", n, protoType)
+		}
+	default:
+		return fmt.Errorf("Expected a value of type %s, found %v (%T).", protoType, value, value)
+	}
+
+	return nil
+}
+
+// Collects the options that use extensions (with names in parentheses), sorted by name.
+func extensionOptions(opts map[string]any) []ProtoOption {
+	var out []ProtoOption
+
+	for _, name := range slices.Sorted(maps.Keys(opts)) {
+		if strings.HasPrefix(name, "(") {
+			out = append(out, ProtoOption{Name: name, Value: opts[name]})
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,119 @@
+package protoschema_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"github.com/bufbuild/protocompile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtensionOptions(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:               "options.v1",
+		GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/optionsv1",
+		ProtoRoot:          tmpDir,
+		ConverterOutputDir: filepath.Join(tmpDir, "converter"),
+	})
+
+	typesFile := pkg.NewFile(sb.FileSchema{Name: "types"})
+
+	visibility := typesFile.NewEnum(sb.EnumGroup{Name: "Visibility", Members: sb.EnumMembers{0: "VISIBILITY_UNSPECIFIED", 1: "VISIBILITY_PUBLIC"}})
+	cacheConfig := typesFile.NewMessage(sb.MessageSchema{Name: "CacheConfig", Fields: sb.FieldsMap{1: sb.Int32("ttl"), 2: sb.Bool("enabled")}})
+
+	label := sb.ExtensionField{Name: "label", Type: "string", FieldNr: 50001}
+	priority := sb.ExtensionField{Name: "priority", Type: "uint32", FieldNr: 50002}
+	visibilityExt := sb.ExtensionField{Name: "visibility", Enum: visibility, FieldNr: 50003}
+	cache := sb.ExtensionField{Name: "cache", Message: cacheConfig, FieldNr: 50004}
+
+	file := pkg.NewFile(sb.FileSchema{
+		Name: "options",
+		Extensions: sb.Extensions{
+			Message: []sb.ExtensionField{visibilityExt, cache},
+			Field:   []sb.ExtensionField{label, priority},
+		},
+	})
+
+	file.NewMessage(sb.MessageSchema{
+		Name: "Item",
+		Fields: sb.FieldsMap{
+			1: sb.String("name").Options(label.Option("Name"), priority.Option(1)),
+		},
+		Options: []sb.ProtoOption{visibilityExt.Option("VISIBILITY_PUBLIC"), cache.Option(map[string]any{"ttl": 60, "enabled": true})},
+	})
+
+	assert.NoError(t, pkg.Generate())
+
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: []string{tmpDir}}),
+	}
+
+	results, err := compiler.Compile(context.Background(), "options/v1/options.proto")
+	if assert.NoError(t, err) {
+		msg := results[0].Messages().ByName("Item")
+		assert.NotNil(t, msg)
+		assert.NotEmpty(t, msg.Options())
+	}
+
+	newFile := func() *sb.FileSchema {
+		return sb.NewProtoPackage(sb.ProtoPackageConfig{
+			Name:      "options.v1",
+			GoPackage: "github.com/Rick-Phoenix/protoschema/gen/optionsv1",
+			ProtoRoot: t.TempDir(),
+		}).NewFile(sb.FileSchema{
+			Name: "invalid",
+			Extensions: sb.Extensions{
+				File:    []sb.ExtensionField{{Name: "testopt", Type: "string", FieldNr: 50000}},
+				Message: []sb.ExtensionField{visibilityExt},
+				Field:   []sb.ExtensionField{priority},
+			},
+		})
+	}
+
+	shouldFail := map[string]func() *sb.FileSchema{
+		"wrong scalar type": func() *sb.FileSchema {
+			f := newFile()
+			f.Options = []sb.ProtoOption{{Name: "(testopt)", Value: 3}}
+			return f
+		},
+		"wrong scalar type with the full name": func() *sb.FileSchema {
+			f := newFile()
+			f.Options = []sb.ProtoOption{{Name: "(options.v1.testopt)", Value: true}}
+			return f
+		},
+		"wrong element": func() *sb.FileSchema {
+			f := newFile()
+			f.NewMessage(sb.MessageSchema{Name: "Item", Options: []sb.ProtoOption{{Name: "(testopt)", Value: "x"}}})
+			return f
+		},
+		"out of range": func() *sb.FileSchema {
+			f := newFile()
+			f.NewMessage(sb.MessageSchema{Name: "Item", Fields: sb.FieldsMap{1: sb.String("name").Options(priority.Option(-1))}})
+			return f
+		},
+		"unknown enum value": func() *sb.FileSchema {
+			f := newFile()
+			f.NewMessage(sb.MessageSchema{Name: "Item", Options: []sb.ProtoOption{visibilityExt.Option("VISIBILITY_SECRET")}})
+			return f
+		},
+		"enum value as a string": func() *sb.FileSchema {
+			f := newFile()
+			f.NewMessage(sb.MessageSchema{Name: "Item", Options: []sb.ProtoOption{{Name: "(visibility)", Value: "VISIBILITY_PUBLIC"}}})
+			return f
+		},
+		"missing type": func() *sb.FileSchema {
+			f := newFile()
+			f.Extensions.Service = []sb.ExtensionField{{Name: "untyped", FieldNr: 50010}}
+			return f
+		},
+	}
+
+	for name, build := range shouldFail {
+		_, err := build().Package.TryBuildFiles()
+		assert.Error(t, err, name)
+	}
+}
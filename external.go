@@ -8,6 +8,7 @@ import (
 // A field that has a protobuf message as its type.
 func MsgField(name string, s *MessageSchema) *GenericField {
 	rules := make(map[string]any)
+	options := make(map[string]any)
 
 	if s == nil {
 		log.Fatalf("Could not generate the message type for field %q because the schema given was nil.", name)
@@ -36,6 +37,7 @@ func MsgField(name string, s *MessageSchema) *GenericField {
 		protoType:   s.Name,
 		goType:      goType,
 		isNonScalar: true,
+		options:     options,
 		rules:       rules,
 		imports:     imports,
 		messageRef:  s,
@@ -0,0 +1,1565 @@
+package protoschema
+
+import (
+	"cmp"
+	"fmt"
+	"log"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"regexp"
+	"regexp/syntax"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// A message that violates a single rule, created by FakeInvalid.
+type InvalidFake struct {
+	// The path of the field with the invalid value, such as "tags[0]"
+	Field string
+	// The id of the rule that is violated, such as "string.min_len"
+	RuleId  string
+	Message *dynamicpb.Message
+}
+
+// Creates a dynamic message for this schema with realistic values that satisfy its rules, causing a fatal error if the files of the package cannot be compiled. The same seed always produces the same values (except for the timestamps with rules relative to the current time), and the message can be serialized with ToJSON or ToBinary.
+// The values respect the rules of the builders of this library: the lengths, formats (such as email, uuid, hostname, ip and uri) and patterns of strings, the bounds of numbers, const and in, the rules of timestamps (including GtNow, LtNow and Within) and durations, the number of items of repeated fields (with unique items if required) and of the entries of maps, and the oneofs, where exactly one field is set. CEL expressions are not taken into account.
+func (m *MessageSchema) Fake(seed int64) *dynamicpb.Message {
+	desc, err := m.Descriptor()
+	if err != nil {
+		log.Fatalf("Could not create the fake message for %q: %v", m.GetName(), err)
+	}
+
+	return newFaker(seed).message(desc)
+}
+
+// Creates a fake message with Fake and serializes it in the protobuf JSON format.
+func (m *MessageSchema) FakeJSON(seed int64) ([]byte, error) {
+	return m.ToJSON(m.Fake(seed))
+}
+
+// Creates the invalid variants of the message created by Fake with the same seed: each of them has a single value changed so that it violates exactly one rule, such as a string that is one character shorter than its MinLen, a number that is equal to the value of Gt, or a missing required field.
+// The rules of nested messages and of the items, keys and values of repeated and map fields are included, and each variant is checked natively (as with Validate), so the rules that cannot be violated on their own are skipped.
+func (m *MessageSchema) FakeInvalid(seed int64) []InvalidFake {
+	desc, err := m.Descriptor()
+	if err != nil {
+		log.Fatalf("Could not create the fake messages for %q: %v", m.GetName(), err)
+	}
+
+	f := newFaker(seed)
+	return f.invalidFakes(desc, f.message(desc))
+}
+
+// Returns the variants of a valid message that violate exactly one rule each.
+func (f *faker) invalidFakes(desc protoreflect.MessageDescriptor, valid *dynamicpb.Message) []InvalidFake {
+	var fakes []InvalidFake
+
+	for _, candidates := range f.invalidMessage(desc, 0) {
+		for _, mutate := range candidates {
+			msg := proto.Clone(valid).(*dynamicpb.Message)
+			mutate(msg)
+
+			ev := &ruleEvaluator{now: f.now, rules: f.rules}
+			ev.message(msg, nil)
+
+			if len(ev.violations) == 1 {
+				violation := ev.violations[0]
+				fakes = append(fakes, InvalidFake{Field: FieldPathString(violation.GetField()), RuleId: violation.GetRuleId(), Message: msg})
+				break
+			}
+		}
+	}
+
+	return fakes
+}
+
+const (
+	// The depth after which the message fields that are not required are left empty, so that recursive messages are finite
+	maxFakeDepth = 3
+	// The number of values that are generated for a field before giving up on satisfying all of its rules
+	maxFakeAttempts = 20
+)
+
+var fakeWords = []string{
+	"alpha", "amber", "anchor", "apple", "atlas", "breeze", "bright", "canyon", "cedar", "cloud", "comet", "coral",
+	"delta", "ember", "falcon", "forest", "garden", "harbor", "island", "jasper", "lunar", "maple", "meadow", "nova",
+	"ocean", "orbit", "pebble", "prairie", "quartz", "river", "sierra", "solar", "summit", "timber", "valley", "willow",
+}
+
+// Generates the values of fake messages.
+type faker struct {
+	rand *rand.Rand
+	now  time.Time
+	// The rules used instead of those of the compiled fields, if defined
+	rules fieldRulesOverride
+}
+
+func newFaker(seed int64) *faker {
+	return &faker{rand: rand.New(rand.NewPCG(uint64(seed), 0x70726f746f)), now: time.Now().Truncate(time.Second)}
+}
+
+func (f *faker) word() string {
+	return fakeWords[f.rand.IntN(len(fakeWords))]
+}
+
+// The rules that are used instead of those in the options of the compiled fields, mapped by the full name of the field.
+type fieldRulesOverride map[protoreflect.FullName]protoreflect.Message
+
+// Returns the rules of a field, or nil if it has none.
+func (o fieldRulesOverride) fieldRules(fd protoreflect.FieldDescriptor) protoreflect.Message {
+	if rules, exists := o[fd.FullName()]; exists {
+		return rules
+	}
+
+	return optionExtension(fd.Options(), "buf.validate.field")
+}
+
+// Returns the nested rules with the given name, or nil if they are not defined.
+func rulesFor(rules protoreflect.Message, name protoreflect.Name) protoreflect.Message {
+	nested, _ := typeRules(rules, name)
+	return nested
+}
+
+// Returns the value of a rule, and false if it is not set.
+func ruleValue(rules protoreflect.Message, name protoreflect.Name) (protoreflect.Value, bool) {
+	if rules == nil || !hasRule(rules, name) {
+		return protoreflect.Value{}, false
+	}
+
+	return rules.Get(ruleField(rules, name)), true
+}
+
+func boolRule(rules protoreflect.Message, name protoreflect.Name) bool {
+	v, ok := ruleValue(rules, name)
+	return ok && v.Bool()
+}
+
+// Returns the value of a string rule, or an empty string if it is not set.
+func stringRule(rules protoreflect.Message, name protoreflect.Name) string {
+	v, ok := ruleValue(rules, name)
+	if !ok {
+		return ""
+	}
+
+	return v.String()
+}
+
+func uintRule(rules protoreflect.Message, name protoreflect.Name) (uint64, bool) {
+	v, ok := ruleValue(rules, name)
+	if !ok {
+		return 0, false
+	}
+
+	return v.Uint(), true
+}
+
+func listRule(rules protoreflect.Message, name protoreflect.Name) []protoreflect.Value {
+	v, ok := ruleValue(rules, name)
+	if !ok {
+		return nil
+	}
+
+	list := v.List()
+	values := make([]protoreflect.Value, list.Len())
+	for i := range list.Len() {
+		values[i] = list.Get(i)
+	}
+
+	return values
+}
+
+func containsValue(values []protoreflect.Value, v protoreflect.Value) bool {
+	return slices.ContainsFunc(values, func(item protoreflect.Value) bool { return valuesEqual(item, v) })
+}
+
+// Returns the name of the rules for a kind of field, such as "int32" or "string".
+func kindRulesName(fd protoreflect.FieldDescriptor) protoreflect.Name {
+	if fd.Kind() == protoreflect.MessageKind {
+		switch fd.Message().FullName() {
+		case "google.protobuf.Timestamp":
+			return "timestamp"
+		case "google.protobuf.Duration":
+			return "duration"
+		case "google.protobuf.Any":
+			return "any"
+		}
+	}
+
+	return protoreflect.Name(fd.Kind().String())
+}
+
+// Checks if a field of a message satisfies all of its rules (including those of the nested messages).
+func (f *faker) fieldIsValid(m protoreflect.Message, fd protoreflect.FieldDescriptor) bool {
+	ev := &ruleEvaluator{now: f.now, rules: f.rules}
+	ev.field(m, fd, nil)
+	return len(ev.violations) == 0
+}
+
+// Checks if a single value satisfies the rules for a field (or for the items, keys or values of a repeated or map field).
+func (f *faker) valueIsValid(v protoreflect.Value, fd protoreflect.FieldDescriptor, rules protoreflect.Message) bool {
+	ev := &ruleEvaluator{now: f.now, rules: f.rules}
+	ev.value(v, fd, rules, ruleTarget{})
+	return len(ev.violations) == 0
+}
+
+// Creates a message with valid values.
+func (f *faker) message(desc protoreflect.MessageDescriptor) *dynamicpb.Message {
+	msg := dynamicpb.NewMessage(desc)
+	f.fillMessage(msg, 0)
+	return msg
+}
+
+// Sets the fields of a message with valid values. Only one field of each oneof is set.
+func (f *faker) fillMessage(m protoreflect.Message, depth int) {
+	desc := m.Descriptor()
+	skipped := make(map[protoreflect.FieldDescriptor]bool)
+
+	oneofs := desc.Oneofs()
+	for i := range oneofs.Len() {
+		od := oneofs.Get(i)
+		if od.IsSynthetic() {
+			continue
+		}
+
+		chosen := f.rand.IntN(od.Fields().Len())
+		for j := range od.Fields().Len() {
+			if j != chosen {
+				skipped[od.Fields().Get(j)] = true
+			}
+		}
+	}
+
+	if rules := optionExtension(desc.Options(), "buf.validate.message"); rules != nil {
+		for _, rule := range listRule(rules, "oneof") {
+			names := listRule(rule.Message(), "fields")
+			if len(names) == 0 {
+				continue
+			}
+
+			chosen := f.rand.IntN(len(names))
+			for i, name := range names {
+				if fd := desc.Fields().ByName(protoreflect.Name(name.String())); fd != nil && i != chosen {
+					skipped[fd] = true
+				}
+			}
+		}
+	}
+
+	fields := desc.Fields()
+	for i := range fields.Len() {
+		if fd := fields.Get(i); !skipped[fd] {
+			f.fillField(m, fd, depth)
+		}
+	}
+}
+
+// Sets a field with a valid value, trying different values until all of its rules are satisfied.
+func (f *faker) fillField(m protoreflect.Message, fd protoreflect.FieldDescriptor, depth int) {
+	rules := f.rules.fieldRules(fd)
+	required := boolRule(rules, "required")
+
+	// The repeated and map fields only have the minimum number of items at this depth
+	if depth >= maxFakeDepth && !required && !fd.IsList() && !fd.IsMap() && kindRulesName(fd) == "message" {
+		return
+	}
+
+	for range maxFakeAttempts {
+		m.Clear(fd)
+
+		switch {
+		case fd.IsList():
+			f.fillList(m.Mutable(fd).List(), fd, rules, depth)
+		case fd.IsMap():
+			f.fillMap(m.Mutable(fd).Map(), fd, rules, depth)
+		default:
+			m.Set(fd, f.value(fd, rules, func() protoreflect.Value { return m.NewField(fd) }, depth))
+		}
+
+		if f.fieldIsValid(m, fd) {
+			return
+		}
+	}
+}
+
+// Returns the number of items for a repeated or map field, within the limits of its rules.
+func (f *faker) count(rules protoreflect.Message, minName, maxName protoreflect.Name, depth int) int {
+	lower, _ := uintRule(rules, minName)
+	upper, hasUpper := uintRule(rules, maxName)
+
+	// The message fields beyond the maximum depth only have the minimum number of items
+	if depth >= maxFakeDepth {
+		return int(lower)
+	}
+
+	if !hasUpper {
+		upper = lower + 3
+	}
+
+	lower = max(lower, min(1, upper))
+	upper = min(upper, lower+5)
+
+	return int(lower) + f.rand.IntN(int(upper-lower)+1)
+}
+
+func (f *faker) fillList(list protoreflect.List, fd protoreflect.FieldDescriptor, rules protoreflect.Message, depth int) {
+	repeated := rulesFor(rules, "repeated")
+	items := rulesFor(repeated, "items")
+	count := f.count(repeated, "min_items", "max_items", depth)
+
+	seen := make(map[any]bool)
+	unique := boolRule(repeated, "unique")
+
+	for attempts := 0; list.Len() < count && attempts < count*maxFakeAttempts; attempts++ {
+		v := f.value(fd, items, list.NewElement, depth)
+
+		if unique && fd.Message() == nil {
+			key := fmt.Sprint(v.Interface())
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+
+		list.Append(v)
+	}
+}
+
+func (f *faker) fillMap(entries protoreflect.Map, fd protoreflect.FieldDescriptor, rules protoreflect.Message, depth int) {
+	mapRules := rulesFor(rules, "map")
+	keys := rulesFor(mapRules, "keys")
+	values := rulesFor(mapRules, "values")
+	count := f.count(mapRules, "min_pairs", "max_pairs", depth)
+
+	for attempts := 0; entries.Len() < count && attempts < count*maxFakeAttempts; attempts++ {
+		key := f.value(fd.MapKey(), keys, nil, depth).MapKey()
+		if entries.Has(key) {
+			continue
+		}
+
+		entries.Set(key, f.value(fd.MapValue(), values, entries.NewValue, depth))
+	}
+}
+
+// Returns a valid value for a field (or for the items, keys or values of a repeated or map field). The messages are created with newValue.
+func (f *faker) value(fd protoreflect.FieldDescriptor, rules protoreflect.Message, newValue func() protoreflect.Value, depth int) protoreflect.Value {
+	if fd.Message() != nil && kindRulesName(fd) == "message" {
+		v := newValue()
+		f.fillMessage(v.Message(), depth+1)
+		return v
+	}
+
+	var v protoreflect.Value
+
+	for range maxFakeAttempts {
+		v = f.scalar(fd, rules, newValue)
+		if f.valueIsValid(v, fd, rules) {
+			break
+		}
+	}
+
+	return v
+}
+
+// Returns a random value for a field with a scalar type, or with a well-known type such as timestamp or duration.
+func (f *faker) scalar(fd protoreflect.FieldDescriptor, rules protoreflect.Message, newValue func() protoreflect.Value) protoreflect.Value {
+	name := kindRulesName(fd)
+	typed := rulesFor(rules, name)
+
+	if v, ok := ruleValue(typed, "const"); ok {
+		if fd.Kind() == protoreflect.EnumKind {
+			return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v.Int()))
+		}
+
+		if fd.Message() != nil {
+			msg := newValue()
+			proto.Merge(msg.Message().Interface(), v.Message().Interface())
+			return msg
+		}
+
+		return v
+	}
+
+	if in := listRule(typed, "in"); len(in) > 0 && name != "any" {
+		v := in[f.rand.IntN(len(in))]
+
+		switch {
+		case fd.Kind() == protoreflect.EnumKind:
+			return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v.Int()))
+		case fd.Message() != nil:
+			msg := newValue()
+			proto.Merge(msg.Message().Interface(), v.Message().Interface())
+			return msg
+		}
+
+		return v
+	}
+
+	switch name {
+	case "string":
+		return protoreflect.ValueOfString(f.string(typed))
+	case "bytes":
+		return protoreflect.ValueOfBytes(f.bytes(typed))
+	case "bool":
+		return protoreflect.ValueOfBool(f.rand.IntN(4) != 0)
+	case "enum":
+		return protoreflect.ValueOfEnum(f.enum(fd.Enum(), typed))
+	case "timestamp":
+		msg := newValue()
+		seconds := f.timestamp(typed)
+		setSecondsAndNanos(msg.Message(), seconds, 0)
+		return msg
+	case "duration":
+		msg := newValue()
+		nanos := f.duration(typed)
+		setSecondsAndNanos(msg.Message(), nanos/int64(time.Second), nanos%int64(time.Second))
+		return msg
+	case "any":
+		msg := newValue()
+		if in := listRule(typed, "in"); len(in) > 0 {
+			msg.Message().Set(msg.Message().Descriptor().Fields().ByName("type_url"), in[0])
+		}
+		return msg
+	}
+
+	return f.number(fd.Kind(), typed)
+}
+
+func (f *faker) enum(desc protoreflect.EnumDescriptor, rules protoreflect.Message) protoreflect.EnumNumber {
+	notIn := listRule(rules, "not_in")
+
+	var candidates []protoreflect.EnumNumber
+	values := desc.Values()
+
+	for i := range values.Len() {
+		number := values.Get(i).Number()
+		if (number != 0 || values.Len() == 1) && !containsValue(notIn, protoreflect.ValueOfInt32(int32(number))) {
+			candidates = append(candidates, number)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return 0
+	}
+
+	return candidates[f.rand.IntN(len(candidates))]
+}
+
+// Returns the length of a string or bytes value, within the limits of its rules. The names are given in groups of three: the rules for the exact, minimum and maximum lengths.
+func (f *faker) length(rules protoreflect.Message, names ...protoreflect.Name) int {
+	lower, upper := uint64(0), uint64(math.MaxUint64)
+	hasLower, hasUpper := false, false
+
+	for i := 0; i+2 < len(names); i += 3 {
+		if exact, ok := uintRule(rules, names[i]); ok {
+			return int(exact)
+		}
+
+		if v, ok := uintRule(rules, names[i+1]); ok {
+			lower, hasLower = max(lower, v), true
+		}
+
+		if v, ok := uintRule(rules, names[i+2]); ok {
+			upper, hasUpper = min(upper, v), true
+		}
+	}
+
+	switch {
+	case !hasLower && !hasUpper:
+		lower, upper = 5, 16
+	case !hasLower:
+		lower = min(5, upper)
+	}
+
+	upper = max(min(upper, lower+20), lower)
+
+	return int(lower) + f.rand.IntN(int(upper-lower)+1)
+}
+
+// Returns a sentence of random words with the given length.
+func (f *faker) text(length int) string {
+	var b strings.Builder
+
+	for b.Len() < length {
+		if b.Len() > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(f.word())
+	}
+
+	text := b.String()[:length]
+	if strings.HasSuffix(text, " ") {
+		text = text[:length-1] + "s"
+	}
+
+	return text
+}
+
+func (f *faker) uuid() string {
+	b := make([]byte, 16)
+	for i := range b {
+		b[i] = byte(f.rand.UintN(256))
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func (f *faker) hostname() string {
+	return f.word() + "." + f.word() + ".com"
+}
+
+func (f *faker) ipv4() string {
+	return fmt.Sprintf("%d.%d.%d.%d", 10+f.rand.IntN(200), f.rand.IntN(256), f.rand.IntN(256), 1+f.rand.IntN(254))
+}
+
+func (f *faker) ipv6() string {
+	return fmt.Sprintf("2001:db8:%x:%x::%x", f.rand.IntN(0x10000), f.rand.IntN(0x10000), 1+f.rand.IntN(0xfffe))
+}
+
+// The generators for the values of the string formats, in the order in which they are checked.
+func (f *faker) stringFormat(rules protoreflect.Message) (string, bool) {
+	formats := []struct {
+		name     protoreflect.Name
+		generate func() string
+	}{
+		{"email", func() string { return f.word() + "." + f.word() + "@" + f.hostname() }},
+		{"hostname", f.hostname},
+		{"address", f.hostname},
+		{"ip", f.ipv4},
+		{"ipv4", f.ipv4},
+		{"ipv6", f.ipv6},
+		{"uri", func() string { return "https://" + f.hostname() + "/" + f.word() }},
+		{"uri_ref", func() string { return "/" + f.word() + "/" + f.word() }},
+		{"uuid", f.uuid},
+		{"tuuid", func() string { return strings.ReplaceAll(f.uuid(), "-", "") }},
+		{"ulid", func() string {
+			const alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+			b := []byte("01")
+			for len(b) < 26 {
+				b = append(b, alphabet[f.rand.IntN(len(alphabet))])
+			}
+			return string(b)
+		}},
+		{"ip_with_prefixlen", func() string { return f.ipv4() + "/24" }},
+		{"ipv4_with_prefixlen", func() string { return f.ipv4() + "/24" }},
+		{"ipv6_with_prefixlen", func() string { return f.ipv6() + "/64" }},
+		{"ip_prefix", func() string { return fmt.Sprintf("10.%d.0.0/16", f.rand.IntN(256)) }},
+		{"ipv4_prefix", func() string { return fmt.Sprintf("10.%d.0.0/16", f.rand.IntN(256)) }},
+		{"ipv6_prefix", func() string { return fmt.Sprintf("2001:db8:%x::/48", f.rand.IntN(0x10000)) }},
+		{"host_and_port", func() string { return fmt.Sprintf("%s:%d", f.hostname(), 1024+f.rand.IntN(60000)) }},
+	}
+
+	for _, format := range formats {
+		if boolRule(rules, format.name) {
+			return format.generate(), true
+		}
+	}
+
+	if v, ok := ruleValue(rules, "well_known_regex"); ok {
+		value := ruleField(rules, "well_known_regex").Enum().Values().ByNumber(v.Enum())
+		if value != nil && strings.HasSuffix(string(value.Name()), "HEADER_NAME") {
+			return "x-" + f.word(), true
+		}
+
+		return f.word(), true
+	}
+
+	return "", false
+}
+
+func (f *faker) string(rules protoreflect.Message) string {
+	if s, isFormat := f.stringFormat(rules); isFormat {
+		return s
+	}
+
+	if v, ok := ruleValue(rules, "pattern"); ok {
+		if re, err := syntax.Parse(v.String(), syntax.Perl); err == nil {
+			var b strings.Builder
+			f.fromRegex(re.Simplify(), &b)
+			return b.String()
+		}
+	}
+
+	prefix, suffix, contains := stringRule(rules, "prefix"), stringRule(rules, "suffix"), stringRule(rules, "contains")
+	fixed := prefix + contains + suffix
+
+	length := f.length(rules, "len", "min_len", "max_len", "len_bytes", "min_bytes", "max_bytes")
+	body := f.text(max(length-len(fixed), 0))
+
+	return prefix + body[:len(body)/2] + contains + body[len(body)/2:] + suffix
+}
+
+func (f *faker) bytes(rules protoreflect.Message) []byte {
+	switch {
+	case boolRule(rules, "ipv6"):
+		b := make([]byte, 16)
+		for i := range b {
+			b[i] = byte(f.rand.UintN(256))
+		}
+		return b
+	case boolRule(rules, "ip"), boolRule(rules, "ipv4"):
+		return []byte{10, byte(f.rand.UintN(256)), byte(f.rand.UintN(256)), byte(1 + f.rand.UintN(254))}
+	}
+
+	if v, ok := ruleValue(rules, "pattern"); ok {
+		if re, err := syntax.Parse(v.String(), syntax.Perl); err == nil {
+			var b strings.Builder
+			f.fromRegex(re.Simplify(), &b)
+			return []byte(b.String())
+		}
+	}
+
+	var prefix, suffix, contains []byte
+	for name, target := range map[protoreflect.Name]*[]byte{"prefix": &prefix, "suffix": &suffix, "contains": &contains} {
+		if v, ok := ruleValue(rules, name); ok {
+			*target = v.Bytes()
+		}
+	}
+
+	length := f.length(rules, "len", "min_len", "max_len")
+	body := f.text(max(length-len(prefix)-len(contains)-len(suffix), 0))
+
+	return slices.Concat(prefix, []byte(body[:len(body)/2]), contains, []byte(body[len(body)/2:]), suffix)
+}
+
+// Writes a random string that matches a regular expression.
+func (f *faker) fromRegex(re *syntax.Regexp, b *strings.Builder) {
+	repeat := func(lower, upper int) {
+		if upper < 0 {
+			upper = lower + 3
+		}
+		upper = min(upper, lower+5)
+
+		for range lower + f.rand.IntN(upper-lower+1) {
+			f.fromRegex(re.Sub[0], b)
+		}
+	}
+
+	switch re.Op {
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		b.WriteRune(f.runeInClass(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteByte(byte('a' + f.rand.IntN(26)))
+	case syntax.OpCapture:
+		f.fromRegex(re.Sub[0], b)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			f.fromRegex(sub, b)
+		}
+	case syntax.OpAlternate:
+		f.fromRegex(re.Sub[f.rand.IntN(len(re.Sub))], b)
+	case syntax.OpStar:
+		repeat(0, 3)
+	case syntax.OpPlus:
+		repeat(1, 3)
+	case syntax.OpQuest:
+		repeat(0, 1)
+	case syntax.OpRepeat:
+		repeat(re.Min, re.Max)
+	}
+}
+
+// Returns a random rune from a character class, preferring letters and digits.
+func (f *faker) runeInClass(ranges []rune) rune {
+	var readable []rune
+
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for r := max(ranges[i], ' '); r <= min(ranges[i+1], '~'); r++ {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				readable = append(readable, r)
+			}
+		}
+	}
+
+	if len(readable) > 0 {
+		return readable[f.rand.IntN(len(readable))]
+	}
+
+	if len(ranges) < 2 {
+		return 'a'
+	}
+
+	i := f.rand.IntN(len(ranges)/2) * 2
+	return ranges[i] + rune(f.rand.IntN(int(min(ranges[i+1]-ranges[i], 100))+1))
+}
+
+// The limits of the integer kinds.
+func intLimits(kind protoreflect.Kind) (int64, int64) {
+	switch kind {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return math.MinInt32, math.MaxInt32
+	}
+
+	return math.MinInt64, math.MaxInt64
+}
+
+func uintLimit(kind protoreflect.Kind) uint64 {
+	if kind == protoreflect.Uint32Kind || kind == protoreflect.Fixed32Kind {
+		return math.MaxUint32
+	}
+
+	return math.MaxUint64
+}
+
+// Returns the bounds of a range, as defined by gt, gte, lt and lte. The bounds are reported as missing if the range is exclusive (when the lower bound is greater than the upper bound), so that the values above the lower bound are used.
+func rangeRules(rules protoreflect.Message) (lower, upper protoreflect.Value, lowerName, upperName protoreflect.Name) {
+	for _, name := range []protoreflect.Name{"gt", "gte"} {
+		if v, ok := ruleValue(rules, name); ok {
+			lower, lowerName = v, name
+		}
+	}
+
+	for _, name := range []protoreflect.Name{"lt", "lte"} {
+		if v, ok := ruleValue(rules, name); ok {
+			upper, upperName = v, name
+		}
+	}
+
+	if lowerName != "" && upperName != "" {
+		if c, _ := compareOrdered(lower, upper); c > 0 {
+			upperName = ""
+		}
+	}
+
+	return lower, upper, lowerName, upperName
+}
+
+// Returns a random integer between the bounds of the rules (inclusive), within the given limits. The values are converted to integers with the toInt function, and the default lower bound and span are used when a bound is missing.
+func (f *faker) intInRange(rules protoreflect.Message, limitLower, limitUpper, defaultLower, span int64, toInt func(protoreflect.Value) int64) int64 {
+	lower, upper, lowerName, upperName := rangeRules(rules)
+
+	lo, hi := defaultLower, defaultLower+span
+
+	if lowerName != "" {
+		lo = toInt(lower)
+		if lowerName == "gt" && lo < limitUpper {
+			lo++
+		}
+
+		if hi = lo + span; hi < lo {
+			hi = limitUpper
+		}
+	}
+
+	if upperName != "" {
+		hi = toInt(upper)
+		if upperName == "lt" && hi > limitLower {
+			hi--
+		}
+
+		if lowerName == "" && hi < lo {
+			if lo = hi - span; lo > hi {
+				lo = limitLower
+			}
+		}
+	}
+
+	lo, hi = max(lo, limitLower), min(hi, limitUpper)
+
+	if hi <= lo {
+		return lo
+	}
+
+	// The span is limited, so that the difference does not overflow
+	if hi-lo < 0 || hi-lo > span {
+		hi = lo + span
+	}
+
+	return lo + f.rand.Int64N(hi-lo+1)
+}
+
+// Returns a random number that satisfies the range and not_in rules of a numeric field.
+func (f *faker) number(kind protoreflect.Kind, rules protoreflect.Message) protoreflect.Value {
+	const span = 1000
+
+	switch kind {
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		lower, upper, lowerName, upperName := rangeRules(rules)
+		lo, hi := 1.0, float64(span)
+
+		if lowerName != "" {
+			lo = lower.Float()
+			hi = lo + span
+		}
+
+		if upperName != "" {
+			hi = upper.Float()
+			if lowerName == "" {
+				lo = hi - span
+			}
+		}
+
+		v := lo + f.rand.Float64()*(hi-lo)
+		// The values are rounded to two decimals when they remain within the bounds
+		if rounded := math.Round(v*100) / 100; rounded > lo && rounded < hi {
+			v = rounded
+		}
+
+		if kind == protoreflect.FloatKind {
+			return protoreflect.ValueOfFloat32(float32(v))
+		}
+
+		return protoreflect.ValueOfFloat64(v)
+	case protoreflect.Uint32Kind, protoreflect.Uint64Kind, protoreflect.Fixed32Kind, protoreflect.Fixed64Kind:
+		lower, upper, lowerName, upperName := rangeRules(rules)
+		lo, hi := uint64(1), uint64(span)
+
+		if lowerName != "" {
+			lo = lower.Uint()
+			if lowerName == "gt" {
+				lo++
+			}
+
+			if hi = lo + span; hi < lo {
+				hi = math.MaxUint64
+			}
+		}
+
+		if upperName != "" {
+			hi = upper.Uint()
+			if upperName == "lt" && hi > 0 {
+				hi--
+			}
+
+			if lowerName == "" && hi < lo {
+				lo = 0
+			}
+		}
+
+		hi = min(hi, uintLimit(kind), lo+span)
+		v := lo
+		if hi > lo {
+			v = lo + f.rand.Uint64N(hi-lo+1)
+		}
+
+		if kind == protoreflect.Uint32Kind || kind == protoreflect.Fixed32Kind {
+			return protoreflect.ValueOfUint32(uint32(v))
+		}
+
+		return protoreflect.ValueOfUint64(v)
+	}
+
+	limitLower, limitUpper := intLimits(kind)
+	v := f.intInRange(rules, limitLower, limitUpper, 1, span, protoreflect.Value.Int)
+
+	if limitUpper == math.MaxInt32 {
+		return protoreflect.ValueOfInt32(int32(v))
+	}
+
+	return protoreflect.ValueOfInt64(v)
+}
+
+// Returns the duration or timestamp in a value as a number of nanoseconds, or seconds for timestamps.
+func durationNanos(v protoreflect.Value) int64 {
+	seconds, nanos := secondsAndNanos(v.Message())
+	return seconds*int64(time.Second) + nanos
+}
+
+func timestampSeconds(v protoreflect.Value) int64 {
+	seconds, _ := secondsAndNanos(v.Message())
+	return seconds
+}
+
+// Returns the nanoseconds of a random duration that satisfies the rules.
+func (f *faker) duration(rules protoreflect.Message) int64 {
+	nanos := f.intInRange(rules, math.MinInt64, math.MaxInt64, int64(time.Second), int64(time.Hour), durationNanos)
+
+	// The durations are rounded to seconds when they remain valid
+	if rounded := nanos - nanos%int64(time.Second); rounded != nanos && f.valueIsDuration(rules, rounded) {
+		return rounded
+	}
+
+	return nanos
+}
+
+func (f *faker) valueIsDuration(rules protoreflect.Message, nanos int64) bool {
+	lower, upper, lowerName, upperName := rangeRules(rules)
+
+	if lowerName == "gt" && nanos <= durationNanos(lower) || lowerName == "gte" && nanos < durationNanos(lower) {
+		return false
+	}
+
+	return !(upperName == "lt" && nanos >= durationNanos(upper) || upperName == "lte" && nanos > durationNanos(upper))
+}
+
+// Returns the seconds of a random timestamp that satisfies the rules.
+func (f *faker) timestamp(rules protoreflect.Message) int64 {
+	now := f.now.Unix()
+	day := int64(24 * time.Hour / time.Second)
+
+	within, hasWithin := ruleValue(rules, "within")
+	span := 30 * day
+	if hasWithin {
+		span = max(durationNanos(within)/int64(time.Second)-1, 1)
+	}
+
+	offset := 1 + f.rand.Int64N(span)
+
+	switch {
+	case boolRule(rules, "gt_now"):
+		return now + offset
+	case boolRule(rules, "lt_now"):
+		return now - offset
+	case hasWithin:
+		return now - offset/2
+	}
+
+	return f.intInRange(rules, math.MinInt64/2, math.MaxInt64/2, now-365*day, 365*day, timestampSeconds)
+}
+
+// Returns the invalid variants for the rules of a message, each with the candidate mutations that may violate a single rule.
+func (f *faker) invalidMessage(desc protoreflect.MessageDescriptor, depth int) [][]func(protoreflect.Message) {
+	var rules [][]func(protoreflect.Message)
+
+	fields := desc.Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		for _, candidates := range f.invalidField(fd, depth) {
+			rules = append(rules, candidates)
+		}
+	}
+
+	oneofs := desc.Oneofs()
+	for i := range oneofs.Len() {
+		od := oneofs.Get(i)
+		if od.IsSynthetic() || !boolRule(optionExtension(od.Options(), "buf.validate.oneof"), "required") {
+			continue
+		}
+
+		rules = append(rules, []func(protoreflect.Message){func(m protoreflect.Message) {
+			if fd := m.WhichOneof(od); fd != nil {
+				m.Clear(fd)
+			}
+		}})
+	}
+
+	for _, rule := range listRule(optionExtension(desc.Options(), "buf.validate.message"), "oneof") {
+		var members []protoreflect.FieldDescriptor
+		for _, name := range listRule(rule.Message(), "fields") {
+			if fd := fields.ByName(protoreflect.Name(name.String())); fd != nil {
+				members = append(members, fd)
+			}
+		}
+
+		if len(members) > 1 {
+			rules = append(rules, []func(protoreflect.Message){func(m protoreflect.Message) {
+				for _, fd := range members[:2] {
+					if !m.Has(fd) {
+						f.fillField(m, fd, maxFakeDepth)
+					}
+				}
+			}})
+		}
+
+		if boolRule(rule.Message(), "required") {
+			rules = append(rules, []func(protoreflect.Message){func(m protoreflect.Message) {
+				for _, fd := range members {
+					m.Clear(fd)
+				}
+			}})
+		}
+	}
+
+	return rules
+}
+
+// Returns the candidate mutations for each rule of a field.
+func (f *faker) invalidField(fd protoreflect.FieldDescriptor, depth int) [][]func(protoreflect.Message) {
+	rules := f.rules.fieldRules(fd)
+
+	var invalid [][]func(protoreflect.Message)
+
+	if boolRule(rules, "required") {
+		invalid = append(invalid, []func(protoreflect.Message){func(m protoreflect.Message) { m.Clear(fd) }})
+	}
+
+	switch {
+	case fd.IsList():
+		invalid = append(invalid, f.invalidList(fd, rules, depth)...)
+	case fd.IsMap():
+		invalid = append(invalid, f.invalidMap(fd, rules, depth)...)
+	case fd.Message() != nil && kindRulesName(fd) == "message":
+		if depth >= maxFakeDepth {
+			break
+		}
+
+		for _, candidates := range f.invalidMessage(fd.Message(), depth+1) {
+			invalid = append(invalid, nestedMutations(candidates, func(m protoreflect.Message) protoreflect.Message {
+				if !m.Has(fd) {
+					f.fillField(m, fd, maxFakeDepth)
+				}
+				return m.Mutable(fd).Message()
+			}))
+		}
+	default:
+		newValue := func() protoreflect.Value { return protoreflect.ValueOfMessage(dynamicpb.NewMessage(fd.Message())) }
+
+		for _, values := range f.invalidValues(fd, rules, newValue) {
+			var candidates []func(protoreflect.Message)
+			for _, v := range values {
+				candidates = append(candidates, func(m protoreflect.Message) { m.Set(fd, v) })
+			}
+
+			invalid = append(invalid, candidates)
+		}
+	}
+
+	return invalid
+}
+
+// Wraps the mutations for a nested message, which is returned by the target function.
+func nestedMutations(candidates []func(protoreflect.Message), target func(protoreflect.Message) protoreflect.Message) []func(protoreflect.Message) {
+	nested := make([]func(protoreflect.Message), len(candidates))
+
+	for i, mutate := range candidates {
+		nested[i] = func(m protoreflect.Message) { mutate(target(m)) }
+	}
+
+	return nested
+}
+
+func (f *faker) invalidList(fd protoreflect.FieldDescriptor, rules protoreflect.Message, depth int) [][]func(protoreflect.Message) {
+	repeated := rulesFor(rules, "repeated")
+	items := rulesFor(repeated, "items")
+
+	var invalid [][]func(protoreflect.Message)
+
+	// Returns the list of the field, with at least the given number of items
+	withItems := func(m protoreflect.Message, count int) protoreflect.List {
+		list := m.Mutable(fd).List()
+		for attempts := 0; list.Len() < count && attempts < count*maxFakeAttempts; attempts++ {
+			list.Append(f.value(fd, items, list.NewElement, maxFakeDepth))
+		}
+		return list
+	}
+
+	if minItems, ok := uintRule(repeated, "min_items"); ok && minItems > 0 {
+		invalid = append(invalid, []func(protoreflect.Message){func(m protoreflect.Message) {
+			withItems(m, int(minItems)).Truncate(int(minItems) - 1)
+		}})
+	}
+
+	if maxItems, ok := uintRule(repeated, "max_items"); ok {
+		var candidates []func(protoreflect.Message)
+		for range 3 {
+			candidates = append(candidates, func(m protoreflect.Message) { withItems(m, int(maxItems)+1) })
+		}
+		invalid = append(invalid, candidates)
+	}
+
+	if boolRule(repeated, "unique") && fd.Message() == nil {
+		invalid = append(invalid, []func(protoreflect.Message){func(m protoreflect.Message) {
+			list := withItems(m, 1)
+			list.Append(list.Get(0))
+		}})
+	}
+
+	if fd.Message() != nil && kindRulesName(fd) == "message" {
+		if depth < maxFakeDepth {
+			for _, candidates := range f.invalidMessage(fd.Message(), depth+1) {
+				invalid = append(invalid, nestedMutations(candidates, func(m protoreflect.Message) protoreflect.Message {
+					return withItems(m, 1).Get(0).Message()
+				}))
+			}
+		}
+
+		return invalid
+	}
+
+	newValue := func() protoreflect.Value { return protoreflect.ValueOfMessage(dynamicpb.NewMessage(fd.Message())) }
+
+	for _, values := range f.invalidValues(fd, items, newValue) {
+		var candidates []func(protoreflect.Message)
+		for _, v := range values {
+			candidates = append(candidates, func(m protoreflect.Message) { withItems(m, 1).Set(0, v) })
+		}
+
+		invalid = append(invalid, candidates)
+	}
+
+	return invalid
+}
+
+func (f *faker) invalidMap(fd protoreflect.FieldDescriptor, rules protoreflect.Message, depth int) [][]func(protoreflect.Message) {
+	mapRules := rulesFor(rules, "map")
+	keys := rulesFor(mapRules, "keys")
+	values := rulesFor(mapRules, "values")
+
+	var invalid [][]func(protoreflect.Message)
+
+	// Returns the map of the field, with at least the given number of entries
+	withEntries := func(m protoreflect.Message, count int) protoreflect.Map {
+		entries := m.Mutable(fd).Map()
+		for attempts := 0; entries.Len() < count && attempts < count*maxFakeAttempts; attempts++ {
+			if key := f.value(fd.MapKey(), keys, nil, maxFakeDepth).MapKey(); !entries.Has(key) {
+				entries.Set(key, f.value(fd.MapValue(), values, entries.NewValue, maxFakeDepth))
+			}
+		}
+		return entries
+	}
+
+	// Returns the first key of the map, in the same order used for the violations
+	firstKey := func(entries protoreflect.Map) protoreflect.MapKey {
+		var keys []protoreflect.MapKey
+		entries.Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+			keys = append(keys, k)
+			return true
+		})
+
+		return slices.MinFunc(keys, func(a, b protoreflect.MapKey) int {
+			return cmp.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+		})
+	}
+
+	if minPairs, ok := uintRule(mapRules, "min_pairs"); ok && minPairs > 0 {
+		invalid = append(invalid, []func(protoreflect.Message){func(m protoreflect.Message) {
+			entries := withEntries(m, int(minPairs))
+			for entries.Len() >= int(minPairs) {
+				entries.Clear(firstKey(entries))
+			}
+		}})
+	}
+
+	if maxPairs, ok := uintRule(mapRules, "max_pairs"); ok {
+		invalid = append(invalid, []func(protoreflect.Message){func(m protoreflect.Message) { withEntries(m, int(maxPairs)+1) }})
+	}
+
+	for _, candidateKeys := range f.invalidValues(fd.MapKey(), keys, nil) {
+		var candidates []func(protoreflect.Message)
+		for _, key := range candidateKeys {
+			candidates = append(candidates, func(m protoreflect.Message) {
+				entries := withEntries(m, 1)
+				first := firstKey(entries)
+				value := entries.Get(first)
+				entries.Clear(first)
+				entries.Set(key.MapKey(), value)
+			})
+		}
+
+		invalid = append(invalid, candidates)
+	}
+
+	if fd.MapValue().Message() != nil && kindRulesName(fd.MapValue()) == "message" {
+		if depth < maxFakeDepth {
+			for _, candidates := range f.invalidMessage(fd.MapValue().Message(), depth+1) {
+				invalid = append(invalid, nestedMutations(candidates, func(m protoreflect.Message) protoreflect.Message {
+					entries := withEntries(m, 1)
+					return entries.Mutable(firstKey(entries)).Message()
+				}))
+			}
+		}
+
+		return invalid
+	}
+
+	newValue := func() protoreflect.Value {
+		return protoreflect.ValueOfMessage(dynamicpb.NewMessage(fd.MapValue().Message()))
+	}
+
+	for _, candidateValues := range f.invalidValues(fd.MapValue(), values, newValue) {
+		var candidates []func(protoreflect.Message)
+		for _, v := range candidateValues {
+			candidates = append(candidates, func(m protoreflect.Message) {
+				entries := withEntries(m, 1)
+				entries.Set(firstKey(entries), v)
+			})
+		}
+
+		invalid = append(invalid, candidates)
+	}
+
+	return invalid
+}
+
+// Returns the candidate values that violate each rule of a single value.
+func (f *faker) invalidValues(fd protoreflect.FieldDescriptor, rules protoreflect.Message, newValue func() protoreflect.Value) [][]protoreflect.Value {
+	name := kindRulesName(fd)
+	typed := rulesFor(rules, name)
+	if typed == nil {
+		return nil
+	}
+
+	var invalid [][]protoreflect.Value
+
+	switch name {
+	case "string":
+		for _, candidates := range f.invalidStrings(typed) {
+			var values []protoreflect.Value
+			for _, s := range candidates {
+				values = append(values, protoreflect.ValueOfString(s))
+			}
+			invalid = append(invalid, values)
+		}
+	case "bytes":
+		for _, candidates := range f.invalidBytes(typed) {
+			var values []protoreflect.Value
+			for _, b := range candidates {
+				values = append(values, protoreflect.ValueOfBytes(b))
+			}
+			invalid = append(invalid, values)
+		}
+	case "bool":
+		if v, ok := ruleValue(typed, "const"); ok {
+			invalid = append(invalid, []protoreflect.Value{protoreflect.ValueOfBool(!v.Bool())})
+		}
+	case "enum":
+		invalid = f.invalidEnums(fd.Enum(), typed)
+	case "timestamp", "duration":
+		unit := int64(time.Second)
+		toValue := func(n int64) protoreflect.Value {
+			v := newValue()
+			setSecondsAndNanos(v.Message(), n/int64(time.Second), n%int64(time.Second))
+			return v
+		}
+
+		if name == "timestamp" {
+			now := f.now.UnixNano()
+			hour := int64(time.Hour)
+
+			if boolRule(typed, "lt_now") {
+				invalid = append(invalid, []protoreflect.Value{toValue(now + hour)})
+			}
+
+			if boolRule(typed, "gt_now") {
+				invalid = append(invalid, []protoreflect.Value{toValue(now - hour)})
+			}
+
+			if within, ok := ruleValue(typed, "within"); ok {
+				invalid = append(invalid, []protoreflect.Value{toValue(now - 2*durationNanos(within) - hour), toValue(now + 2*durationNanos(within) + hour)})
+			}
+		} else {
+			unit = 1
+		}
+
+		for _, candidates := range invalidOrdered(typed, func(v protoreflect.Value) protoreflect.Value {
+			return protoreflect.ValueOfInt64(durationNanos(v))
+		}, func(v protoreflect.Value, delta int64) (protoreflect.Value, bool) {
+			return toValue(v.Int() + delta*unit), true
+		}) {
+			invalid = append(invalid, candidates)
+		}
+	case "any":
+		if in := listRule(typed, "in"); len(in) > 0 {
+			v := newValue()
+			v.Message().Set(v.Message().Descriptor().Fields().ByName("type_url"), protoreflect.ValueOfString("type.googleapis.com/invalid.Type"))
+			invalid = append(invalid, []protoreflect.Value{v})
+		}
+
+		if notIn := listRule(typed, "not_in"); len(notIn) > 0 {
+			v := newValue()
+			v.Message().Set(v.Message().Descriptor().Fields().ByName("type_url"), notIn[0])
+			invalid = append(invalid, []protoreflect.Value{v})
+		}
+	default:
+		invalid = invalidOrdered(typed, func(v protoreflect.Value) protoreflect.Value { return v }, func(v protoreflect.Value, delta int64) (protoreflect.Value, bool) {
+			return offsetNumber(fd.Kind(), v, delta)
+		})
+
+		if boolRule(typed, "finite") {
+			nan, inf := math.NaN(), math.Inf(1)
+			if fd.Kind() == protoreflect.FloatKind {
+				invalid = append(invalid, []protoreflect.Value{protoreflect.ValueOfFloat32(float32(nan)), protoreflect.ValueOfFloat32(float32(inf))})
+			} else {
+				invalid = append(invalid, []protoreflect.Value{protoreflect.ValueOfFloat64(nan), protoreflect.ValueOfFloat64(inf)})
+			}
+		}
+	}
+
+	return invalid
+}
+
+// Returns the candidate values that violate the const, in, not_in and range rules of numbers, durations and timestamps. The values of the rules are normalized with the normalize function, and offset returns the value that is moved by delta units (or false if it would overflow).
+func invalidOrdered(rules protoreflect.Message, normalize func(protoreflect.Value) protoreflect.Value, offset func(v protoreflect.Value, delta int64) (protoreflect.Value, bool)) [][]protoreflect.Value {
+	var invalid [][]protoreflect.Value
+
+	// Collects the values that can be computed without overflowing
+	candidates := func(base protoreflect.Value, deltas ...int64) []protoreflect.Value {
+		var values []protoreflect.Value
+		for _, delta := range deltas {
+			if v, ok := offset(normalize(base), delta); ok {
+				values = append(values, v)
+			}
+		}
+		return values
+	}
+
+	if v, ok := ruleValue(rules, "const"); ok {
+		invalid = append(invalid, candidates(v, 1, -1))
+	}
+
+	if in := listRule(rules, "in"); len(in) > 0 {
+		largest := slices.MaxFunc(in, func(a, b protoreflect.Value) int {
+			c, _ := compareOrdered(normalize(a), normalize(b))
+			return c
+		})
+		smallest := slices.MinFunc(in, func(a, b protoreflect.Value) int {
+			c, _ := compareOrdered(normalize(a), normalize(b))
+			return c
+		})
+		invalid = append(invalid, append(candidates(largest, 1), candidates(smallest, -1)...))
+	}
+
+	if notIn := listRule(rules, "not_in"); len(notIn) > 0 {
+		invalid = append(invalid, candidates(notIn[0], 0))
+	}
+
+	deltas := map[protoreflect.Name]int64{"gt": 0, "gte": -1, "lt": 0, "lte": 1}
+	for _, name := range []protoreflect.Name{"gt", "gte", "lt", "lte"} {
+		if v, ok := ruleValue(rules, name); ok {
+			invalid = append(invalid, candidates(v, deltas[name]))
+		}
+	}
+
+	return invalid
+}
+
+// Returns a number moved by the given delta (or to the next representable value, for floats), and false if it would overflow.
+func offsetNumber(kind protoreflect.Kind, v protoreflect.Value, delta int64) (protoreflect.Value, bool) {
+	switch kind {
+	case protoreflect.FloatKind:
+		f := float32(v.Float())
+		for range max(delta, -delta) {
+			f = math.Nextafter32(f, float32(math.Inf(int(delta))))
+		}
+		return protoreflect.ValueOfFloat32(f), true
+	case protoreflect.DoubleKind:
+		f := v.Float()
+		for range max(delta, -delta) {
+			f = math.Nextafter(f, math.Inf(int(delta)))
+		}
+		return protoreflect.ValueOfFloat64(f), true
+	case protoreflect.Uint32Kind, protoreflect.Uint64Kind, protoreflect.Fixed32Kind, protoreflect.Fixed64Kind:
+		n := v.Uint()
+		if (delta < 0 && n < uint64(-delta)) || (delta > 0 && n > uintLimit(kind)-uint64(delta)) {
+			return protoreflect.Value{}, false
+		}
+
+		n = uint64(int64(n) + delta)
+		if uintLimit(kind) == math.MaxUint32 {
+			return protoreflect.ValueOfUint32(uint32(n)), true
+		}
+		return protoreflect.ValueOfUint64(n), true
+	}
+
+	lower, upper := intLimits(kind)
+	n := v.Int()
+	if (delta < 0 && n < lower-delta) || (delta > 0 && n > upper-delta) {
+		return protoreflect.Value{}, false
+	}
+
+	if upper == math.MaxInt32 {
+		return protoreflect.ValueOfInt32(int32(n + delta)), true
+	}
+
+	return protoreflect.ValueOfInt64(n + delta), true
+}
+
+func (f *faker) invalidEnums(desc protoreflect.EnumDescriptor, rules protoreflect.Message) [][]protoreflect.Value {
+	values := desc.Values()
+	var defined []protoreflect.EnumNumber
+	for i := range values.Len() {
+		defined = append(defined, values.Get(i).Number())
+	}
+
+	undefined := slices.Max(defined) + 1
+
+	// Returns the defined values that are not in the list, followed by an undefined value
+	except := func(list []protoreflect.Value) []protoreflect.Value {
+		var candidates []protoreflect.Value
+		for _, n := range defined {
+			if !containsValue(list, protoreflect.ValueOfInt32(int32(n))) {
+				candidates = append(candidates, protoreflect.ValueOfEnum(n))
+			}
+		}
+		return append(candidates, protoreflect.ValueOfEnum(undefined))
+	}
+
+	var invalid [][]protoreflect.Value
+
+	if v, ok := ruleValue(rules, "const"); ok {
+		invalid = append(invalid, except([]protoreflect.Value{v}))
+	}
+
+	if in := listRule(rules, "in"); len(in) > 0 {
+		invalid = append(invalid, except(in))
+	}
+
+	if notIn := listRule(rules, "not_in"); len(notIn) > 0 {
+		invalid = append(invalid, []protoreflect.Value{protoreflect.ValueOfEnum(protoreflect.EnumNumber(notIn[0].Int()))})
+	}
+
+	if boolRule(rules, "defined_only") {
+		invalid = append(invalid, []protoreflect.Value{protoreflect.ValueOfEnum(undefined)})
+	}
+
+	return invalid
+}
+
+// Returns the candidate strings that violate the length rules, with the names of the rules for the exact, minimum and maximum lengths.
+func invalidLengths(rules protoreflect.Message, exact, minName, maxName protoreflect.Name) [][]string {
+	var invalid [][]string
+
+	if n, ok := uintRule(rules, exact); ok {
+		candidates := []string{strings.Repeat("a", int(n)+1)}
+		if n > 0 {
+			candidates = append(candidates, strings.Repeat("a", int(n)-1))
+		}
+		invalid = append(invalid, candidates)
+	}
+
+	if n, ok := uintRule(rules, minName); ok && n > 0 {
+		invalid = append(invalid, []string{strings.Repeat("a", int(n)-1)})
+	}
+
+	if n, ok := uintRule(rules, maxName); ok {
+		invalid = append(invalid, []string{strings.Repeat("a", int(n)+1)})
+	}
+
+	return invalid
+}
+
+// The values that are used to violate the formats and patterns of strings and bytes.
+var invalidFormatCandidates = []string{"", "!", "not valid!", "%zz", "-invalid-", "999.999.999.999", " ", "0"}
+
+func (f *faker) invalidStrings(rules protoreflect.Message) [][]string {
+	valid := f.string(rules)
+
+	var invalid [][]string
+
+	if v, ok := ruleValue(rules, "const"); ok {
+		invalid = append(invalid, []string{v.String() + "x", ""})
+	}
+
+	invalid = append(invalid, invalidLengths(rules, "len", "min_len", "max_len")...)
+	invalid = append(invalid, invalidLengths(rules, "len_bytes", "min_bytes", "max_bytes")...)
+
+	if v, ok := ruleValue(rules, "pattern"); ok {
+		if re, err := regexp.Compile(v.String()); err == nil {
+			var candidates []string
+			for _, s := range append([]string{valid + "!", "!" + valid}, invalidFormatCandidates...) {
+				if !re.MatchString(s) {
+					candidates = append(candidates, s)
+				}
+			}
+			invalid = append(invalid, candidates)
+		}
+	}
+
+	if v, ok := ruleValue(rules, "prefix"); ok && v.String() != "" {
+		invalid = append(invalid, []string{strings.Replace(valid, v.String(), strings.Repeat("z", len(v.String())), 1)})
+	}
+
+	if v, ok := ruleValue(rules, "suffix"); ok && v.String() != "" {
+		trimmed := strings.TrimSuffix(valid, v.String())
+		invalid = append(invalid, []string{trimmed + strings.Repeat("z", len(v.String()))})
+	}
+
+	if v, ok := ruleValue(rules, "contains"); ok && v.String() != "" {
+		invalid = append(invalid, []string{strings.ReplaceAll(valid, v.String(), strings.Repeat("z", len(v.String())))})
+	}
+
+	if v, ok := ruleValue(rules, "not_contains"); ok && v.String() != "" {
+		candidates := []string{v.String()}
+		if len(valid) > len(v.String()) {
+			candidates = append([]string{v.String() + valid[len(v.String()):]}, candidates...)
+		}
+		invalid = append(invalid, candidates)
+	}
+
+	if in := listRule(rules, "in"); len(in) > 0 {
+		invalid = append(invalid, []string{in[0].String() + "x", "invalid"})
+	}
+
+	if notIn := listRule(rules, "not_in"); len(notIn) > 0 {
+		invalid = append(invalid, []string{notIn[0].String()})
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(stringFormats)) {
+		if !boolRule(rules, protoreflect.Name(name)) {
+			continue
+		}
+
+		format := stringFormats[name]
+
+		var candidates []string
+		for _, s := range invalidFormatCandidates {
+			if s != "" && !format.check(s) {
+				candidates = append(candidates, s)
+			}
+		}
+
+		// The empty value is reported with a different rule id
+		invalid = append(invalid, candidates, []string{""})
+	}
+
+	if _, ok := ruleValue(rules, "well_known_regex"); ok {
+		// The first value is an invalid header name, and the second is an invalid header value
+		invalid = append(invalid, []string{"invalid header!", "a\x01b", ""})
+	}
+
+	return invalid
+}
+
+func (f *faker) invalidBytes(rules protoreflect.Message) [][][]byte {
+	valid := f.bytes(rules)
+
+	var invalid [][][]byte
+
+	if v, ok := ruleValue(rules, "const"); ok {
+		invalid = append(invalid, [][]byte{append(slices.Clone(v.Bytes()), 'x'), nil})
+	}
+
+	for _, candidates := range invalidLengths(rules, "len", "min_len", "max_len") {
+		var values [][]byte
+		for _, s := range candidates {
+			values = append(values, []byte(s))
+		}
+		invalid = append(invalid, values)
+	}
+
+	if v, ok := ruleValue(rules, "pattern"); ok {
+		if re, err := regexp.Compile(v.String()); err == nil {
+			candidates := [][]byte{{0xff}}
+			for _, s := range invalidFormatCandidates {
+				if !re.MatchString(s) {
+					candidates = append(candidates, []byte(s))
+				}
+			}
+			invalid = append(invalid, candidates)
+		}
+	}
+
+	for _, name := range []protoreflect.Name{"prefix", "suffix", "contains"} {
+		if v, ok := ruleValue(rules, name); ok && len(v.Bytes()) > 0 {
+			replaced := []byte(strings.ReplaceAll(string(valid), string(v.Bytes()), strings.Repeat("z", len(v.Bytes()))))
+			invalid = append(invalid, [][]byte{replaced})
+		}
+	}
+
+	if in := listRule(rules, "in"); len(in) > 0 {
+		invalid = append(invalid, [][]byte{append(slices.Clone(in[0].Bytes()), 'x'), []byte("invalid")})
+	}
+
+	if notIn := listRule(rules, "not_in"); len(notIn) > 0 {
+		invalid = append(invalid, [][]byte{notIn[0].Bytes()})
+	}
+
+	ipLengths := map[protoreflect.Name]int{"ip": 3, "ipv4": 16, "ipv6": 4}
+	for _, name := range []protoreflect.Name{"ip", "ipv4", "ipv6"} {
+		if boolRule(rules, name) {
+			invalid = append(invalid, [][]byte{make([]byte, ipLengths[name])}, [][]byte{nil})
+		}
+	}
+
+	return invalid
+}
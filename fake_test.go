@@ -0,0 +1,145 @@
+package protoschema_test
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFake(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:               "fake.v1",
+		GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/fakev1",
+		ProtoRoot:          tmpDir,
+		ConverterOutputDir: filepath.Join(tmpDir, "converter"),
+	})
+
+	file := pkg.NewFile(sb.FileSchema{Name: "user"})
+
+	status := file.NewEnum(sb.EnumGroup{
+		Name:   "Status",
+		Values: []sb.EnumValue{{Name: "STATUS_UNSPECIFIED", Number: 0}, {Name: "STATUS_ACTIVE", Number: 1}, {Name: "STATUS_BANNED", Number: 2}},
+	})
+
+	user := file.NewMessage(sb.MessageSchema{Name: "User"})
+	address := user.NestedMessage(sb.MessageSchema{
+		Name:   "Address",
+		Fields: sb.FieldsMap{1: sb.String("city").MinLen(2).MaxLen(20), 2: sb.String("zip").Pattern(`^[0-9]{5}$`)},
+	})
+
+	user.Fields = sb.FieldsMap{
+		1:  sb.String("id").UUID(),
+		2:  sb.String("email").Email(),
+		3:  sb.String("name").MinLen(3).MaxLen(10),
+		4:  sb.Int32("age").Gte(18).Lt(120),
+		5:  sb.String("role").In("admin", "member"),
+		6:  sb.EnumField("status", status).DefinedOnly().NotIn(2),
+		7:  sb.Repeated("tags", sb.String("").MinLen(2)).MinItems(2).MaxItems(4).Unique(),
+		8:  sb.Map("scores", sb.String("").MinLen(1), sb.Double("").Gte(0).Lte(1)).MinPairs(1),
+		9:  sb.MsgField("address", address).Required(),
+		10: sb.Timestamp("last_login").LtNow(),
+		11: sb.String("website").URI().Optional(),
+		12: sb.String("host").Hostname(),
+		13: sb.String("ip").Ip(),
+		14: sb.Bool("verified").Const(true),
+	}
+
+	user.NewOneof(sb.OneofGroup{
+		Name:     "contact",
+		Required: true,
+		Fields:   sb.OneofFields{20: sb.String("phone").MinLen(8), 21: sb.String("backup_email").Email()},
+	})
+
+	msg := user.Fake(1)
+	assert.NoError(t, sb.Validate(msg))
+
+	// The same seed produces the same message
+	first, _ := user.FakeJSON(1)
+	same, _ := user.FakeJSON(1)
+	other, _ := user.FakeJSON(2)
+	assert.Equal(t, string(first), string(same))
+	assert.NotEqual(t, string(first), string(other))
+
+	for seed := range int64(20) {
+		assert.NoError(t, sb.Validate(user.Fake(seed)), "seed %d", seed)
+	}
+
+	jsonData, err := user.FakeJSON(1)
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(jsonData), `"address":`)
+	}
+
+	invalid := user.FakeInvalid(1)
+
+	rules := make(map[string]bool)
+	for _, fake := range invalid {
+		err := sb.Validate(fake.Message)
+
+		var validationErr *sb.ValidationError
+		if assert.ErrorAs(t, err, &validationErr) && assert.Len(t, validationErr.Violations, 1, fake.RuleId) {
+			assert.Equal(t, fake.RuleId, validationErr.Violations[0].GetRuleId())
+		}
+
+		rules[fake.Field+" "+fake.RuleId] = true
+	}
+
+	for _, expected := range []string{
+		"id string.uuid",
+		"id string.uuid_empty",
+		"email string.email",
+		"name string.min_len",
+		"name string.max_len",
+		"age int32.gte_lt",
+		"role string.in",
+		"status enum.defined_only",
+		"status enum.not_in",
+		"tags repeated.min_items",
+		"tags repeated.max_items",
+		"tags repeated.unique",
+		"tags[0] string.min_len",
+		"scores map.min_pairs",
+		"address required",
+		"address.city string.min_len",
+		"address.zip string.pattern",
+		"last_login timestamp.lt_now",
+		"website string.uri",
+		"verified bool.const",
+		"contact required",
+	} {
+		assert.True(t, rules[expected], "missing invalid variant %q", expected)
+	}
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, pkg.EmitFakes(&buf, "fakes", 1)) {
+		return
+	}
+
+	source := buf.String()
+
+	_, err = parser.ParseFile(token.NewFileSet(), "fakes.go", source, 0)
+	assert.NoError(t, err)
+
+	for _, expected := range []string{
+		"package fakes",
+		`"github.com/Rick-Phoenix/protoschema/gen/fakev1"`,
+		"func FakeUser() *fakev1.User {",
+		"func FakeUserAddress() *fakev1.User_Address {",
+		"Address: &fakev1.User_Address{City: ",
+		"Status: fakev1.Status(1)",
+		"Tags: []string{",
+		"Scores: map[string]float64{",
+		"LastLogin: timestamppb.New(time.Now().Add(-",
+		"Website: proto.String(",
+		"Verified: true",
+		"Contact: &fakev1.User_",
+	} {
+		assert.Contains(t, source, expected)
+	}
+}
@@ -0,0 +1,385 @@
+package protoschema
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"maps"
+	"math"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Writes a go file (in the package with the given name) with a FakeX function for each message of this package, such as FakePost or FakePostLocation for a nested message, which returns an instance of the generated type with the values created by Fake.
+// The values are generated once with the given seed, so they are written as literals, except for the timestamps with rules relative to the current time (such as GtNow, LtNow and Within), which are computed when the functions are called so that they remain valid.
+func (p *ProtoPackage) EmitFakes(w io.Writer, packageName string, seed int64) error {
+	filesData, err := p.TryBuildFiles()
+	if err != nil {
+		return err
+	}
+
+	return p.EmitFakesFromFiles(w, filesData, packageName, seed)
+}
+
+// Writes the fakes of EmitFakes for the data returned by TryBuildFiles, so that the schemas are not processed again.
+func (p *ProtoPackage) EmitFakesFromFiles(w io.Writer, filesData []FileData, packageName string, seed int64) error {
+	files, err := p.compileFiles(filesData)
+	if err != nil {
+		return err
+	}
+
+	f := newFaker(seed)
+	src := &fakeSource{imports: make(map[string]string), now: f.now}
+
+	var addMessages func(messages protoreflect.MessageDescriptors)
+	addMessages = func(messages protoreflect.MessageDescriptors) {
+		for i := range messages.Len() {
+			desc := messages.Get(i)
+			if desc.IsMapEntry() {
+				continue
+			}
+
+			src.function(desc, f.message(desc))
+			addMessages(desc.Messages())
+		}
+	}
+
+	for _, file := range files {
+		addMessages(file.Messages())
+	}
+
+	formatted, err := src.source(packageName)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(formatted)
+	return err
+}
+
+// The source of the fake functions, with the aliases of the go packages that it imports.
+type fakeSource struct {
+	body    strings.Builder
+	imports map[string]string
+	// The time used to generate the values, from which the offsets of the timestamps relative to the current time are computed
+	now time.Time
+	// The rules used instead of those of the compiled fields, if defined
+	rules fieldRulesOverride
+	err   error
+}
+
+// Returns the formatted source of a go file in the package with the given name, with the imports and the body.
+func (s *fakeSource) source(packageName string) ([]byte, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "// %s\n\npackage %s\n\nimport (\n", GeneratedHeader, packageName)
+
+	// The imports of the standard library are in a separate group, as with goimports
+	group := func(importPath string) int {
+		if domain, _, _ := strings.Cut(importPath, "/"); strings.Contains(domain, ".") {
+			return 1
+		}
+		return 0
+	}
+
+	importPaths := slices.SortedFunc(maps.Keys(s.imports), func(a, b string) int {
+		return cmp.Or(cmp.Compare(group(a), group(b)), cmp.Compare(a, b))
+	})
+
+	for i, importPath := range importPaths {
+		if i > 0 && group(importPath) != group(importPaths[i-1]) {
+			out.WriteString("\n")
+		}
+
+		alias := s.imports[importPath]
+		if alias == path.Base(importPath) {
+			fmt.Fprintf(&out, "\t%q\n", importPath)
+		} else {
+			fmt.Fprintf(&out, "\t%s %q\n", alias, importPath)
+		}
+	}
+
+	out.WriteString(")\n")
+	out.WriteString(s.body.String())
+
+	formatted, err := format.Source([]byte(out.String()))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to format the generated go source: %w", err)
+	}
+
+	return formatted, nil
+}
+
+func (s *fakeSource) importPackage(importPath string) string {
+	if alias, exists := s.imports[importPath]; exists {
+		return alias
+	}
+
+	base := strings.NewReplacer("-", "", ".", "").Replace(path.Base(importPath))
+	alias := base
+
+	for i := 2; slices.Contains(slices.Collect(maps.Values(s.imports)), alias); i++ {
+		alias = base + strconv.Itoa(i)
+	}
+
+	s.imports[importPath] = alias
+	return alias
+}
+
+// Returns the name of the generated go type for a message or enum, qualified with the alias of its package.
+func (s *fakeSource) typeName(desc protoreflect.Descriptor) string {
+	return s.qualify(desc, goIdentName(desc))
+}
+
+// Qualifies the name of a go type with the alias of the go package of the file that contains the descriptor.
+func (s *fakeSource) qualify(desc protoreflect.Descriptor, name string) string {
+	file := desc.ParentFile()
+	opts, _ := file.Options().(*descriptorpb.FileOptions)
+	goPackage := opts.GetGoPackage()
+
+	if goPackage == "" {
+		s.err = errors.Join(s.err, fmt.Errorf("The file %q has no go_package option, so the go type for %q cannot be determined.", file.Path(), desc.FullName()))
+		return "invalid"
+	}
+
+	importPath, packageName, hasName := strings.Cut(goPackage, ";")
+	alias := s.importPackage(importPath)
+
+	if hasName && alias == path.Base(importPath) && packageName != alias {
+		s.imports[importPath] = packageName
+		alias = packageName
+	}
+
+	return alias + "." + name
+}
+
+// Returns the name of the go type generated by protoc-gen-go for a message or enum, such as Post_Location for a nested message.
+func goIdentName(desc protoreflect.Descriptor) string {
+	name := strings.TrimPrefix(string(desc.FullName()), string(desc.ParentFile().Package())+".")
+	return goCamelCase(name)
+}
+
+// Converts a name to the format used by protoc-gen-go for the names of types and fields.
+func goCamelCase(s string) string {
+	isLower := func(c byte) bool { return 'a' <= c && c <= 'z' }
+	isDigit := func(c byte) bool { return '0' <= c && c <= '9' }
+
+	var b []byte
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch {
+		case c == '.' && i+1 < len(s) && isLower(s[i+1]):
+			// The dot is dropped before a lowercase letter
+		case c == '.':
+			b = append(b, '_')
+		case c == '_' && (i == 0 || s[i-1] == '.'):
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isLower(s[i+1]):
+			// The underscore is dropped before a lowercase letter
+		case isDigit(c):
+			b = append(b, c)
+		default:
+			if isLower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+
+			for ; i+1 < len(s) && isLower(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+
+	return string(b)
+}
+
+// Returns the name of the wrapper type for a field of a oneof, adding an underscore if it conflicts with a nested message or enum, as protoc-gen-go does.
+func oneofWrapperName(fd protoreflect.FieldDescriptor) string {
+	parent := fd.ContainingMessage()
+	name := goIdentName(parent) + "_" + goCamelCase(string(fd.Name()))
+
+	for i := range parent.Messages().Len() {
+		if goIdentName(parent.Messages().Get(i)) == name {
+			return name + "_"
+		}
+	}
+
+	for i := range parent.Enums().Len() {
+		if goIdentName(parent.Enums().Get(i)) == name {
+			return name + "_"
+		}
+	}
+
+	return name
+}
+
+func (s *fakeSource) function(desc protoreflect.MessageDescriptor, msg protoreflect.Message) {
+	goName := goIdentName(desc)
+	funcName := "Fake" + strings.ReplaceAll(goName, "_", "")
+
+	fmt.Fprintf(&s.body, "\n// Returns a %s with values that satisfy its rules.\nfunc %s() *%s {\n\treturn %s\n}\n", desc.Name(), funcName, s.typeName(desc), s.message(msg))
+}
+
+func (s *fakeSource) message(m protoreflect.Message) string {
+	desc := m.Descriptor()
+
+	var fields []string
+
+	for i := range desc.Fields().Len() {
+		fd := desc.Fields().Get(i)
+		if !m.Has(fd) {
+			continue
+		}
+
+		value := s.field(fd, m.Get(fd))
+
+		if od := fd.ContainingOneof(); od != nil && !od.IsSynthetic() {
+			value = fmt.Sprintf("&%s{%s: %s}", s.qualify(desc, oneofWrapperName(fd)), goCamelCase(string(fd.Name())), value)
+			fields = append(fields, goCamelCase(string(od.Name()))+": "+value)
+			continue
+		}
+
+		fields = append(fields, goCamelCase(string(fd.Name()))+": "+value)
+	}
+
+	return "&" + s.typeName(desc) + "{" + strings.Join(fields, ", ") + "}"
+}
+
+// Returns the go expression for the value of a field.
+func (s *fakeSource) field(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch {
+	case fd.IsList():
+		list := v.List()
+		items := make([]string, list.Len())
+		for i := range list.Len() {
+			items[i] = s.value(fd, list.Get(i), rulesFor(rulesFor(s.rules.fieldRules(fd), "repeated"), "items"))
+		}
+
+		return "[]" + s.goType(fd) + "{" + strings.Join(items, ", ") + "}"
+	case fd.IsMap():
+		entries := v.Map()
+		var keys []protoreflect.MapKey
+		entries.Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+			keys = append(keys, k)
+			return true
+		})
+
+		slices.SortFunc(keys, func(a, b protoreflect.MapKey) int {
+			return strings.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+		})
+
+		values := rulesFor(rulesFor(s.rules.fieldRules(fd), "map"), "values")
+		items := make([]string, len(keys))
+		for i, k := range keys {
+			items[i] = s.value(fd.MapKey(), k.Value(), nil) + ": " + s.value(fd.MapValue(), entries.Get(k), values)
+		}
+
+		return "map[" + s.goType(fd.MapKey()) + "]" + s.goType(fd.MapValue()) + "{" + strings.Join(items, ", ") + "}"
+	}
+
+	value := s.value(fd, v, s.rules.fieldRules(fd))
+
+	// The scalar fields with explicit presence (outside of oneofs) are pointers
+	if fd.HasPresence() && fd.Message() == nil && fd.Kind() != protoreflect.BytesKind && (fd.ContainingOneof() == nil || fd.ContainingOneof().IsSynthetic()) {
+		if fd.Kind() == protoreflect.EnumKind {
+			return value + ".Enum()"
+		}
+
+		helper := map[string]string{"int32": "Int32", "int64": "Int64", "uint32": "Uint32", "uint64": "Uint64", "float32": "Float32", "float64": "Float64", "bool": "Bool", "string": "String"}[s.goType(fd)]
+		return s.importPackage("google.golang.org/protobuf/proto") + "." + helper + "(" + value + ")"
+	}
+
+	return value
+}
+
+// Returns the go type of a single value of a field.
+func (s *fakeSource) goType(fd protoreflect.FieldDescriptor) string {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return "*" + s.typeName(fd.Message())
+	case protoreflect.EnumKind:
+		return s.typeName(fd.Enum())
+	case protoreflect.BytesKind:
+		return "[]byte"
+	case protoreflect.BoolKind:
+		return "bool"
+	case protoreflect.StringKind:
+		return "string"
+	case protoreflect.FloatKind:
+		return "float32"
+	case protoreflect.DoubleKind:
+		return "float64"
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return "int32"
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return "uint32"
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return "uint64"
+	}
+
+	return "int64"
+}
+
+// Returns the go expression for a single value. The rules are used to write the timestamps relative to the current time.
+func (s *fakeSource) value(fd protoreflect.FieldDescriptor, v protoreflect.Value, rules protoreflect.Message) string {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		m := v.Message()
+
+		if m.Descriptor().FullName() == "google.protobuf.Timestamp" {
+			timestamp := rulesFor(rules, "timestamp")
+			if boolRule(timestamp, "gt_now") || boolRule(timestamp, "lt_now") || hasRule(timestamp, "within") {
+				seconds, _ := secondsAndNanos(m)
+				offset := seconds - s.now.Unix()
+				return fmt.Sprintf("%s.New(%s.Now().Add(%d * %s.Second))", s.importPackage("google.golang.org/protobuf/types/known/timestamppb"), s.importPackage("time"), offset, s.importPackage("time"))
+			}
+		}
+
+		return s.message(m)
+	case protoreflect.EnumKind:
+		return fmt.Sprintf("%s(%d)", s.typeName(fd.Enum()), v.Enum())
+	case protoreflect.StringKind:
+		return strconv.Quote(v.String())
+	case protoreflect.BytesKind:
+		return fmt.Sprintf("[]byte(%q)", v.Bytes())
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return s.float(v.Float(), fd.Kind() == protoreflect.FloatKind)
+	}
+
+	return fmt.Sprint(v.Interface())
+}
+
+// Returns the go expression for a float, using the functions of the math package for NaN and the infinities (which are used by the invalid variants).
+func (s *fakeSource) float(f float64, is32 bool) string {
+	var expr string
+
+	switch {
+	case math.IsNaN(f):
+		expr = s.importPackage("math") + ".NaN()"
+	case math.IsInf(f, 0):
+		expr = fmt.Sprintf("%s.Inf(%d)", s.importPackage("math"), int(math.Copysign(1, f)))
+	case is32:
+		return strconv.FormatFloat(f, 'g', -1, 32)
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+
+	if is32 {
+		return "float32(" + expr + ")"
+	}
+
+	return expr
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"reflect"
 	"slices"
 )
 
@@ -24,6 +25,19 @@ type FieldData struct {
 	IsNonScalar   bool
 	MessageRef    *MessageSchema
 	EnumRef       *EnumGroup
+	// The default value for this field (proto2 only).
+	Default any
+	// Whether the field has the proto2 required label.
+	LabelRequired bool
+	// The features for this field (editions only).
+	Features Features
+	// The comments for this field.
+	Doc             string
+	TrailingComment string
+	// The options of this field that use extensions (with names in parentheses). They are checked against the extensions declared in the package.
+	ExtensionOptions []ProtoOption
+	// The label rendered before the field's type ("optional", "required", "repeated" or an empty string). It is set when the parent message is processed, depending on the syntax of its file.
+	Label string
 }
 
 type protoFieldInternal struct {
@@ -31,6 +45,7 @@ type protoFieldInternal struct {
 	rules           map[string]any
 	options         map[string]any
 	repeatedOptions []string
+	celRules        []celRule
 	optional        bool
 	imports         []string
 	protoType       string
@@ -44,6 +59,11 @@ type protoFieldInternal struct {
 	isConst         bool
 	messageRef      *MessageSchema
 	enumRef         *EnumGroup
+	defaultValue    any
+	labelRequired   bool
+	features        Features
+	doc             string
+	trailingComment string
 }
 
 // The FieldBuilder interface, which is implemented by the various field constructors.
@@ -83,13 +103,19 @@ func (b *protoFieldInternal) GetName() string {
 	return b.name
 }
 
+func (b *protoFieldInternal) fieldInternal() *protoFieldInternal {
+	return b
+}
+
 // Returns the internal data of the FieldBuilder instance. Mostly for internal use.
 func (b *protoFieldInternal) GetData() FieldData {
 	return FieldData{
 		Name: b.name, ProtoType: b.protoType, ProtoBaseType: b.protoBaseType, Rules: maps.Clone(b.rules),
 		Imports:  slices.Clone(b.imports),
 		Repeated: b.repeated, Required: b.required, IsNonScalar: b.isNonScalar, Optional: b.optional,
-		GoType: b.goType, IsMap: b.isMap, MessageRef: b.messageRef,
+		GoType: b.goType, IsMap: b.isMap, MessageRef: b.messageRef, EnumRef: b.enumRef,
+		Default: b.defaultValue, LabelRequired: b.labelRequired, Features: b.features,
+		Doc: b.doc, TrailingComment: b.trailingComment,
 	}
 }
 
@@ -98,7 +124,9 @@ func (b *protoFieldInternal) Build(fieldNr uint32, imports Set) (FieldData, erro
 	data := FieldData{
 		Name: b.name, ProtoType: b.protoType, GoType: b.goType, FieldNr: fieldNr,
 		Rules: b.rules, IsNonScalar: b.isNonScalar, Optional: b.optional, ProtoBaseType: b.protoBaseType, IsMap: b.isMap,
-		MessageRef: b.messageRef,
+		MessageRef: b.messageRef, EnumRef: b.enumRef, Default: b.defaultValue, LabelRequired: b.labelRequired,
+		Features: b.features, ExtensionOptions: extensionOptions(b.options),
+		Doc: b.doc, TrailingComment: b.trailingComment,
 	}
 
 	if data.ProtoBaseType == "" {
@@ -114,8 +142,8 @@ func (b *protoFieldInternal) Build(fieldNr uint32, imports Set) (FieldData, erro
 		imports[v] = present
 	}
 
-	options := make([]string, len(b.repeatedOptions))
-	copy(options, b.repeatedOptions)
+	options, celErr := b.buildRepeatedOptions()
+	errAgg = errors.Join(errAgg, celErr)
 
 	optsCollector := make(map[string]any)
 	maps.Copy(optsCollector, b.options)
@@ -129,11 +157,34 @@ func (b *protoFieldInternal) Build(fieldNr uint32, imports Set) (FieldData, erro
 		}
 	}
 
+	if b.defaultValue != nil {
+		if b.isNonScalar {
+			errAgg = errors.Join(errAgg, fmt.Errorf("Only scalar fields can have a default value."))
+		}
+
+		optsCollector["default"] = b.defaultValue
+	}
+
+	errAgg = errors.Join(errAgg, checkFieldFeatureTypes(data))
+
 	if len(b.rules) > 0 {
 		imports["buf/validate/validate.proto"] = present
 
-		for rule, value := range b.rules {
-			optsCollector[fmt.Sprintf("(buf.validate.field).%s.%s", data.ProtoBaseType, rule)] = value
+		for _, rule := range slices.Sorted(maps.Keys(b.rules)) {
+			name := fmt.Sprintf("(buf.validate.field).%s.%s", data.ProtoBaseType, rule)
+			value := reflect.ValueOf(b.rules[rule])
+
+			// Lists cannot be used as option values, so the rules with multiple values (such as in) are set once for each value
+			if value.Kind() != reflect.Slice || value.Type().Elem().Kind() == reflect.Uint8 {
+				optsCollector[name] = b.rules[rule]
+				continue
+			}
+
+			for i := range value.Len() {
+				opt, err := getProtoOption(name, value.Index(i).Interface())
+				errAgg = errors.Join(errAgg, err)
+				options = append(options, opt)
+			}
 		}
 	}
 
@@ -69,3 +69,38 @@ func (b *ProtoField[BuilderT]) Required() *BuilderT {
 	b.required = true
 	return b.self
 }
+
+// Sets the default value for this field. Only available for scalar fields in proto2 files.
+func (b *ProtoField[BuilderT]) Default(val any) *BuilderT {
+	b.defaultValue = val
+	return b.self
+}
+
+// Marks the field with the required label. Only available in proto2 files.
+// This is different from Required, which adds the protovalidate rule instead.
+func (b *ProtoField[BuilderT]) RequiredLabel() *BuilderT {
+	b.labelRequired = true
+	return b.self
+}
+
+// Sets the features for this field. Only available in files that use editions.
+// The field_presence, repeated_field_encoding and utf8_validation features can be set on fields. The optional keyword and the required label are converted to the equivalent field presence automatically.
+func (b *ProtoField[BuilderT]) Features(f Features) *BuilderT {
+	b.features = f
+	for _, o := range f.options() {
+		b.options[o.Name] = o.Value
+	}
+	return b.self
+}
+
+// Sets the comment that is rendered above this field in the proto file, and that is used as its leading comment in the descriptor's SourceCodeInfo.
+func (b *ProtoField[BuilderT]) Doc(doc string) *BuilderT {
+	b.doc = doc
+	return b.self
+}
+
+// Sets the comment that is rendered on the same line as this field. Line breaks are replaced with spaces.
+func (b *ProtoField[BuilderT]) TrailingComment(comment string) *BuilderT {
+	b.trailingComment = comment
+	return b.self
+}
@@ -4,8 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"path"
-
-	u "github.com/Rick-Phoenix/goutils"
+	"slices"
 )
 
 // Function that receives the file data after processing the its schema. If it returns an error, this will be marked as fatal at the very last moment, in order to accumulate all the errors in the schemas and report them.
@@ -17,10 +16,25 @@ type FileSchema struct {
 	Package *ProtoPackage
 	// The name of the file. The ".proto" suffix will be added automatically by the constructor and the getter.
 	Name string
+	// (Default: "proto3") The syntax of the file. Can be "proto2" or "proto3".
+	// When an Edition is set, this indicates the syntax that the schema is migrated from, and the features needed to preserve its semantics are added automatically.
+	Syntax string
+	// The edition of the file (such as "2023" or "2024"). When set, the file uses editions instead of the proto2 or proto3 syntax.
+	Edition string
+	// The features for the file, which are inherited by all of its elements. Only available in files that use editions.
+	Features Features
 	// Imports required by the components of the file will be added automatically. This can be used to add extra imports if necessary.
 	Imports Set
 	// The protobuf extensions for this file.
 	Extensions Extensions
+	// The extend blocks that add fields to messages with extension ranges (proto2 only).
+	Extends []ExtendBlock
+	// A comment that is rendered above this file's syntax declaration in the proto file, and that is used as its leading comment in the descriptor's SourceCodeInfo.
+	Doc string
+	// A comment that is rendered on the same line as the syntax declaration. Line breaks are replaced with spaces.
+	TrailingComment string
+	// Overrides the language options of the package for this file.
+	LanguageOptions LanguageOptions
 	// Top level options.
 	Options  []ProtoOption
 	enums    []*EnumGroup
@@ -34,15 +48,20 @@ type FileSchema struct {
 
 // The struct containing all the results from processing the components of the file. Will be passed to the FileHook after being generated if it's defined.
 type FileData struct {
-	Package    *ProtoPackage
-	Name       string
-	Imports    Set
-	Extensions Extensions
-	Options    []ProtoOption
-	Enums      []EnumGroup
-	Messages   []MessageData
-	Services   []ServiceData
-	Metadata   map[string]any
+	Package         *ProtoPackage
+	Name            string
+	Syntax          string
+	Edition         string
+	Doc             string
+	TrailingComment string
+	Imports         Set
+	Extensions      Extensions
+	Extends         []ExtendData
+	Options         []ProtoOption
+	Enums           []EnumGroup
+	Messages        []MessageData
+	Services        []ServiceData
+	Metadata        map[string]any
 }
 
 // Accesses the name safely, and adds the ".proto" suffix if missing.
@@ -122,20 +141,50 @@ func (f *FileSchema) build() (FileData, error) {
 	imports := make(Set)
 
 	file := FileData{
-		Package:    f.Package,
-		Imports:    imports,
-		Extensions: f.Extensions,
-		Options:    f.Options,
-		Name:       f.Name,
-		Metadata:   f.Metadata,
-		Enums:      u.ToValSlice(f.enums),
+		Package:         f.Package,
+		Imports:         imports,
+		Extensions:      f.Extensions,
+		Options:         slices.Concat(f.languageOptions(), f.Options),
+		Name:            f.Name,
+		Syntax:          f.GetSyntax(),
+		Edition:         f.Edition,
+		Doc:             f.Doc,
+		TrailingComment: f.TrailingComment,
+		Metadata:        f.Metadata,
+		Enums:           []EnumGroup{},
+	}
+
+	var messageErrors error
+
+	if file.Syntax != SyntaxProto2 && file.Syntax != SyntaxProto3 && file.Syntax != SyntaxEditions {
+		messageErrors = errors.Join(messageErrors, fmt.Errorf("Unsupported syntax %q.", file.Syntax))
+	}
+
+	messageErrors = errors.Join(messageErrors, f.checkEdition())
+
+	if file.Syntax == SyntaxEditions {
+		file.Extensions = f.Extensions.withoutOptional()
+		features := f.resolvedFeatures()
+		file.Options = slices.Concat(file.Options, features.options())
+
+		if features.GoAPILevel != "" {
+			imports[goFeaturesImport] = present
+		}
 	}
 
-	if len(f.Extensions.File)+len(f.Extensions.Service)+len(f.Extensions.Message)+len(f.Extensions.Field)+len(f.Extensions.OneOf) > 0 {
+	if f.Extensions.len() > 0 {
 		imports["google/protobuf/descriptor.proto"] = present
+		messageErrors = errors.Join(messageErrors, f.Extensions.build(imports))
 	}
 
-	var messageErrors error
+	for _, e := range f.enums {
+		enum, err := e.build()
+		if err != nil {
+			messageErrors = errors.Join(messageErrors, indentErrors(fmt.Sprintf("Errors for the %q enum", e.GetName()), err))
+		}
+
+		file.Enums = append(file.Enums, enum)
+	}
 
 	for _, m := range f.messages {
 		var errAgg error
@@ -149,10 +198,22 @@ func (f *FileSchema) build() (FileData, error) {
 		}
 	}
 
+	for i, ext := range f.Extends {
+		extend, err := ext.build(f, imports)
+		if err != nil {
+			messageErrors = errors.Join(messageErrors, indentErrors(fmt.Sprintf("Errors for extend block #%d", i+1), err))
+		}
+
+		file.Extends = append(file.Extends, extend)
+	}
+
 	for _, serv := range f.services {
 		file.Services = append(file.Services, serv.build(imports))
 	}
 
+	checker := extensionOptionsChecker{pkg: f.Package.GetName(), extensions: f.Package.declaredExtensions()}
+	messageErrors = errors.Join(messageErrors, checker.checkFile(file))
+
 	if f.Hook != nil {
 		err := f.Hook(file)
 		if err != nil {
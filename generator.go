@@ -14,32 +14,66 @@ import (
 
 // The function that processes the file schemas (and all the schemas inside them) and generates the proto files, while also calling the various hooks and the converter function.
 // This should be called after all the elements of the proto package have been added with the various constructors.
+// Files that have not changed since the previous generation are not rewritten, and the files that are no longer produced are removed.
 func (p *ProtoPackage) Generate() error {
-	filesData := p.BuildFiles()
+	return p.GenerateFiles(p.BuildFiles())
+}
+
+// Generates the files from the data returned by TryBuildFiles. This can be used to handle the errors in the schemas directly, since the schemas should not be processed more than once (as that would call the hooks again).
+func (p *ProtoPackage) GenerateFiles(filesData []FileData) error {
+	if err := p.generate(filesData); err != nil {
+		return err
+	}
+
+	return p.saveManifest()
+}
+
+// Generates the proto files, the descriptor set and the converter from the processed files data.
+func (p *ProtoPackage) generate(filesData []FileData) error {
+	if err := p.loadManifest(); err != nil {
+		return err
+	}
+
+	if !p.lintConfig.Skip {
+		if err := reportLintIssues(Lint(filesData, p.lintConfig), p.lintConfig); err != nil {
+			return err
+		}
+	}
+
+	snapshot := NewSnapshot(filesData)
+
+	breakingChanges, err := p.checkBreaking(snapshot)
+	if err != nil {
+		return err
+	}
+
+	if err := reportBreakingChanges(breakingChanges); err != nil {
+		return err
+	}
 
 	tmpl := p.tmpl
 
 	for _, fileData := range filesData {
 
-		outputFile := strings.ToLower(fileData.Name)
-		outputPath := filepath.Join(p.protoOutputDir, outputFile)
-		delete(fileData.Imports, filepath.Join(p.GetBasePath(), outputFile))
+		outputPath := filepath.Join(p.protoOutputDir, strings.ToLower(fileData.Name))
 
-		var outputBuffer bytes.Buffer
-		if err := tmpl.ExecuteTemplate(&outputBuffer, "protoFile", fileData); err != nil {
-			return fmt.Errorf("Failed to execute template: %w", err)
+		content, err := p.renderFile(fileData)
+		if err != nil {
+			return err
 		}
 
-		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		written, err := p.writeGeneratedFile(outputPath, content)
+		if err != nil {
 			return err
 		}
-		if err := os.WriteFile(outputPath, outputBuffer.Bytes(), 0644); err != nil {
-			return err
+
+		if !written {
+			continue
 		}
 
 		fmt.Printf("✅ Successfully generated proto file at: %s\n", outputPath)
 
-		_, err := exec.LookPath("buf")
+		_, err = exec.LookPath("buf")
 		if err != nil {
 			fmt.Println("Could not format the generated proto file. Is the buf cli in PATH?")
 		} else {
@@ -50,8 +84,39 @@ func (p *ProtoPackage) Generate() error {
 			if err != nil {
 				fmt.Printf("Error while attempting to format the file %q: %s\n", outputPath, err.Error())
 			}
+
+			p.updateOutputHash(outputPath)
+		}
+
+	}
+
+	if err := p.saveLock(); err != nil {
+		return err
+	}
+
+	if p.snapshotFilePath != "" {
+		if err := snapshot.Save(p.snapshotFilePath); err != nil {
+			return err
+		}
+	}
+
+	if p.descriptorSetPath != "" {
+		if err := p.writeDescriptorSet(filesData); err != nil {
+			return err
 		}
+	}
 
+	// In a workspace, the buf files are generated for all the packages that share the same ProtoRoot
+	if p.bufConfig.Generate && p.workspace == nil {
+		if err := writeBufFiles([]*ProtoPackage{p}, [][]FileData{filesData}); err != nil {
+			return err
+		}
+	}
+
+	if p.conformanceTests {
+		if err := p.generateConformanceTests(filesData); err != nil {
+			return err
+		}
 	}
 
 	if p.converterFunc == nil {
@@ -62,18 +127,19 @@ func (p *ProtoPackage) Generate() error {
 
 		outputPath := filepath.Join(p.converterOutputDir, p.converterPackage+".go")
 
-		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		written, err := p.writeGeneratedFile(outputPath, outputBuffer.Bytes())
+		if err != nil {
 			return err
 		}
 
-		if err := os.WriteFile(outputPath, outputBuffer.Bytes(), 0644); err != nil {
-			fmt.Print(err)
+		if !written {
+			return nil
 		}
 
 		cmd := exec.Command("gofmt", "-w", outputPath)
 		cmd.Stderr = os.Stderr
 
-		err := cmd.Run()
+		err = cmd.Run()
 		if err != nil {
 			fmt.Printf("An error occurred while trying to format the converter file at %q:\n%s\n", outputPath, err.Error())
 		}
@@ -85,6 +151,8 @@ func (p *ProtoPackage) Generate() error {
 			fmt.Printf("An error occurred while trying to call goimports for the file %q:\n%s\n", outputPath, importErr.Error())
 		}
 
+		p.updateOutputHash(outputPath)
+
 		fmt.Printf("✅ Successfully generated converter at: %s\n", outputPath)
 
 	}
@@ -92,6 +160,27 @@ func (p *ProtoPackage) Generate() error {
 	return nil
 }
 
+// Writes the conformance tests for the messages of the package next to the converter.
+func (p *ProtoPackage) generateConformanceTests(filesData []FileData) error {
+	var outputBuffer bytes.Buffer
+	if err := p.writeConformanceTests(&outputBuffer, filesData, p.converterPackage+"_test", conformanceTestsSeed); err != nil {
+		return fmt.Errorf("Failed to generate the conformance tests: %w", err)
+	}
+
+	outputPath := filepath.Join(p.converterOutputDir, p.converterPackage+"_rules_test.go")
+
+	written, err := p.writeGeneratedFile(outputPath, outputBuffer.Bytes())
+	if err != nil {
+		return err
+	}
+
+	if written {
+		fmt.Printf("✅ Successfully generated conformance tests at: %s\n", outputPath)
+	}
+
+	return nil
+}
+
 var funcMap = template.FuncMap{
 	"fmtOpt": func(o ProtoOption) string {
 		opt, err := getProtoOption(o.Name, o.Value)
@@ -102,6 +191,26 @@ var funcMap = template.FuncMap{
 
 		return "option " + opt
 	},
+	"inlineOpts": func(opts []ProtoOption) string {
+		if len(opts) == 0 {
+			return ""
+		}
+
+		formatted := make([]string, len(opts))
+
+		for i, o := range opts {
+			opt, err := getProtoOption(o.Name, o.Value)
+			if err != nil {
+				fmt.Println(err.Error())
+				return "error"
+			}
+			formatted[i] = opt
+		}
+
+		return " [" + strings.Join(formatted, ", ") + "]"
+	},
+	"comment":  formatComment,
+	"trailing": formatTrailingComment,
 	"join": func(e []string, sep string) string {
 		str := ""
 
@@ -115,6 +224,19 @@ var funcMap = template.FuncMap{
 
 		return str
 	},
+	// Reserved names are string literals in proto2 and proto3 files, and identifiers in editions files.
+	"reservedNames": func(names []string, file *FileSchema) string {
+		if file.GetSyntax() == SyntaxEditions {
+			return strings.Join(names, ", ")
+		}
+
+		quoted := make([]string, len(names))
+		for i, name := range names {
+			quoted[i] = fmt.Sprintf("%q", name)
+		}
+
+		return strings.Join(quoted, ", ")
+	},
 	"joinInt":   joinIntSlice,
 	"joinInt32": joinInt32Slice,
 	"joinUint":  joinUintSlice,
@@ -0,0 +1,1277 @@
+package protoschema
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/format"
+	"log"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The schemas created by importing existing proto files into a package.
+type ImportedSchemas struct {
+	// The imported files, in the order in which they were imported.
+	Files []*FileSchema
+	// The imported messages, by their name within the package (such as "Item" or "Item.Location").
+	Messages map[string]*MessageSchema
+	// The imported enums, by their name within the package (such as "Status" or "Item.Status").
+	Enums map[string]*EnumGroup
+	// The imported services, by their name (such as "ItemService").
+	Services map[string]*ServiceSchema
+	// The go source code that recreates the schemas.
+	source importedSource
+}
+
+// Returns an imported message, causing a fatal error if it's not found.
+func (s *ImportedSchemas) GetMessage(name string) *MessageSchema {
+	m, exists := s.Messages[name]
+	if !exists {
+		log.Fatalf("The message %q was not imported.", name)
+	}
+
+	return m
+}
+
+// Returns an imported enum, causing a fatal error if it's not found.
+func (s *ImportedSchemas) GetEnum(name string) *EnumGroup {
+	e, exists := s.Enums[name]
+	if !exists {
+		log.Fatalf("The enum %q was not imported.", name)
+	}
+
+	return e
+}
+
+// The go source code for the imported schemas. The declarations create the files, messages and enums (in this order), and the statements in the init function add the fields, oneofs and services.
+type importedSource struct {
+	declarations []importedDeclaration
+	init         []string
+	imports      Set
+}
+
+type importedDeclaration struct {
+	name string
+	// The variable of the parent element. If empty, the file is added to the package.
+	parent string
+	method string
+	value  string
+}
+
+// Returns the go source code that recreates the imported schemas with the builders of this library, so that a package can be migrated in one step.
+// The code declares a variable for each file, message and enum (such as itemFile, itemMsg or statusEnum), and it adds the files to the *ProtoPackage variable with the given name, which must be defined in the same go package.
+func (s *ImportedSchemas) GoSource(goPackage, packageVar string) ([]byte, error) {
+	return s.source.format("Imported from proto files by protoschema.", goPackage, packageVar, "")
+}
+
+// Returns the formatted go source, with the header comment. If the declaration of the package is not empty, it is added before the declarations of the files.
+func (s importedSource) format(header, goPackage, packageVar, packageDecl string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "// %s\n\npackage %s\n\nimport (\n", header, goPackage)
+	for _, imp := range slices.Sorted(maps.Keys(s.imports)) {
+		fmt.Fprintf(&buf, "\t%q\n", imp)
+	}
+	fmt.Fprintf(&buf, "\n\tsb \"github.com/Rick-Phoenix/protoschema\"\n)\n\nvar (\n")
+
+	if packageDecl != "" {
+		fmt.Fprintf(&buf, "\t%s = %s\n", packageVar, packageDecl)
+	}
+
+	for _, d := range s.declarations {
+		parent := d.parent
+		if parent == "" {
+			parent = packageVar
+		}
+
+		fmt.Fprintf(&buf, "\t%s = %s.%s(%s)\n", d.name, parent, d.method, d.value)
+	}
+
+	fmt.Fprintf(&buf, ")\n\nfunc init() {\n")
+	for _, stmt := range s.init {
+		fmt.Fprintf(&buf, "\t%s\n", stmt)
+	}
+	fmt.Fprintf(&buf, "}\n")
+
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to format the go source for the schemas: %w", err)
+	}
+
+	return out, nil
+}
+
+type importedMessage struct {
+	schema  *MessageSchema
+	varName string
+	desc    protoreflect.MessageDescriptor
+}
+
+type importedEnum struct {
+	schema  *EnumGroup
+	varName string
+}
+
+// Converts the descriptors of imported proto files into schemas.
+type protoImporter struct {
+	pkg      *ProtoPackage
+	out      *ImportedSchemas
+	messages map[protoreflect.FullName]*importedMessage
+	enums    map[protoreflect.FullName]*importedEnum
+	// The messages in the order in which they were created, for adding their fields
+	messageOrder []*importedMessage
+	varNames     Set
+	errors       error
+}
+
+// Parses existing proto files and adds their contents to this package, as live schemas created with the same constructors and builders used in go. The protovalidate rules are converted into the corresponding builder methods (such as MinLen or Email), and the rules that have no builder method are kept as options.
+// The paths are relative to the ProtoRoot (like the paths in import statements), and the files must belong to this package. Their imports are resolved from the ProtoRoot, the well-known types and the files in the global protobuf registry (such as buf/validate/validate.proto).
+// The imported messages and enums can be used in new fields (with MsgField or EnumField), and the GoSource method returns the go code that recreates them, so that the package can be switched over to go schemas in one step.
+// The generated files are written in the output directory of the package, so the original files must be removed (or moved) before generating them, since files without the protoschema header are never overwritten.
+func (p *ProtoPackage) ImportProto(paths ...string) (*ImportedSchemas, error) {
+	resolvers := protocompile.CompositeResolver{
+		&protocompile.SourceResolver{ImportPaths: []string{p.protoRoot}},
+		protocompile.ResolverFunc(func(filePath string) (protocompile.SearchResult, error) {
+			desc, err := protoregistry.GlobalFiles.FindFileByPath(filePath)
+			if err != nil {
+				return protocompile.SearchResult{}, err
+			}
+
+			return protocompile.SearchResult{Desc: desc}, nil
+		}),
+	}
+
+	compiler := protocompile.Compiler{
+		Resolver:       protocompile.WithStandardImports(resolvers),
+		SourceInfoMode: protocompile.SourceInfoStandard,
+	}
+
+	files, err := compiler.Compile(context.Background(), paths...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to compile the proto files: %w", err)
+	}
+
+	descriptors := make([]protoreflect.FileDescriptor, len(files))
+	for i, f := range files {
+		descriptors[i] = f
+	}
+
+	return p.importFiles(descriptors)
+}
+
+// Creates the schemas for the given file descriptors in this package.
+func (p *ProtoPackage) importFiles(files []protoreflect.FileDescriptor) (*ImportedSchemas, error) {
+	im := &protoImporter{
+		pkg: p,
+		out: &ImportedSchemas{
+			Messages: make(map[string]*MessageSchema), Enums: make(map[string]*EnumGroup), Services: make(map[string]*ServiceSchema),
+			source: importedSource{imports: make(Set)},
+		},
+		messages: make(map[protoreflect.FullName]*importedMessage),
+		enums:    make(map[protoreflect.FullName]*importedEnum),
+		varNames: make(Set),
+	}
+
+	var fileVars []string
+
+	for _, f := range files {
+		if string(f.Package()) != p.GetName() {
+			im.errors = errors.Join(im.errors, fmt.Errorf("The file %q belongs to the package %q instead of %q.", f.Path(), f.Package(), p.GetName()))
+			fileVars = append(fileVars, "")
+			continue
+		}
+
+		fileVars = append(fileVars, im.importFile(f))
+	}
+
+	if im.errors != nil {
+		return nil, im.errors
+	}
+
+	for _, m := range im.messageOrder {
+		im.importFields(m)
+	}
+
+	for i, f := range files {
+		services := f.Services()
+		for j := range services.Len() {
+			im.importService(im.out.Files[i], fileVars[i], services.Get(j))
+		}
+	}
+
+	if im.errors != nil {
+		return nil, im.errors
+	}
+
+	return im.out, nil
+}
+
+// Returns a unique go variable name for an element, such as "itemLocationMsg" for the message "Item.Location".
+func (im *protoImporter) varName(name, suffix string) string {
+	var b strings.Builder
+
+	for i, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '.' || r == '_' || r == '/' || r == '-' }) {
+		if i == 0 {
+			b.WriteString(strings.ToLower(part[:1]) + part[1:])
+		} else {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+
+	base := b.String() + suffix
+	varName := base
+
+	for i := 2; ; i++ {
+		if _, exists := im.varNames[varName]; !exists {
+			break
+		}
+		varName = base + strconv.Itoa(i)
+	}
+
+	im.varNames[varName] = present
+
+	return varName
+}
+
+// Returns the leading and trailing comments of an element, without the leading space of each line.
+func importedComments(loc protoreflect.SourceLocation) (string, string) {
+	clean := func(comment string) string {
+		lines := strings.Split(strings.TrimRight(comment, "\n"), "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimPrefix(line, " ")
+		}
+		return strings.TrimSpace(strings.Join(lines, "\n"))
+	}
+
+	return clean(loc.LeadingComments), clean(loc.TrailingComments)
+}
+
+func descriptorComments(d protoreflect.Descriptor) (string, string) {
+	return importedComments(d.ParentFile().SourceLocations().ByDescriptor(d))
+}
+
+// Builds the go literal for a struct, with the non-empty fields.
+type structLiteral struct {
+	typeName string
+	fields   []string
+}
+
+func (s *structLiteral) add(name, value string) {
+	s.fields = append(s.fields, name+": "+value)
+}
+
+func (s *structLiteral) addString(name, value string) {
+	if value != "" {
+		s.add(name, strconv.Quote(value))
+	}
+}
+
+func (s *structLiteral) String() string {
+	return s.typeName + "{" + strings.Join(s.fields, ", ") + "}"
+}
+
+// Returns the value of an extension in a set of options, resolved with the global registry.
+func optionExtension(opts proto.Message, name protoreflect.FullName) protoreflect.Message {
+	if opts == nil || reflect.ValueOf(opts).IsNil() {
+		return nil
+	}
+
+	xt, err := protoregistry.GlobalTypes.FindExtensionByName(name)
+	if err != nil {
+		return nil
+	}
+
+	// The options are re-parsed so that the extensions use the registered go types
+	content, err := proto.Marshal(opts)
+	if err != nil {
+		return nil
+	}
+
+	parsed := opts.ProtoReflect().Type().New().Interface()
+	if err := (proto.UnmarshalOptions{Resolver: protoregistry.GlobalTypes}).Unmarshal(content, parsed); err != nil {
+		return nil
+	}
+
+	if !proto.HasExtension(parsed, xt) {
+		return nil
+	}
+
+	return proto.GetExtension(parsed, xt).(proto.Message).ProtoReflect()
+}
+
+// Converts the options that are not handled by the importer into ProtoOptions. Only options with scalar values can be converted, and the others are reported with a warning.
+func (im *protoImporter) importOptions(element string, opts proto.Message, handled ...string) ([]ProtoOption, []string) {
+	if opts == nil || reflect.ValueOf(opts).IsNil() {
+		return nil, nil
+	}
+
+	content, err := proto.Marshal(opts)
+	if err != nil {
+		return nil, nil
+	}
+
+	parsed := opts.ProtoReflect().Type().New().Interface()
+	if err := (proto.UnmarshalOptions{Resolver: protoregistry.GlobalTypes}).Unmarshal(content, parsed); err != nil {
+		return nil, nil
+	}
+
+	var out []ProtoOption
+	var literals []string
+
+	parsed.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		name := string(fd.Name())
+		if fd.IsExtension() {
+			name = "(" + string(fd.FullName()) + ")"
+		}
+
+		if slices.Contains(handled, name) || strings.HasPrefix(name, "(buf.validate.") {
+			return true
+		}
+
+		value, ok := scalarOptionValue(fd, v)
+		if !ok {
+			fmt.Printf("Warning: the option %q of %s could not be imported, because only options with scalar values are supported.\n", name, element)
+			return true
+		}
+
+		out = append(out, ProtoOption{Name: name, Value: value})
+		literals = append(literals, fmt.Sprintf("sb.ProtoOption{Name: %q, Value: %s}", name, im.goLiteral(reflect.ValueOf(value))))
+		return true
+	})
+
+	return out, literals
+}
+
+func scalarOptionValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) (any, bool) {
+	if fd.IsList() || fd.IsMap() {
+		return nil, false
+	}
+
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind, protoreflect.EnumKind:
+		return nil, false
+	}
+
+	return v.Interface(), true
+}
+
+// Creates the schema for a file, along with its enums and messages (without their fields), and returns the name of its variable.
+func (im *protoImporter) importFile(f protoreflect.FileDescriptor) string {
+	name := strings.TrimSuffix(f.Path()[strings.LastIndex(f.Path(), "/")+1:], ".proto")
+
+	schema := FileSchema{Name: name}
+	lit := structLiteral{typeName: "sb.FileSchema"}
+	lit.addString("Name", name)
+
+	switch f.Syntax() {
+	case protoreflect.Editions:
+		schema.Edition = strings.TrimPrefix(protodesc.ToFileDescriptorProto(f).GetEdition().String(), "EDITION_")
+		lit.addString("Edition", schema.Edition)
+	default:
+		schema.Syntax = f.Syntax().String()
+		lit.addString("Syntax", schema.Syntax)
+	}
+
+	// The comments of the file are attached to the syntax (or edition) declaration
+	schema.Doc, schema.TrailingComment = importedComments(f.SourceLocations().ByPath(protoreflect.SourcePath{12}))
+	if schema.Doc == "" {
+		schema.Doc, schema.TrailingComment = importedComments(f.SourceLocations().ByPath(protoreflect.SourcePath{14}))
+	}
+	lit.addString("Doc", schema.Doc)
+	lit.addString("TrailingComment", schema.TrailingComment)
+
+	fileOpts, _ := f.Options().(*descriptorpb.FileOptions)
+	langLit := structLiteral{typeName: "sb.LanguageOptions"}
+
+	// The language options that are the same as those of the package are not set on the file
+	defaults := make(map[string]any)
+	for _, o := range (&FileSchema{Package: im.pkg}).languageOptions() {
+		defaults[o.Name] = o.Value
+	}
+
+	isDefault := func(name string, value any) bool {
+		defaultValue, exists := defaults[name]
+		return exists && defaultValue == value
+	}
+
+	if goPackage := fileOpts.GetGoPackage(); goPackage != "" && !isDefault("go_package", goPackage) {
+		schema.LanguageOptions.GoPackage = goPackage
+		langLit.addString("GoPackage", goPackage)
+	}
+
+	stringOptions := []struct {
+		field  string
+		option string
+		target *string
+		value  string
+	}{
+		{"JavaPackage", "java_package", &schema.LanguageOptions.JavaPackage, fileOpts.GetJavaPackage()},
+		{"CsharpNamespace", "csharp_namespace", &schema.LanguageOptions.CsharpNamespace, fileOpts.GetCsharpNamespace()},
+		{"ObjcClassPrefix", "objc_class_prefix", &schema.LanguageOptions.ObjcClassPrefix, fileOpts.GetObjcClassPrefix()},
+		{"PhpNamespace", "php_namespace", &schema.LanguageOptions.PhpNamespace, fileOpts.GetPhpNamespace()},
+		{"RubyPackage", "ruby_package", &schema.LanguageOptions.RubyPackage, fileOpts.GetRubyPackage()},
+		{"SwiftPrefix", "swift_prefix", &schema.LanguageOptions.SwiftPrefix, fileOpts.GetSwiftPrefix()},
+	}
+
+	for _, opt := range stringOptions {
+		if isDefault(opt.option, opt.value) {
+			continue
+		}
+		*opt.target = opt.value
+		langLit.addString(opt.field, opt.value)
+	}
+
+	if fileOpts != nil && fileOpts.JavaMultipleFiles != nil && !isDefault("java_multiple_files", fileOpts.GetJavaMultipleFiles()) {
+		schema.LanguageOptions.JavaMultipleFiles = fileOpts.JavaMultipleFiles
+		im.out.source.imports["google.golang.org/protobuf/proto"] = present
+		langLit.add("JavaMultipleFiles", fmt.Sprintf("proto.Bool(%t)", fileOpts.GetJavaMultipleFiles()))
+	}
+
+	if len(langLit.fields) > 0 {
+		lit.add("LanguageOptions", langLit.String())
+	}
+
+	var optionLiterals []string
+	schema.Options, optionLiterals = im.importOptions(fmt.Sprintf("the file %q", f.Path()), fileOpts,
+		"go_package", "java_package", "java_multiple_files", "csharp_namespace", "objc_class_prefix", "php_namespace", "ruby_package", "swift_prefix")
+	if len(optionLiterals) > 0 {
+		lit.add("Options", "[]sb.ProtoOption{"+strings.Join(optionLiterals, ", ")+"}")
+	}
+
+	if f.Extensions().Len() > 0 {
+		fmt.Printf("Warning: the extensions in the file %q were not imported.\n", f.Path())
+	}
+
+	file := im.pkg.NewFile(schema)
+	im.out.Files = append(im.out.Files, file)
+
+	fileVar := im.varName(name, "File")
+	im.out.source.declarations = append(im.out.source.declarations, importedDeclaration{name: fileVar, method: "NewFile", value: lit.String()})
+
+	enums := f.Enums()
+	for i := range enums.Len() {
+		im.importEnum(enums.Get(i), fileVar, file.NewEnum)
+	}
+
+	messages := f.Messages()
+	for i := range messages.Len() {
+		im.importMessage(messages.Get(i), fileVar, file.NewMessage)
+	}
+
+	return fileVar
+}
+
+// Returns the name of an element relative to the package (such as "Item.Location").
+func (im *protoImporter) relativeName(d protoreflect.Descriptor) string {
+	return strings.TrimPrefix(string(d.FullName()), im.pkg.GetName()+".")
+}
+
+// Converts reserved ranges (whose end is exclusive) into single numbers and ranges (whose end is inclusive).
+func reservedNumbers[T uint | int32](ranges interface {
+	Len() int
+	Get(i int) [2]protoreflect.FieldNumber
+}, endExclusive bool) ([]T, []Range) {
+	var numbers []T
+	var outRanges []Range
+
+	for i := range ranges.Len() {
+		r := ranges.Get(i)
+		start, end := int32(r[0]), int32(r[1])
+		if endExclusive {
+			end--
+		}
+
+		if start == end {
+			numbers = append(numbers, T(start))
+		} else {
+			outRanges = append(outRanges, Range{start, end})
+		}
+	}
+
+	return numbers, outRanges
+}
+
+type enumRanges struct{ protoreflect.EnumRanges }
+
+func (r enumRanges) Get(i int) [2]protoreflect.FieldNumber {
+	er := r.EnumRanges.Get(i)
+	return [2]protoreflect.FieldNumber{protoreflect.FieldNumber(er[0]), protoreflect.FieldNumber(er[1])}
+}
+
+func reservedNames(names protoreflect.Names) []string {
+	var out []string
+	for i := range names.Len() {
+		out = append(out, string(names.Get(i)))
+	}
+	return out
+}
+
+func rangesLiteral(ranges []Range) string {
+	var items []string
+	for _, r := range ranges {
+		items = append(items, fmt.Sprintf("{%d, %d}", r[0], r[1]))
+	}
+	return "[]sb.Range{" + strings.Join(items, ", ") + "}"
+}
+
+func intsLiteral[T uint | int32](typeName string, numbers []T) string {
+	var items []string
+	for _, n := range numbers {
+		items = append(items, fmt.Sprint(n))
+	}
+	return "[]" + typeName + "{" + strings.Join(items, ", ") + "}"
+}
+
+func stringsLiteral(values []string) string {
+	var items []string
+	for _, v := range values {
+		items = append(items, strconv.Quote(v))
+	}
+	return "[]string{" + strings.Join(items, ", ") + "}"
+}
+
+func (im *protoImporter) importEnum(e protoreflect.EnumDescriptor, parentVar string, newEnum func(EnumGroup) *EnumGroup) {
+	schema := EnumGroup{Name: string(e.Name())}
+	lit := structLiteral{typeName: "sb.EnumGroup"}
+	lit.addString("Name", schema.Name)
+
+	schema.Doc, schema.TrailingComment = descriptorComments(e)
+	lit.addString("Doc", schema.Doc)
+	lit.addString("TrailingComment", schema.TrailingComment)
+
+	var valueLiterals []string
+	values := e.Values()
+	for i := range values.Len() {
+		v := values.Get(i)
+		value := EnumValue{Name: string(v.Name()), Number: int32(v.Number())}
+		value.Doc, value.TrailingComment = descriptorComments(v)
+
+		valueLit := structLiteral{}
+		valueLit.addString("Name", value.Name)
+		valueLit.add("Number", fmt.Sprint(value.Number))
+		valueLit.addString("Doc", value.Doc)
+		valueLit.addString("TrailingComment", value.TrailingComment)
+
+		var optionLiterals []string
+		value.Options, optionLiterals = im.importOptions(fmt.Sprintf("the enum value %q", v.FullName()), v.Options())
+		if len(optionLiterals) > 0 {
+			valueLit.add("Options", "[]sb.ProtoOption{"+strings.Join(optionLiterals, ", ")+"}")
+		}
+
+		schema.Values = append(schema.Values, value)
+		valueLiterals = append(valueLiterals, valueLit.String())
+	}
+	lit.add("Values", "[]sb.EnumValue{"+strings.Join(valueLiterals, ", ")+"}")
+
+	schema.ReservedNumbers, schema.ReservedRanges = reservedNumbers[int32](enumRanges{e.ReservedRanges()}, false)
+	if len(schema.ReservedNumbers) > 0 {
+		lit.add("ReservedNumbers", intsLiteral("int32", schema.ReservedNumbers))
+	}
+	if len(schema.ReservedRanges) > 0 {
+		lit.add("ReservedRanges", rangesLiteral(schema.ReservedRanges))
+	}
+
+	if schema.ReservedNames = reservedNames(e.ReservedNames()); len(schema.ReservedNames) > 0 {
+		lit.add("ReservedNames", stringsLiteral(schema.ReservedNames))
+	}
+
+	var optionLiterals []string
+	schema.Options, optionLiterals = im.importOptions(fmt.Sprintf("the enum %q", e.FullName()), e.Options())
+	if len(optionLiterals) > 0 {
+		lit.add("Options", "[]sb.ProtoOption{"+strings.Join(optionLiterals, ", ")+"}")
+	}
+
+	enum := newEnum(schema)
+	varName := im.varName(im.relativeName(e), "Enum")
+
+	im.enums[e.FullName()] = &importedEnum{schema: enum, varName: varName}
+	im.out.Enums[im.relativeName(e)] = enum
+	im.out.source.declarations = append(im.out.source.declarations, importedDeclaration{name: varName, parent: parentVar, method: "NewEnum", value: lit.String()})
+}
+
+func (im *protoImporter) importMessage(m protoreflect.MessageDescriptor, parentVar string, newMessage func(MessageSchema) *MessageSchema) {
+	schema := MessageSchema{Name: string(m.Name())}
+	lit := structLiteral{typeName: "sb.MessageSchema"}
+	lit.addString("Name", schema.Name)
+
+	schema.Doc, schema.TrailingComment = descriptorComments(m)
+	lit.addString("Doc", schema.Doc)
+	lit.addString("TrailingComment", schema.TrailingComment)
+
+	schema.ReservedNumbers, schema.ReservedRanges = reservedNumbers[uint](m.ReservedRanges(), true)
+	if len(schema.ReservedNumbers) > 0 {
+		lit.add("ReservedNumbers", intsLiteral("uint", schema.ReservedNumbers))
+	}
+	if len(schema.ReservedRanges) > 0 {
+		lit.add("ReservedRanges", rangesLiteral(schema.ReservedRanges))
+	}
+
+	if schema.ReservedNames = reservedNames(m.ReservedNames()); len(schema.ReservedNames) > 0 {
+		lit.add("ReservedNames", stringsLiteral(schema.ReservedNames))
+	}
+
+	_, schema.ExtensionRanges = reservedNumbers[uint](m.ExtensionRanges(), true)
+	if len(schema.ExtensionRanges) > 0 {
+		lit.add("ExtensionRanges", rangesLiteral(schema.ExtensionRanges))
+	}
+
+	var optionLiterals []string
+	schema.Options, optionLiterals = im.importOptions(fmt.Sprintf("the message %q", m.FullName()), m.Options())
+	if len(optionLiterals) > 0 {
+		lit.add("Options", "[]sb.ProtoOption{"+strings.Join(optionLiterals, ", ")+"}")
+	}
+
+	msg := newMessage(schema)
+	varName := im.varName(im.relativeName(m), "Msg")
+
+	imported := &importedMessage{schema: msg, varName: varName, desc: m}
+	im.messages[m.FullName()] = imported
+	im.messageOrder = append(im.messageOrder, imported)
+	im.out.Messages[im.relativeName(m)] = msg
+	im.out.source.declarations = append(im.out.source.declarations, importedDeclaration{name: varName, parent: parentVar, method: "NewMessage", value: lit.String()})
+
+	if parentVar != "" && strings.HasSuffix(parentVar, "Msg") {
+		im.out.source.declarations[len(im.out.source.declarations)-1].method = "NestedMessage"
+	}
+
+	enums := m.Enums()
+	for i := range enums.Len() {
+		im.importEnum(enums.Get(i), varName, msg.NewEnum)
+	}
+
+	nested := m.Messages()
+	for i := range nested.Len() {
+		if nested.Get(i).IsMapEntry() {
+			continue
+		}
+		im.importMessage(nested.Get(i), varName, msg.NestedMessage)
+	}
+}
+
+// A field builder with the go expression that recreates it.
+type importedField struct {
+	builder FieldBuilder
+	expr    string
+	element string
+}
+
+// The constructors of the scalar field types, by their protobuf kind.
+var importedScalarTypes = map[protoreflect.Kind]string{
+	protoreflect.StringKind: "String", protoreflect.BytesKind: "Bytes", protoreflect.BoolKind: "Bool",
+	protoreflect.Int32Kind: "Int32", protoreflect.Int64Kind: "Int64", protoreflect.Uint32Kind: "UInt32", protoreflect.Uint64Kind: "UInt64",
+	protoreflect.Sint32Kind: "SInt32", protoreflect.Sint64Kind: "SInt64", protoreflect.Fixed32Kind: "Fixed32", protoreflect.Fixed64Kind: "Fixed64",
+	protoreflect.Sfixed32Kind: "SFixed32", protoreflect.Sfixed64Kind: "SFixed64", protoreflect.FloatKind: "Float", protoreflect.DoubleKind: "Double",
+}
+
+// The constructors of the fields for the well-known message types that have a dedicated builder.
+var importedWellKnownTypes = map[protoreflect.FullName]string{
+	"google.protobuf.Timestamp": "Timestamp",
+	"google.protobuf.Duration":  "Duration",
+	"google.protobuf.Any":       "Any",
+	"google.protobuf.FieldMask": "FieldMask",
+}
+
+// Calls a method of the field builder (and adds it to its go expression), reporting the methods that do not exist for this field type and the panics caused by the builder.
+func (im *protoImporter) call(f *importedField, methodName string, args ...reflect.Value) bool {
+	method := reflect.ValueOf(f.builder).MethodByName(methodName)
+	if !method.IsValid() {
+		return false
+	}
+
+	ok := func() (ok bool) {
+		defer func() {
+			if r := recover(); r != nil {
+				im.errors = errors.Join(im.errors, fmt.Errorf("Failed to apply %s to %s: %v", methodName, f.element, r))
+				ok = false
+			}
+		}()
+
+		if method.Type().IsVariadic() {
+			method.CallSlice(args)
+		} else {
+			method.Call(args)
+		}
+
+		return true
+	}()
+
+	if !ok {
+		return true
+	}
+
+	var literals []string
+	for i, arg := range args {
+		if method.Type().IsVariadic() && i == len(args)-1 {
+			for j := range arg.Len() {
+				literals = append(literals, im.goLiteral(arg.Index(j)))
+			}
+			continue
+		}
+		literals = append(literals, im.goLiteral(arg))
+	}
+
+	f.expr += "." + methodName + "(" + strings.Join(literals, ", ") + ")"
+
+	return true
+}
+
+// Returns the go literal for a value used in a rule, option or default value.
+func (im *protoImporter) goLiteral(v reflect.Value) string {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	switch val := v.Interface().(type) {
+	case *timestamppb.Timestamp:
+		im.out.source.imports["google.golang.org/protobuf/types/known/timestamppb"] = present
+		return fmt.Sprintf("&timestamppb.Timestamp{Seconds: %d, Nanos: %d}", val.GetSeconds(), val.GetNanos())
+	case *durationpb.Duration:
+		im.out.source.imports["google.golang.org/protobuf/types/known/durationpb"] = present
+		return fmt.Sprintf("&durationpb.Duration{Seconds: %d, Nanos: %d}", val.GetSeconds(), val.GetNanos())
+	case []byte:
+		return fmt.Sprintf("[]byte(%q)", val)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case reflect.Slice:
+		var items []string
+		for i := range v.Len() {
+			items = append(items, im.goLiteral(v.Index(i)))
+		}
+		return v.Type().String() + "{" + strings.Join(items, ", ") + "}"
+	}
+
+	return fmt.Sprintf("%#v", v.Interface())
+}
+
+// Returns the schema (and its go expression) for a message defined outside of the imported files.
+func (im *protoImporter) externalMessage(m protoreflect.MessageDescriptor) (*MessageSchema, string) {
+	if m.FullName() == "google.protobuf.Empty" {
+		return Empty(), "sb.Empty()"
+	}
+
+	if imported, exists := im.messages[m.FullName()]; exists {
+		return imported.schema, imported.varName
+	}
+
+	pkg := &ProtoPackage{Name: string(m.ParentFile().Package()), GoPackagePath: importedGoPackage(m.ParentFile())}
+	name := strings.TrimPrefix(string(m.FullName()), pkg.Name+".")
+
+	schema := &MessageSchema{Name: name, ImportPath: m.ParentFile().Path(), Package: pkg}
+	expr := fmt.Sprintf("&sb.MessageSchema{Name: %q, ImportPath: %q, Package: &sb.ProtoPackage{Name: %q, GoPackagePath: %q}}", name, schema.ImportPath, pkg.Name, pkg.GoPackagePath)
+
+	return schema, expr
+}
+
+// Returns the schema (and its go expression) for an enum.
+func (im *protoImporter) enumType(e protoreflect.EnumDescriptor) (*EnumGroup, string) {
+	if imported, exists := im.enums[e.FullName()]; exists {
+		return imported.schema, imported.varName
+	}
+
+	pkg := &ProtoPackage{Name: string(e.ParentFile().Package()), GoPackagePath: importedGoPackage(e.ParentFile())}
+	name := strings.TrimPrefix(string(e.FullName()), pkg.Name+".")
+
+	schema := &EnumGroup{Name: name, ImportPath: e.ParentFile().Path(), Package: pkg}
+	expr := fmt.Sprintf("&sb.EnumGroup{Name: %q, ImportPath: %q, Package: &sb.ProtoPackage{Name: %q, GoPackagePath: %q}}", name, schema.ImportPath, pkg.Name, pkg.GoPackagePath)
+
+	return schema, expr
+}
+
+// Returns the import path of the go package of a file, from its go_package option.
+func importedGoPackage(f protoreflect.FileDescriptor) string {
+	opts, _ := f.Options().(*descriptorpb.FileOptions)
+	return strings.Split(opts.GetGoPackage(), ";")[0]
+}
+
+// Creates the builder for the type of a field (or for the type of the items, keys or values of repeated and map fields), and applies the protovalidate rules for that type.
+func (im *protoImporter) typedField(name string, fd protoreflect.FieldDescriptor, rules protoreflect.Message, element string) *importedField {
+	f := &importedField{element: element}
+
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if constructor, isWellKnown := importedWellKnownTypes[fd.Message().FullName()]; isWellKnown {
+			f.builder = documentFieldTypes[toSnakeCase(constructor)](name)
+			f.expr = fmt.Sprintf("sb.%s(%q)", constructor, name)
+			break
+		}
+
+		schema, expr := im.externalMessage(fd.Message())
+		f.builder = MsgField(name, schema)
+		f.expr = fmt.Sprintf("sb.MsgField(%q, %s)", name, expr)
+	case protoreflect.EnumKind:
+		schema, expr := im.enumType(fd.Enum())
+		f.builder = EnumField(name, schema)
+		f.expr = fmt.Sprintf("sb.EnumField(%q, %s)", name, expr)
+	default:
+		constructor := importedScalarTypes[fd.Kind()]
+		f.builder = documentFieldTypes[strings.ToLower(constructor)](name)
+		f.expr = fmt.Sprintf("sb.%s(%q)", constructor, name)
+	}
+
+	im.applyRules(f, rules)
+
+	return f
+}
+
+// Applies the protovalidate rules of a field (a buf.validate.FieldRules message) to its builder. The rules of the items of repeated fields and of the keys and values of maps are applied when their builders are created.
+func (im *protoImporter) applyRules(f *importedField, rules protoreflect.Message) {
+	if rules == nil {
+		return
+	}
+
+	rules.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		name := string(fd.Name())
+
+		switch name {
+		case "required":
+			if v.Bool() {
+				im.call(f, "Required")
+			}
+		case "ignore":
+			value := fd.Enum().Values().ByNumber(v.Enum())
+			if value == nil || v.Enum() == 0 {
+				break
+			}
+
+			methodName := "Ignore" + pascalCase(strings.TrimPrefix(string(value.Name()), "IGNORE_"))
+			if !im.call(f, methodName) {
+				fmt.Printf("Warning: the rule (buf.validate.field).ignore = %s of %s could not be imported, because it is not supported.\n", value.Name(), f.element)
+			}
+		case "cel":
+			list := v.List()
+			for i := range list.Len() {
+				rule := list.Get(i).Message()
+				field := func(n protoreflect.Name) reflect.Value {
+					return reflect.ValueOf(rule.Get(rule.Descriptor().Fields().ByName(n)).String())
+				}
+				im.call(f, "CelOption", field("id"), field("message"), field("expression"))
+			}
+		default:
+			if fd.Message() == nil {
+				im.fallbackRule(f, "(buf.validate.field)."+name, fd, v)
+				break
+			}
+
+			typeRules := v.Message()
+			typeRules.Range(func(rfd protoreflect.FieldDescriptor, rv protoreflect.Value) bool {
+				switch rfd.Name() {
+				case "items", "keys", "values":
+					return true
+				}
+
+				if !im.applyTypeRule(f, rfd, rv) {
+					im.fallbackRule(f, fmt.Sprintf("(buf.validate.field).%s.%s", name, rfd.Name()), rfd, rv)
+				}
+
+				return true
+			})
+		}
+
+		return true
+	})
+}
+
+// Converts a name in screaming snake case (such as IF_UNPOPULATED) to pascal case (IfUnpopulated).
+func pascalCase(name string) string {
+	var b strings.Builder
+
+	for part := range strings.SplitSeq(strings.ToLower(name), "_") {
+		if part != "" {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+
+	return b.String()
+}
+
+// Applies a rule of a specific type (such as string.min_len) by calling the builder method with the same name, ignoring the case and the underscores. It returns false if the rule has no builder method, or if its value cannot be converted into the arguments of the method.
+func (im *protoImporter) applyTypeRule(f *importedField, fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+	builder := reflect.ValueOf(f.builder)
+	normalized := strings.ReplaceAll(string(fd.Name()), "_", "")
+
+	var method reflect.Value
+	var methodName string
+
+	for i := range builder.NumMethod() {
+		name := builder.Type().Method(i).Name
+		if lower := strings.ToLower(name); lower == normalized && !slices.Contains(nonRuleMethods, lower) {
+			method, methodName = builder.Method(i), name
+			break
+		}
+	}
+
+	if !method.IsValid() {
+		return false
+	}
+
+	methodType := method.Type()
+
+	switch {
+	case methodType.NumIn() == 0:
+		if fd.Kind() != protoreflect.BoolKind || fd.IsList() {
+			return false
+		}
+		// Rules without arguments that are set to false have no effect
+		if v.Bool() {
+			im.call(f, methodName)
+		}
+		return true
+	case fd.IsList():
+		if !methodType.IsVariadic() || methodType.NumIn() != 1 {
+			return false
+		}
+
+		argType := methodType.In(0).Elem()
+		list := v.List()
+		args := reflect.MakeSlice(methodType.In(0), 0, list.Len())
+
+		for i := range list.Len() {
+			arg, ok := ruleArg(argType, fd, list.Get(i))
+			if !ok {
+				return false
+			}
+			args = reflect.Append(args, arg)
+		}
+
+		im.call(f, methodName, args)
+		return true
+	case methodType.NumIn() == 1 && !methodType.IsVariadic():
+		arg, ok := ruleArg(methodType.In(0), fd, v)
+		if !ok {
+			return false
+		}
+
+		im.call(f, methodName, arg)
+		return true
+	}
+
+	return false
+}
+
+// Converts the value of a rule into the argument of its builder method. Durations are converted to strings for the methods that take them in the format of time.ParseDuration, and enum values are passed as numbers.
+func ruleArg(argType reflect.Type, fd protoreflect.FieldDescriptor, v protoreflect.Value) (reflect.Value, bool) {
+	var value any
+
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		msg := v.Message().Interface()
+
+		if d, isDuration := msg.(*durationpb.Duration); isDuration && argType.Kind() == reflect.String {
+			value = d.AsDuration().String()
+		} else {
+			value = msg
+		}
+	case protoreflect.EnumKind:
+		value = int32(v.Enum())
+	default:
+		value = v.Interface()
+	}
+
+	rv := reflect.ValueOf(value)
+
+	switch {
+	case rv.Type().AssignableTo(argType):
+		arg := reflect.New(argType).Elem()
+		arg.Set(rv)
+		return arg, true
+	case rv.Kind() != reflect.Pointer && rv.CanConvert(argType):
+		return rv.Convert(argType), true
+	}
+
+	return reflect.Value{}, false
+}
+
+// Keeps a rule without a builder method as an option of the field. Only rules with scalar values can be kept, and the others are reported with a warning.
+func (im *protoImporter) fallbackRule(f *importedField, name string, fd protoreflect.FieldDescriptor, v protoreflect.Value) {
+	value, ok := scalarOptionValue(fd, v)
+	if !ok || !im.call(f, "Options", reflect.ValueOf([]ProtoOption{{Name: name, Value: value}})) {
+		fmt.Printf("Warning: the rule %s of %s could not be imported, because it has no builder method and it cannot be kept as an option.\n", name, f.element)
+	}
+}
+
+// Creates the builder for a field, with its rules, options and comments.
+func (im *protoImporter) field(fd protoreflect.FieldDescriptor) *importedField {
+	element := fmt.Sprintf("the field %q", fd.FullName())
+	name := string(fd.Name())
+	rules := optionExtension(fd.Options(), "buf.validate.field")
+
+	// Returns the rules of the items of repeated fields and of the keys and values of maps
+	nestedRules := func(typeName, name protoreflect.Name) protoreflect.Message {
+		if rules == nil {
+			return nil
+		}
+
+		typeField := rules.Descriptor().Fields().ByName(typeName)
+		if typeField == nil || !rules.Has(typeField) {
+			return nil
+		}
+
+		typeRules := rules.Get(typeField).Message()
+		nestedField := typeRules.Descriptor().Fields().ByName(name)
+		if nestedField == nil || !typeRules.Has(nestedField) {
+			return nil
+		}
+
+		return typeRules.Get(nestedField).Message()
+	}
+
+	var f *importedField
+
+	switch {
+	case fd.IsMap():
+		keys := im.typedField("", fd.MapKey(), nestedRules("map", "keys"), element)
+		values := im.typedField("", fd.MapValue(), nestedRules("map", "values"), element)
+
+		f = &importedField{builder: Map(name, keys.builder, values.builder), expr: fmt.Sprintf("sb.Map(%q, %s, %s)", name, keys.expr, values.expr), element: element}
+		im.applyRules(f, rules)
+	case fd.IsList():
+		items := im.typedField("", fd, nestedRules("repeated", "items"), element)
+
+		f = &importedField{builder: Repeated(name, items.builder), expr: fmt.Sprintf("sb.Repeated(%q, %s)", name, items.expr), element: element}
+		im.applyRules(f, rules)
+	default:
+		f = im.typedField(name, fd, rules, element)
+	}
+
+	if fd.HasOptionalKeyword() && fd.ParentFile().Syntax() == protoreflect.Proto3 {
+		if !im.call(f, "Optional") {
+			fmt.Printf("Warning: %s is optional, but its type does not support the optional label.\n", element)
+		}
+	}
+
+	if fd.Cardinality() == protoreflect.Required {
+		im.call(f, "RequiredLabel")
+	}
+
+	if fd.HasDefault() {
+		var value reflect.Value
+		if fd.Kind() == protoreflect.EnumKind {
+			value = reflect.ValueOf(string(fd.DefaultEnumValue().Name()))
+		} else {
+			value = reflect.ValueOf(fd.Default().Interface())
+		}
+		im.call(f, "Default", value)
+	}
+
+	fieldOpts, _ := fd.Options().(*descriptorpb.FieldOptions)
+	if fieldOpts.GetDeprecated() {
+		im.call(f, "Deprecated")
+	}
+
+	if options, _ := im.importOptions(element, fieldOpts, "deprecated"); len(options) > 0 {
+		if !im.call(f, "Options", reflect.ValueOf(options)) {
+			fmt.Printf("Warning: the options of %s could not be imported.\n", element)
+		}
+	}
+
+	doc, trailingComment := descriptorComments(fd)
+	if doc != "" {
+		im.call(f, "Doc", reflect.ValueOf(doc))
+	}
+	if trailingComment != "" {
+		im.call(f, "TrailingComment", reflect.ValueOf(trailingComment))
+	}
+
+	return f
+}
+
+// Adds the fields, the oneofs and the message-level rules to an imported message.
+func (im *protoImporter) importFields(m *importedMessage) {
+	fields := make(FieldsMap)
+	var fieldLiterals []string
+
+	descFields := m.desc.Fields()
+	for i := range descFields.Len() {
+		fd := descFields.Get(i)
+		if oneof := fd.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() {
+			continue
+		}
+
+		f := im.field(fd)
+		fields[uint32(fd.Number())] = f.builder
+		fieldLiterals = append(fieldLiterals, fmt.Sprintf("%d: %s", fd.Number(), f.expr))
+	}
+
+	if len(fields) > 0 {
+		m.schema.Fields = fields
+		im.out.source.init = append(im.out.source.init, fmt.Sprintf("%s.Fields = sb.FieldsMap{%s}", m.varName, strings.Join(fieldLiterals, ", ")))
+	}
+
+	oneofs := m.desc.Oneofs()
+	for i := range oneofs.Len() {
+		od := oneofs.Get(i)
+		if od.IsSynthetic() {
+			continue
+		}
+
+		oneof := OneofGroup{Name: string(od.Name()), Fields: make(OneofFields)}
+		lit := structLiteral{typeName: "sb.OneofGroup"}
+		lit.addString("Name", oneof.Name)
+
+		if rules := optionExtension(od.Options(), "buf.validate.oneof"); rules != nil {
+			if required := rules.Descriptor().Fields().ByName("required"); required != nil && rules.Get(required).Bool() {
+				oneof.Required = true
+				lit.add("Required", "true")
+			}
+		}
+
+		var oneofFieldLiterals []string
+		oneofFields := od.Fields()
+		for j := range oneofFields.Len() {
+			fd := oneofFields.Get(j)
+			f := im.field(fd)
+			oneof.Fields[uint32(fd.Number())] = f.builder
+			oneofFieldLiterals = append(oneofFieldLiterals, fmt.Sprintf("%d: %s", fd.Number(), f.expr))
+		}
+		lit.add("Fields", "sb.OneofFields{"+strings.Join(oneofFieldLiterals, ", ")+"}")
+
+		oneof.Doc, oneof.TrailingComment = descriptorComments(od)
+		lit.addString("Doc", oneof.Doc)
+		lit.addString("TrailingComment", oneof.TrailingComment)
+
+		var optionLiterals []string
+		oneof.Options, optionLiterals = im.importOptions(fmt.Sprintf("the oneof %q", od.FullName()), od.Options())
+		if len(optionLiterals) > 0 {
+			lit.add("Options", "[]sb.ProtoOption{"+strings.Join(optionLiterals, ", ")+"}")
+		}
+
+		m.schema.NewOneof(oneof)
+		im.out.source.init = append(im.out.source.init, fmt.Sprintf("%s.NewOneof(%s)", m.varName, lit.String()))
+	}
+
+	im.importMessageRules(m)
+}
+
+// Converts the protovalidate rules of a message into CEL options and protovalidate oneofs.
+func (im *protoImporter) importMessageRules(m *importedMessage) {
+	rules := optionExtension(m.desc.Options(), "buf.validate.message")
+	if rules == nil {
+		return
+	}
+
+	rules.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch fd.Name() {
+		case "disabled":
+			if v.Bool() {
+				m.schema.Options = append(m.schema.Options, Options.DisableValidator)
+				im.out.source.init = append(im.out.source.init, fmt.Sprintf("%s.Options = append(%s.Options, sb.Options.DisableValidator)", m.varName, m.varName))
+			}
+		case "cel":
+			list := v.List()
+			for i := range list.Len() {
+				rule := list.Get(i).Message()
+				get := func(n protoreflect.Name) string {
+					return rule.Get(rule.Descriptor().Fields().ByName(n)).String()
+				}
+
+				m.schema.CelOption(get("id"), get("message"), get("expression"))
+				im.out.source.init = append(im.out.source.init, fmt.Sprintf("%s.CelOption(%q, %q, %q)", m.varName, get("id"), get("message"), get("expression")))
+			}
+		case "oneof":
+			list := v.List()
+			for i := range list.Len() {
+				rule := list.Get(i).Message()
+				fieldsList := rule.Get(rule.Descriptor().Fields().ByName("fields")).List()
+				required := rule.Get(rule.Descriptor().Fields().ByName("required")).Bool()
+
+				var names []string
+				for j := range fieldsList.Len() {
+					names = append(names, fieldsList.Get(j).String())
+				}
+
+				m.schema.Options = append(m.schema.Options, ProtoValidateOneof(required, names...))
+
+				args := strconv.FormatBool(required)
+				for _, n := range names {
+					args += ", " + strconv.Quote(n)
+				}
+				im.out.source.init = append(im.out.source.init, fmt.Sprintf("%s.Options = append(%s.Options, sb.ProtoValidateOneof(%s))", m.varName, m.varName, args))
+			}
+		default:
+			fmt.Printf("Warning: the rule (buf.validate.message).%s of the message %q could not be imported, because it is not supported.\n", fd.Name(), m.desc.FullName())
+		}
+
+		return true
+	})
+}
+
+// Creates a service with its handlers. Streaming methods are not supported, so they are skipped with a warning.
+func (im *protoImporter) importService(file *FileSchema, fileVar string, sd protoreflect.ServiceDescriptor) {
+	name := string(sd.Name())
+	if !strings.HasSuffix(name, "Service") {
+		fmt.Printf("Warning: the service %q will be renamed to %q, because the names of services always end with \"Service\".\n", name, name+"Service")
+	}
+
+	schema := ServiceSchema{Resource: strings.TrimSuffix(name, "Service"), Handlers: make(HandlersMap)}
+	lit := structLiteral{typeName: "sb.ServiceSchema"}
+	lit.addString("Resource", schema.Resource)
+
+	var handlerLiterals []string
+
+	methods := sd.Methods()
+	for i := range methods.Len() {
+		md := methods.Get(i)
+		if md.IsStreamingClient() || md.IsStreamingServer() {
+			fmt.Printf("Warning: the streaming method %q was skipped, because streaming methods are not supported.\n", md.FullName())
+			continue
+		}
+
+		request, requestExpr := im.externalMessage(md.Input())
+		response, responseExpr := im.externalMessage(md.Output())
+
+		handler := Handler{Request: request, Response: response}
+		handler.Doc, handler.TrailingComment = descriptorComments(md)
+
+		handlerLit := structLiteral{}
+		handlerLit.add("Request", requestExpr)
+		handlerLit.add("Response", responseExpr)
+		handlerLit.addString("Doc", handler.Doc)
+		handlerLit.addString("TrailingComment", handler.TrailingComment)
+
+		if options, _ := im.importOptions(fmt.Sprintf("the method %q", md.FullName()), md.Options()); len(options) > 0 {
+			fmt.Printf("Warning: the options of the method %q were not imported, because handlers do not support options.\n", md.FullName())
+		}
+
+		schema.Handlers[string(md.Name())] = handler
+		handlerLiterals = append(handlerLiterals, fmt.Sprintf("%q: %s", md.Name(), handlerLit.String()))
+	}
+	lit.add("Handlers", "sb.HandlersMap{"+strings.Join(handlerLiterals, ", ")+"}")
+
+	var optionLiterals []string
+	schema.Options, optionLiterals = im.importOptions(fmt.Sprintf("the service %q", sd.FullName()), sd.Options())
+	if len(optionLiterals) > 0 {
+		lit.add("Options", "[]sb.ProtoOption{"+strings.Join(optionLiterals, ", ")+"}")
+	}
+
+	schema.Doc, schema.TrailingComment = descriptorComments(sd)
+	lit.addString("Doc", schema.Doc)
+	lit.addString("TrailingComment", schema.TrailingComment)
+
+	im.out.Services[schema.Resource+"Service"] = file.NewService(schema)
+	im.out.source.init = append(im.out.source.init, fmt.Sprintf("%s.NewService(%s)", fileVar, lit.String()))
+}
@@ -0,0 +1,189 @@
+package protoschema_test
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportProto(t *testing.T) {
+	protoRoot := t.TempDir()
+
+	source := `// The store.
+syntax = "proto3";
+
+package store.v1;
+
+import "buf/validate/validate.proto";
+import "google/protobuf/timestamp.proto";
+
+option go_package = "github.com/Rick-Phoenix/protoschema/gen/storev1";
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  // The item is available.
+  STATUS_ACTIVE = 1;
+}
+
+// An item in the store.
+message Item {
+  option (buf.validate.message).cel = {
+    id: "price_check"
+    message: "the price must be positive"
+    expression: "this.price > 0.0"
+  };
+
+  reserved 20, 30 to 40;
+
+  message Location {
+    string shelf = 1 [(buf.validate.field).string = {in: ["A", "B"]}];
+  }
+
+  int64 id = 1 [(buf.validate.field).int64.gt = 0];
+  string email = 2 [(buf.validate.field).string = {min_len: 2, email: true}];
+  Status status = 3 [(buf.validate.field).enum.defined_only = true];
+  repeated string tags = 4 [(buf.validate.field).repeated = {unique: true, items: {string: {max_len: 10}}}];
+  map<string, double> prices = 5 [(buf.validate.field).map = {min_pairs: 1, values: {double: {gte: 0}}}];
+  // Where the item is stored.
+  Location location = 6;
+  google.protobuf.Timestamp created_at = 7 [(buf.validate.field).timestamp.lt_now = true];
+  optional string note = 8 [(buf.validate.field).required = true];
+  double price = 9;
+
+  oneof discount {
+    option (buf.validate.oneof).required = true;
+    uint32 percentage = 10 [(buf.validate.field).uint32.lte = 100];
+    double amount = 11;
+  }
+}
+
+message GetItemRequest {
+  int64 id = 1;
+}
+
+service ItemService {
+  // Returns an item.
+  rpc GetItem(GetItemRequest) returns (Item);
+}
+`
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(protoRoot, "store/v1"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(protoRoot, "store/v1/store.proto"), []byte(source), 0o644))
+
+	pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:               "store.v1",
+		GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/storev1",
+		ProtoRoot:          protoRoot,
+		ConverterOutputDir: filepath.Join(protoRoot, "converter"),
+	})
+
+	imported, err := pkg.ImportProto("store/v1/store.proto")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Len(t, imported.Files, 1)
+	assert.Contains(t, imported.Services, "ItemService")
+	assert.Equal(t, "Item.Location", imported.GetMessage("Item.Location").GetName())
+	assert.Equal(t, "Status", imported.GetEnum("Status").Name)
+
+	// Files from other packages cannot be imported
+	otherPkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:      "other.v1",
+		GoPackage: "github.com/Rick-Phoenix/protoschema/gen/otherv1",
+		ProtoRoot: protoRoot,
+	})
+
+	_, err = otherPkg.ImportProto("store/v1/store.proto")
+	assert.ErrorContains(t, err, `belongs to the package "store.v1"`)
+
+	// The imported messages can be referenced by new schemas
+	pkg.NewFile(sb.FileSchema{Name: "order"}).NewMessage(sb.MessageSchema{
+		Name:   "Order",
+		Fields: sb.FieldsMap{1: sb.Repeated("items", sb.MsgField("", imported.GetMessage("Item")))},
+	})
+
+	files, err := pkg.TryBuildFiles()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	item := files[0].Messages[0]
+	assert.Equal(t, "Item", item.Name)
+	assert.Equal(t, "An item in the store.", item.Doc)
+	assert.Equal(t, []uint{20}, item.ReservedNumbers)
+	assert.Equal(t, []sb.Range{{30, 40}}, item.ReservedRanges)
+
+	fields := make(map[string]sb.FieldData)
+	for _, f := range item.Fields {
+		fields[f.Name] = f
+	}
+
+	assert.Equal(t, map[string]any{"gt": int64(0)}, fields["id"].Rules)
+	assert.Equal(t, true, fields["email"].Rules["email"])
+	assert.Equal(t, true, fields["note"].Optional)
+	assert.Equal(t, "Where the item is stored.", fields["location"].Doc)
+	assert.Equal(t, map[string]any{"lte": uint32(100)}, item.Oneofs[0].Fields[0].Rules)
+
+	// The original file is replaced by the generated one
+	assert.NoError(t, os.Remove(filepath.Join(protoRoot, "store/v1/store.proto")))
+	assert.NoError(t, pkg.Generate())
+
+	content, err := os.ReadFile(filepath.Join(protoRoot, "store/v1/store.proto"))
+	assert.NoError(t, err)
+
+	for _, exp := range []string{
+		"(buf.validate.field).string.email = true",
+		"(buf.validate.field).repeated.items = {string: {max_len: 10}}",
+		"(buf.validate.field).required = true",
+		"oneof discount {\n    option (buf.validate.oneof).required = true;",
+		"reserved 20;",
+		"rpc GetItem(GetItemRequest) returns(Item);",
+	} {
+		assert.Contains(t, string(content), exp)
+	}
+
+	// The generated file can be imported again
+	reimportPkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:      "store.v1",
+		GoPackage: "github.com/Rick-Phoenix/protoschema/gen/storev1",
+		ProtoRoot: protoRoot,
+	})
+
+	reimported, err := reimportPkg.ImportProto("store/v1/store.proto")
+	if assert.NoError(t, err) {
+		assert.Equal(t, true, reimported.GetMessage("Item").GetField("email") != nil)
+	}
+
+	goSource, err := imported.GoSource("schemas", "storePkg")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = parser.ParseFile(token.NewFileSet(), "store.go", goSource, 0)
+	assert.NoError(t, err)
+
+	for _, exp := range []string{
+		`storePkg.NewFile(sb.FileSchema{Name: "store", Syntax: "proto3", Doc: "The store."})`,
+		`itemMsg.NestedMessage(sb.MessageSchema{Name: "Location"})`,
+		`1: sb.Int64("id").Gt(0)`,
+		`2: sb.String("email").MinLen(2).Email()`,
+		`3: sb.EnumField("status", statusEnum).DefinedOnly()`,
+		`4: sb.Repeated("tags", sb.String("").MaxLen(10)).Unique()`,
+		`5: sb.Map("prices", sb.String(""), sb.Double("").Gte(0)).MinPairs(1)`,
+		`6: sb.MsgField("location", itemLocationMsg).Doc("Where the item is stored.")`,
+		`7: sb.Timestamp("created_at").LtNow()`,
+		`8: sb.String("note").Required().Optional()`,
+		`itemMsg.NewOneof(sb.OneofGroup{Name: "discount", Required: true, Fields: sb.OneofFields{10: sb.UInt32("percentage").Lte(100), 11: sb.Double("amount")}})`,
+		`itemMsg.CelOption("price_check", "the price must be positive", "this.price > 0.0")`,
+		`"GetItem": {Request: getItemRequestMsg, Response: itemMsg, Doc: "Returns an item."}`,
+	} {
+		assert.Contains(t, string(goSource), exp)
+	}
+
+}
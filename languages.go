@@ -0,0 +1,210 @@
+package protoschema
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// A language whose file options can be derived from the name of the package.
+type Language string
+
+const (
+	LanguageJava   Language = "java"
+	LanguageCsharp Language = "csharp"
+	LanguageObjc   Language = "objc"
+	LanguagePhp    Language = "php"
+	LanguageRuby   Language = "ruby"
+	LanguageSwift  Language = "swift"
+)
+
+// The file options that define the package, namespace or prefix used by the code generators of each language.
+// In the package config, these define the values for all of its files. In a FileSchema, they override the values of the package for that file.
+type LanguageOptions struct {
+	// (Default: the GoPackage of the package config) The value of the go_package option, which is emitted in every file.
+	GoPackage string
+	// (Default: false) Disables the go_package option. In a FileSchema, a value that is not nil overrides the one of the package, so proto.Bool(false) emits the go_package option in a file of a package where it is disabled.
+	DisableGoPackage *bool
+	// The languages for which the options that are not explicitly defined are derived from the name of the package (i.e. "myapp.v1" -> "com.myapp.v1" for java_package).
+	// In a FileSchema, a nil list inherits the languages of the package, while a list that is not nil (even if empty) replaces them.
+	Languages   []Language
+	JavaPackage string
+	// (Default: true, when the java options are emitted)
+	JavaMultipleFiles *bool
+	CsharpNamespace   string
+	ObjcClassPrefix   string
+	PhpNamespace      string
+	RubyPackage       string
+	SwiftPrefix       string
+}
+
+// Returns a copy of the options where the empty values are replaced with the values of the parent.
+func (o LanguageOptions) inherit(parent LanguageOptions) LanguageOptions {
+	pick := func(v, parentV string) string {
+		if v == "" {
+			return parentV
+		}
+		return v
+	}
+
+	o.GoPackage = pick(o.GoPackage, parent.GoPackage)
+	o.JavaPackage = pick(o.JavaPackage, parent.JavaPackage)
+	o.CsharpNamespace = pick(o.CsharpNamespace, parent.CsharpNamespace)
+	o.ObjcClassPrefix = pick(o.ObjcClassPrefix, parent.ObjcClassPrefix)
+	o.PhpNamespace = pick(o.PhpNamespace, parent.PhpNamespace)
+	o.RubyPackage = pick(o.RubyPackage, parent.RubyPackage)
+	o.SwiftPrefix = pick(o.SwiftPrefix, parent.SwiftPrefix)
+
+	if o.DisableGoPackage == nil {
+		o.DisableGoPackage = parent.DisableGoPackage
+	}
+
+	if o.JavaMultipleFiles == nil {
+		o.JavaMultipleFiles = parent.JavaMultipleFiles
+	}
+
+	if o.Languages == nil {
+		o.Languages = parent.Languages
+	}
+
+	return o
+}
+
+var versionSegmentRegex = regexp.MustCompile(`^v\d+((alpha|beta)\d*)?$`)
+
+// Converts each segment of the package name to pascal case (i.e. "my_app.v1" -> ["MyApp", "V1"]).
+func pascalCaseSegments(pkgName string) []string {
+	var out []string
+
+	for segment := range strings.SplitSeq(pkgName, ".") {
+		var sb strings.Builder
+		for word := range strings.SplitSeq(segment, "_") {
+			if word != "" {
+				sb.WriteString(strings.ToUpper(word[:1]) + word[1:])
+			}
+		}
+		out = append(out, sb.String())
+	}
+
+	return out
+}
+
+// Derives the objc class prefix from the initials of the package segments, excluding the version, as per the buf convention ("acme.weather.v1" -> "AWX").
+func objcClassPrefix(pkgName string) string {
+	var sb strings.Builder
+
+	for segment := range strings.SplitSeq(pkgName, ".") {
+		if segment != "" && !versionSegmentRegex.MatchString(segment) {
+			sb.WriteString(strings.ToUpper(segment[:1]))
+		}
+	}
+
+	prefix := sb.String()
+	for len(prefix) < 3 {
+		prefix += "X"
+	}
+
+	// The GPB prefix is reserved for the protobuf library
+	if prefix == "GPB" {
+		prefix = "GPX"
+	}
+
+	return prefix
+}
+
+// Returns the language options for the given file, resolving the values inherited from the package and those derived from the package name.
+func (f *FileSchema) languageOptions() []ProtoOption {
+	var pkgOptions LanguageOptions
+	var pkgName, goPackagePath, goPackageName string
+
+	if f.Package != nil {
+		pkgOptions = f.Package.languageOptions
+		pkgName = f.Package.GetName()
+		goPackagePath = f.Package.GetGoPackagePath()
+		goPackageName = f.Package.GetGoPackageName()
+	}
+
+	opts := f.LanguageOptions.inherit(pkgOptions)
+
+	enabled := make(map[Language]bool)
+	for _, l := range opts.Languages {
+		enabled[l] = true
+	}
+
+	segments := pascalCaseSegments(pkgName)
+
+	derive := func(value string, lang Language, derived func() string) string {
+		if value == "" && enabled[lang] && pkgName != "" {
+			return derived()
+		}
+		return value
+	}
+
+	var out []ProtoOption
+
+	if opts.DisableGoPackage == nil || !*opts.DisableGoPackage {
+		goPackage := opts.GoPackage
+		if goPackage == "" && goPackagePath != "" {
+			goPackage = goPackagePath
+			if goPackageName != "" && goPackageName != path.Base(goPackagePath) {
+				goPackage += ";" + goPackageName
+			}
+		}
+
+		if goPackage != "" {
+			out = append(out, ProtoOption{Name: "go_package", Value: goPackage})
+		}
+	}
+
+	if javaPackage := derive(opts.JavaPackage, LanguageJava, func() string { return "com." + pkgName }); javaPackage != "" {
+		out = append(out, ProtoOption{Name: "java_package", Value: javaPackage})
+
+		multipleFiles := true
+		if opts.JavaMultipleFiles != nil {
+			multipleFiles = *opts.JavaMultipleFiles
+		}
+		out = append(out, ProtoOption{Name: "java_multiple_files", Value: multipleFiles})
+	} else if opts.JavaMultipleFiles != nil {
+		out = append(out, ProtoOption{Name: "java_multiple_files", Value: *opts.JavaMultipleFiles})
+	}
+
+	stringOptions := []struct {
+		name    string
+		value   string
+		lang    Language
+		derived func() string
+	}{
+		{"csharp_namespace", opts.CsharpNamespace, LanguageCsharp, func() string { return strings.Join(segments, ".") }},
+		{"objc_class_prefix", opts.ObjcClassPrefix, LanguageObjc, func() string { return objcClassPrefix(pkgName) }},
+		{"php_namespace", opts.PhpNamespace, LanguagePhp, func() string { return strings.Join(segments, `\`) }},
+		{"ruby_package", opts.RubyPackage, LanguageRuby, func() string { return strings.Join(segments, "::") }},
+		{"swift_prefix", opts.SwiftPrefix, LanguageSwift, func() string { return strings.Join(segments, "_") + "_" }},
+	}
+
+	for _, o := range stringOptions {
+		if value := derive(o.value, o.lang, o.derived); value != "" {
+			out = append(out, ProtoOption{Name: o.name, Value: value})
+		}
+	}
+
+	// Options that are defined manually in the schema take precedence
+	var filtered []ProtoOption
+	for _, o := range out {
+		if !hasOption(f.Options, o.Name) {
+			filtered = append(filtered, o)
+		}
+	}
+
+	return filtered
+}
+
+// Checks if an option with the given name is present in the list.
+func hasOption(opts []ProtoOption, name string) bool {
+	for _, o := range opts {
+		if o.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,94 @@
+package protoschema_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestLanguageOptions(t *testing.T) {
+	tmpDir := t.TempDir()
+	multipleFiles := false
+
+	pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:               "acme.weather_data.v1",
+		GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/weatherv1",
+		ProtoRoot:          tmpDir,
+		ConverterOutputDir: filepath.Join(tmpDir, "converter"),
+		LanguageOptions: sb.LanguageOptions{
+			Languages:   []sb.Language{sb.LanguageJava, sb.LanguageCsharp, sb.LanguageObjc, sb.LanguagePhp, sb.LanguageRuby, sb.LanguageSwift},
+			RubyPackage: "Acme::Weather",
+		},
+	})
+
+	pkg.NewFile(sb.FileSchema{Name: "weather"})
+	pkg.NewFile(sb.FileSchema{
+		Name: "overrides",
+		LanguageOptions: sb.LanguageOptions{
+			JavaPackage:       "io.acme.weather",
+			JavaMultipleFiles: &multipleFiles,
+			Languages:         []sb.Language{sb.LanguageJava},
+		},
+		Options: []sb.ProtoOption{{Name: "go_package", Value: "example.com/custom;custompb"}},
+	})
+
+	assert.NoError(t, pkg.Generate())
+
+	generated, err := os.ReadFile(filepath.Join(tmpDir, "acme/weather_data/v1/weather.proto"))
+	assert.NoError(t, err)
+
+	for _, expected := range []string{
+		`option go_package = "github.com/Rick-Phoenix/protoschema/gen/weatherv1";`,
+		`option java_package = "com.acme.weather_data.v1";`,
+		`option java_multiple_files = true;`,
+		`option csharp_namespace = "Acme.WeatherData.V1";`,
+		`option objc_class_prefix = "AWX";`,
+		`option php_namespace = "Acme\\WeatherData\\V1";`,
+		`option ruby_package = "Acme::Weather";`,
+		`option swift_prefix = "Acme_WeatherData_V1_";`,
+	} {
+		assert.Contains(t, string(generated), expected)
+	}
+
+	overrides, err := os.ReadFile(filepath.Join(tmpDir, "acme/weather_data/v1/overrides.proto"))
+	assert.NoError(t, err)
+
+	for _, expected := range []string{
+		`option go_package = "example.com/custom;custompb";`,
+		`option java_package = "io.acme.weather";`,
+		`option java_multiple_files = false;`,
+		`option ruby_package = "Acme::Weather";`,
+	} {
+		assert.Contains(t, string(overrides), expected)
+	}
+
+	assert.NotContains(t, string(overrides), "csharp_namespace")
+	assert.NotContains(t, string(overrides), "github.com/Rick-Phoenix/protoschema/gen/weatherv1")
+
+	disabled := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:            "plain.v1",
+		GoPackage:       "github.com/Rick-Phoenix/protoschema/gen/plainv1",
+		ProtoRoot:       t.TempDir(),
+		LanguageOptions: sb.LanguageOptions{DisableGoPackage: proto.Bool(true), Languages: []sb.Language{sb.LanguageJava}},
+	})
+	disabled.NewFile(sb.FileSchema{Name: "plain"})
+
+	// A file can enable the go_package option again, and clear the languages of the package with an empty list
+	disabled.NewFile(sb.FileSchema{
+		Name:            "enabled",
+		LanguageOptions: sb.LanguageOptions{DisableGoPackage: proto.Bool(false), Languages: []sb.Language{}},
+	})
+
+	files, err := disabled.TryBuildFiles()
+	if assert.NoError(t, err) {
+		assert.Equal(t, []sb.ProtoOption{
+			{Name: "java_package", Value: "com.plain.v1"},
+			{Name: "java_multiple_files", Value: true},
+		}, files[0].Options)
+		assert.Equal(t, []sb.ProtoOption{{Name: "go_package", Value: "github.com/Rick-Phoenix/protoschema/gen/plainv1"}}, files[1].Options)
+	}
+}
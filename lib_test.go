@@ -189,8 +189,8 @@ func TestGeneration(t *testing.T) {
 	UserSchema.NewOneof(sb.OneofGroup{
 		Name: "myoneof",
 		Fields: sb.OneofFields{
-			9:  sb.String("example"),
-			10: sb.Int32("another"),
+			10: sb.String("example"),
+			11: sb.Int32("another"),
 		},
 	})
 
@@ -200,6 +200,7 @@ func TestGeneration(t *testing.T) {
 			0: "VAL_1",
 			1: "VAL_2",
 		},
+		Values:          []sb.EnumValue{{Name: "VAL_2_ALIAS", Number: 1}},
 		Options:         []sb.ProtoOption{sb.Options.AllowAlias},
 		ReservedNames:   []string{"name1", "name2"},
 		ReservedNumbers: []int32{10, 11},
@@ -0,0 +1,409 @@
+package protoschema
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// A style rule that is enforced by the linter. The names match the equivalent rules in the buf cli.
+type LintRule string
+
+const (
+	LintFieldLowerSnakeCase     LintRule = "FIELD_LOWER_SNAKE_CASE"
+	LintOneofLowerSnakeCase     LintRule = "ONEOF_LOWER_SNAKE_CASE"
+	LintMessagePascalCase       LintRule = "MESSAGE_PASCAL_CASE"
+	LintEnumPascalCase          LintRule = "ENUM_PASCAL_CASE"
+	LintEnumValueUpperSnakeCase LintRule = "ENUM_VALUE_UPPER_SNAKE_CASE"
+	LintEnumValuePrefix         LintRule = "ENUM_VALUE_PREFIX"
+	LintEnumZeroValueSuffix     LintRule = "ENUM_ZERO_VALUE_SUFFIX"
+	LintServicePascalCase       LintRule = "SERVICE_PASCAL_CASE"
+	LintServiceSuffix           LintRule = "SERVICE_SUFFIX"
+	LintRPCPascalCase           LintRule = "RPC_PASCAL_CASE"
+	LintRPCRequestStandardName  LintRule = "RPC_REQUEST_STANDARD_NAME"
+	LintRPCResponseStandardName LintRule = "RPC_RESPONSE_STANDARD_NAME"
+	LintPackageVersionSuffix    LintRule = "PACKAGE_VERSION_SUFFIX"
+	LintPackageDirectoryMatch   LintRule = "PACKAGE_DIRECTORY_MATCH"
+	LintFileLowerSnakeCase      LintRule = "FILE_LOWER_SNAKE_CASE"
+	// This rule has no equivalent in the buf cli: the fields referenced in the CEL expressions of the protovalidate rules (such as this.title) must exist.
+	LintCelFieldReference LintRule = "CEL_FIELD_REFERENCE"
+)
+
+// All the rules that are enforced by the linter.
+var LintRules = []LintRule{
+	LintFieldLowerSnakeCase,
+	LintOneofLowerSnakeCase,
+	LintMessagePascalCase,
+	LintEnumPascalCase,
+	LintEnumValueUpperSnakeCase,
+	LintEnumValuePrefix,
+	LintEnumZeroValueSuffix,
+	LintServicePascalCase,
+	LintServiceSuffix,
+	LintRPCPascalCase,
+	LintRPCRequestStandardName,
+	LintRPCResponseStandardName,
+	LintPackageVersionSuffix,
+	LintPackageDirectoryMatch,
+	LintFileLowerSnakeCase,
+	LintCelFieldReference,
+}
+
+// The rules that have no equivalent in the buf cli, which are left out of the lint settings of the buf.yaml file.
+var protoschemaLintRules = []LintRule{LintCelFieldReference}
+
+// The key used in the Metadata of a schema to ignore some lint rules for that schema and for all the elements inside it.
+// The value can be a LintRule, a string, or a slice of either of them. The special value "all" disables every rule.
+const LintIgnoreKey = "lint:ignore"
+
+// The configuration for the linter.
+type LintConfig struct {
+	// If true, the linter will not run during generation.
+	Skip bool
+	// The rules that should not be enforced.
+	Disable []LintRule
+	// If true, the issues found by the linter will stop the generation instead of being printed as warnings.
+	Strict bool
+}
+
+// A style violation found by the linter.
+type LintIssue struct {
+	Rule LintRule
+	// The file that contains the element with the issue.
+	File string
+	// The fully qualified name of the element with the issue.
+	Element string
+	Message string
+}
+
+func (li LintIssue) String() string {
+	return fmt.Sprintf("%s: [%s] %s: %s", li.File, li.Rule, li.Element, li.Message)
+}
+
+var (
+	lowerSnakeCaseRegex = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+	upperSnakeCaseRegex = regexp.MustCompile(`^[A-Z][A-Z0-9]*(_[A-Z0-9]+)*$`)
+	pascalCaseRegex     = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`)
+	versionSuffixRegex  = regexp.MustCompile(`^v\d+((alpha|beta)\d*)?(test.*)?$`)
+	// The references to fields in CEL expressions, with an opening parenthesis if the last name is a method call
+	celReferenceRegex = regexp.MustCompile(`\bthis((?:\s*\.\s*[A-Za-z_][A-Za-z0-9_]*)+)(\s*\()?`)
+	celStringRegex    = regexp.MustCompile(`"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'`)
+	celOptionRegex    = regexp.MustCompile(`id:\s*("(?:[^"\\]|\\.)*")(?s:.*?)expression:\s*("(?:[^"\\]|\\.)*")`)
+)
+
+// Runs the linter on the processed data of a package's files, and returns the issues found.
+func Lint(files []FileData, conf LintConfig) []LintIssue {
+	l := linter{conf: conf}
+
+	for _, f := range files {
+		l.lintFile(f)
+	}
+
+	return l.issues
+}
+
+// Processes the files of this package and runs the linter on them, using the LintConfig from the package's configuration.
+func (p *ProtoPackage) Lint() ([]LintIssue, error) {
+	files, err := p.TryBuildFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	return Lint(files, p.lintConfig), nil
+}
+
+// Prints the issues as warnings, or returns them as an error if the config is strict.
+func reportLintIssues(issues []LintIssue, conf LintConfig) error {
+	var errs error
+
+	for _, issue := range issues {
+		if conf.Strict {
+			errs = errors.Join(errs, errors.New(issue.String()))
+		} else {
+			fmt.Printf("Lint warning: %s\n", issue.String())
+		}
+	}
+
+	return issuesError(indentErrors("Lint issues found", errs))
+}
+
+type linter struct {
+	conf   LintConfig
+	issues []LintIssue
+	file   string
+}
+
+func (l *linter) report(rule LintRule, ignored []LintRule, element, msg string, args ...any) {
+	if slices.Contains(l.conf.Disable, rule) || slices.Contains(ignored, rule) {
+		return
+	}
+
+	l.issues = append(l.issues, LintIssue{Rule: rule, File: l.file, Element: element, Message: fmt.Sprintf(msg, args...)})
+}
+
+func (l *linter) lintFile(f FileData) {
+	pkgName := f.Package.GetName()
+	l.file = path.Join(f.Package.GetBasePath(), f.Name)
+	ignored := ignoredLintRules(nil, f.Metadata)
+
+	parts := strings.Split(pkgName, ".")
+	if !versionSuffixRegex.MatchString(parts[len(parts)-1]) {
+		l.report(LintPackageVersionSuffix, ignored, pkgName, "Package name should end with a version suffix (i.e. \"myapp.v1\").")
+	}
+
+	if expected := strings.ReplaceAll(pkgName, ".", "/"); f.Package.GetBasePath() != expected {
+		l.report(LintPackageDirectoryMatch, ignored, pkgName, "Files for this package should be in the %q directory, but they are in %q.", expected, f.Package.GetBasePath())
+	}
+
+	if base := strings.TrimSuffix(f.Name, ".proto"); !lowerSnakeCaseRegex.MatchString(base) {
+		l.report(LintFileLowerSnakeCase, ignored, f.Name, "File name should be lower_snake_case.")
+	}
+
+	for _, e := range f.Enums {
+		l.lintEnum(e, pkgName, ignored)
+	}
+
+	for _, m := range f.Messages {
+		l.lintMessage(m, pkgName, ignored)
+	}
+
+	for _, s := range f.Services {
+		l.lintService(s, pkgName, ignored)
+	}
+}
+
+func (l *linter) lintMessage(m MessageData, prefix string, ignored []LintRule) {
+	fullName := prefix + "." + m.Name
+	ignored = ignoredLintRules(ignored, m.Metadata)
+
+	if !pascalCaseRegex.MatchString(m.Name) {
+		l.report(LintMessagePascalCase, ignored, fullName, "Message name should be PascalCase.")
+	}
+
+	for _, f := range m.Fields {
+		l.lintFieldName(f.Name, fullName, ignored)
+		l.lintFieldCel(f, fullName, ignored)
+	}
+
+	for _, of := range m.Oneofs {
+		oneofIgnored := ignoredLintRules(ignored, of.Metadata)
+
+		if !lowerSnakeCaseRegex.MatchString(of.Name) {
+			l.report(LintOneofLowerSnakeCase, oneofIgnored, fullName+"."+of.Name, "Oneof name should be lower_snake_case.")
+		}
+
+		for _, f := range of.Fields {
+			l.lintFieldName(f.Name, fullName, oneofIgnored)
+			l.lintFieldCel(f, fullName, oneofIgnored)
+		}
+	}
+
+	messageFields := make(map[string]FieldData)
+	for _, f := range m.Fields {
+		messageFields[f.Name] = f
+	}
+	for _, of := range m.Oneofs {
+		for _, f := range of.Fields {
+			messageFields[f.Name] = f
+		}
+	}
+
+	for _, opt := range m.Options {
+		if celOpt, isCel := opt.Value.(CelOption); isCel && opt.Name == "(buf.validate.message).cel" {
+			l.lintCelReferences(celOpt.Id, celOpt.Expression, func(name string) (FieldData, bool) {
+				f, exists := messageFields[name]
+				return f, exists
+			}, fullName, ignored)
+		}
+	}
+
+	for _, e := range m.Enums {
+		l.lintEnum(e, fullName, ignored)
+	}
+
+	for _, nested := range m.Messages {
+		l.lintMessage(nested, fullName, ignored)
+	}
+}
+
+func (l *linter) lintFieldName(name, msgName string, ignored []LintRule) {
+	if !lowerSnakeCaseRegex.MatchString(name) {
+		l.report(LintFieldLowerSnakeCase, ignored, msgName+"."+name, "Field name should be lower_snake_case.")
+	}
+}
+
+// Checks the references in the cel options of a field, where "this" is the value of the field. They are only checked for the fields with a message type that is defined with this library.
+func (l *linter) lintFieldCel(f FieldData, msgName string, ignored []LintRule) {
+	if f.MessageRef == nil || f.Repeated || f.IsMap {
+		return
+	}
+
+	for _, opt := range f.Options {
+		if !strings.HasPrefix(opt, "(buf.validate.field).cel") {
+			continue
+		}
+
+		for _, match := range celOptionRegex.FindAllStringSubmatch(opt, -1) {
+			id, idErr := strconv.Unquote(match[1])
+			expression, exprErr := strconv.Unquote(match[2])
+
+			if idErr == nil && exprErr == nil {
+				l.lintCelReferences(id, expression, schemaFieldLookup(f.MessageRef), msgName+"."+f.Name, ignored)
+			}
+		}
+	}
+}
+
+// Returns a function that looks up the fields of a message schema, or nil if the schema has no fields (as with the messages that are only used as references).
+func schemaFieldLookup(m *MessageSchema) func(name string) (FieldData, bool) {
+	if m == nil || (len(m.GetFields()) == 0 && len(m.oneofs) == 0) {
+		return nil
+	}
+
+	return func(name string) (FieldData, bool) {
+		f, exists := m.LookupField(name)
+		if !exists {
+			return FieldData{}, false
+		}
+
+		return f.GetData(), true
+	}
+}
+
+// Reports the references to fields (such as this.title or this.author.name) in a cel expression that do not exist in the message, starting from the fields returned by lookup.
+func (l *linter) lintCelReferences(id, expression string, lookup func(name string) (FieldData, bool), element string, ignored []LintRule) {
+	// The string literals are removed so that their contents are not mistaken for references
+	expression = celStringRegex.ReplaceAllString(expression, `""`)
+
+	for _, match := range celReferenceRegex.FindAllStringSubmatch(expression, -1) {
+		var names []string
+		for name := range strings.SplitSeq(match[1], ".") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+
+		// The last name is a method, such as in this.title.size()
+		if match[2] != "" {
+			names = names[:len(names)-1]
+		}
+
+		current := lookup
+		for i, name := range names {
+			if current == nil {
+				break
+			}
+
+			f, exists := current(name)
+			if !exists {
+				l.report(LintCelFieldReference, ignored, element, "The CEL expression of the rule %q references the unknown field %q.", id, "this."+strings.Join(names[:i+1], "."))
+				break
+			}
+
+			current = nil
+			if !f.Repeated && !f.IsMap {
+				current = schemaFieldLookup(f.MessageRef)
+			}
+		}
+	}
+}
+
+func (l *linter) lintEnum(e EnumGroup, prefix string, ignored []LintRule) {
+	fullName := prefix + "." + e.Name
+	ignored = ignoredLintRules(ignored, e.Metadata)
+
+	if !pascalCaseRegex.MatchString(e.Name) {
+		l.report(LintEnumPascalCase, ignored, fullName, "Enum name should be PascalCase.")
+	}
+
+	valuePrefix := strings.ToUpper(toSnakeCase(e.Name)) + "_"
+
+	for i, v := range e.Values {
+		name := v.Name
+		element := prefix + "." + name
+
+		if !upperSnakeCaseRegex.MatchString(name) {
+			l.report(LintEnumValueUpperSnakeCase, ignored, element, "Enum value name should be UPPER_SNAKE_CASE.")
+		}
+
+		if !strings.HasPrefix(name, valuePrefix) {
+			l.report(LintEnumValuePrefix, ignored, element, "Enum value name should be prefixed with %q.", valuePrefix)
+		}
+
+		if i == 0 && v.Number == 0 && !strings.HasSuffix(name, "_UNSPECIFIED") {
+			l.report(LintEnumZeroValueSuffix, ignored, element, "Enum zero value name should be suffixed with \"_UNSPECIFIED\" (i.e. %q).", valuePrefix+"UNSPECIFIED")
+		}
+	}
+}
+
+func (l *linter) lintService(s ServiceData, pkgName string, ignored []LintRule) {
+	name := addServiceSuffix(s.Resource)
+	fullName := pkgName + "." + name
+	ignored = ignoredLintRules(ignored, s.Metadata)
+
+	if !pascalCaseRegex.MatchString(name) {
+		l.report(LintServicePascalCase, ignored, fullName, "Service name should be PascalCase.")
+	}
+
+	if !strings.HasSuffix(name, "Service") {
+		l.report(LintServiceSuffix, ignored, fullName, "Service name should be suffixed with \"Service\".")
+	}
+
+	for _, h := range s.Handlers {
+		element := fullName + "." + h.Name
+		handlerIgnored := ignoredLintRules(ignored, h.Metadata)
+
+		if !pascalCaseRegex.MatchString(h.Name) {
+			l.report(LintRPCPascalCase, handlerIgnored, element, "RPC name should be PascalCase.")
+		}
+
+		if reqName := h.Request.GetName(); reqName != h.Name+"Request" && reqName != name+h.Name+"Request" {
+			l.report(LintRPCRequestStandardName, handlerIgnored, element, "Request type should be named %q, but it is %q.", h.Name+"Request", reqName)
+		}
+
+		if resName := h.Response.GetName(); resName != h.Name+"Response" && resName != name+h.Name+"Response" {
+			l.report(LintRPCResponseStandardName, handlerIgnored, element, "Response type should be named %q, but it is %q.", h.Name+"Response", resName)
+		}
+	}
+}
+
+// Returns the rules that are ignored by the parent schemas, together with those ignored in the given metadata.
+func ignoredLintRules(parent []LintRule, metadata map[string]any) []LintRule {
+	value, exists := metadata[LintIgnoreKey]
+	if !exists {
+		return parent
+	}
+
+	out := slices.Clone(parent)
+
+	add := func(rule string) {
+		if rule == "all" {
+			out = append(out, LintRules...)
+		} else {
+			out = append(out, LintRule(rule))
+		}
+	}
+
+	switch v := value.(type) {
+	case LintRule:
+		add(string(v))
+	case string:
+		add(v)
+	case []LintRule:
+		for _, rule := range v {
+			add(string(rule))
+		}
+	case []string:
+		for _, rule := range v {
+			add(rule)
+		}
+	default:
+		fmt.Printf("Invalid value for the %q metadata key: %v\n", LintIgnoreKey, value)
+	}
+
+	return out
+}
@@ -0,0 +1,111 @@
+package protoschema_test
+
+import (
+	"testing"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:      "lint",
+		GoPackage: "github.com/Rick-Phoenix/protoschema/gen/lint",
+		ProtoRoot: t.TempDir(),
+		Lint:      sb.LintConfig{Disable: []sb.LintRule{sb.LintFileLowerSnakeCase}},
+	})
+
+	file := pkg.NewFile(sb.FileSchema{Name: "LintFile"})
+
+	msg := file.NewMessage(sb.MessageSchema{
+		Name: "user_info",
+		Fields: sb.FieldsMap{
+			1: sb.String("userName"),
+			2: sb.String("email"),
+		},
+	})
+	msg.NewEnum(sb.EnumGroup{Name: "myenum", Members: sb.EnumMembers{0: "VAL_1", 1: "VAL_2"}})
+
+	file.NewEnum(sb.EnumGroup{Name: "Status", Members: sb.EnumMembers{0: "STATUS_UNSPECIFIED", 1: "STATUS_ACTIVE"}})
+
+	file.NewMessage(sb.MessageSchema{
+		Name:     "legacy_item",
+		Fields:   sb.FieldsMap{1: sb.String("ItemName")},
+		Metadata: map[string]any{sb.LintIgnoreKey: []sb.LintRule{sb.LintMessagePascalCase, sb.LintFieldLowerSnakeCase}},
+	})
+
+	legacyFile := pkg.NewFile(sb.FileSchema{
+		Name:     "legacy",
+		Metadata: map[string]any{sb.LintIgnoreKey: []sb.LintRule{sb.LintPackageVersionSuffix, sb.LintMessagePascalCase}},
+	})
+	legacyFile.NewMessage(sb.MessageSchema{Name: "old_item", Fields: sb.FieldsMap{1: sb.String("name")}})
+
+	GetUserRequest := file.NewMessage(sb.MessageSchema{Name: "GetUserRequest", Fields: sb.FieldsMap{1: sb.Int64("id")}})
+	UserResponse := file.NewMessage(sb.MessageSchema{Name: "UserResponse", Fields: sb.FieldsMap{1: sb.Int64("id")}})
+
+	file.NewService(sb.ServiceSchema{
+		Resource: "User",
+		Handlers: sb.HandlersMap{
+			"GetUser": {Request: GetUserRequest, Response: UserResponse},
+		},
+	})
+
+	issues, err := pkg.Lint()
+	assert.NoError(t, err)
+
+	found := make(map[sb.LintRule][]string)
+	for _, issue := range issues {
+		found[issue.Rule] = append(found[issue.Rule], issue.Element)
+	}
+
+	assert.Equal(t, map[sb.LintRule][]string{
+		sb.LintPackageVersionSuffix:    {"lint"},
+		sb.LintMessagePascalCase:       {"lint.user_info"},
+		sb.LintFieldLowerSnakeCase:     {"lint.user_info.userName"},
+		sb.LintEnumPascalCase:          {"lint.user_info.myenum"},
+		sb.LintEnumValuePrefix:         {"lint.user_info.VAL_1", "lint.user_info.VAL_2"},
+		sb.LintEnumZeroValueSuffix:     {"lint.user_info.VAL_1"},
+		sb.LintRPCResponseStandardName: {"lint.UserService.GetUser"},
+	}, found)
+}
+
+func TestLintCelReferences(t *testing.T) {
+	pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:      "lint.v1",
+		GoPackage: "github.com/Rick-Phoenix/protoschema/gen/lintv1",
+		ProtoRoot: t.TempDir(),
+	})
+
+	file := pkg.NewFile(sb.FileSchema{Name: "post"})
+
+	author := file.NewMessage(sb.MessageSchema{Name: "Author", Fields: sb.FieldsMap{1: sb.String("name")}})
+
+	post := file.NewMessage(sb.MessageSchema{
+		Name: "Post",
+		Fields: sb.FieldsMap{
+			1: sb.String("title"),
+			2: sb.MsgField("author", author).CelOption("author_name", "the author must have a name", "this.name.size() > 0 && this.nickname != ''"),
+		},
+	})
+
+	post.NewOneof(sb.OneofGroup{Name: "body", Fields: sb.OneofFields{3: sb.String("text")}})
+
+	post.CelOption("title_length", "the title is too short", "this.title.size() > 3 && has(this.text) && this.titel != 'this.missing'")
+	post.CelOption("author", "the author must have a name", "this.author.name != '' || this.author.alias == ''")
+
+	issues, err := pkg.Lint()
+	assert.NoError(t, err)
+
+	var messages []string
+	for _, issue := range issues {
+		if issue.Rule == sb.LintCelFieldReference {
+			messages = append(messages, issue.Element+": "+issue.Message)
+		}
+	}
+
+	assert.ElementsMatch(t, []string{
+		`lint.v1.Post.author: The CEL expression of the rule "author_name" references the unknown field "this.nickname".`,
+		`lint.v1.Post: The CEL expression of the rule "title_length" references the unknown field "this.titel".`,
+		`lint.v1.Post: The CEL expression of the rule "author" references the unknown field "this.author.alias".`,
+	}, messages)
+}
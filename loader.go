@@ -0,0 +1,830 @@
+package protoschema
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gopkg.in/yaml.v3"
+)
+
+// The constructors for the field types that can be used in schema documents, by their key.
+var documentFieldTypes = map[string]func(name string) FieldBuilder{
+	"string":     func(name string) FieldBuilder { return String(name) },
+	"bytes":      func(name string) FieldBuilder { return Bytes(name) },
+	"bool":       func(name string) FieldBuilder { return Bool(name) },
+	"int32":      func(name string) FieldBuilder { return Int32(name) },
+	"int64":      func(name string) FieldBuilder { return Int64(name) },
+	"uint32":     func(name string) FieldBuilder { return UInt32(name) },
+	"uint64":     func(name string) FieldBuilder { return UInt64(name) },
+	"sint32":     func(name string) FieldBuilder { return SInt32(name) },
+	"sint64":     func(name string) FieldBuilder { return SInt64(name) },
+	"fixed32":    func(name string) FieldBuilder { return Fixed32(name) },
+	"fixed64":    func(name string) FieldBuilder { return Fixed64(name) },
+	"sfixed32":   func(name string) FieldBuilder { return SFixed32(name) },
+	"sfixed64":   func(name string) FieldBuilder { return SFixed64(name) },
+	"float":      func(name string) FieldBuilder { return Float(name) },
+	"double":     func(name string) FieldBuilder { return Double(name) },
+	"timestamp":  func(name string) FieldBuilder { return Timestamp(name) },
+	"duration":   func(name string) FieldBuilder { return Duration(name) },
+	"any":        func(name string) FieldBuilder { return Any(name) },
+	"field_mask": func(name string) FieldBuilder { return FieldMask(name) },
+}
+
+// The methods of the field builders that cannot be used as rules in schema documents.
+var nonRuleMethods = []string{"build", "getdata", "getgotype", "getname", "getmessageref", "ismap", "isnonscalar", "isrepeated", "options", "repeatedoptions", "features"}
+
+// The keys that define the type of a field in schema documents.
+var fieldTypeKeys = slices.Concat([]string{"message", "enum"}, slices.Sorted(maps.Keys(documentFieldTypes)))
+
+// The keys that can be used in the fields of schema documents.
+var fieldKeys = slices.Concat([]string{"name", "number", "doc", "trailing_comment", "repeated", "map"}, fieldTypeKeys)
+
+// Matches the prefix and the line numbers in the errors from the yaml decoder, since the position is already included in the loader's errors.
+var yamlErrorRegex = regexp.MustCompile(`yaml: unmarshal errors:\n\s*|line \d+: `)
+
+var (
+	timestampType = reflect.TypeFor[*timestamppb.Timestamp]()
+	durationType  = reflect.TypeFor[*durationpb.Duration]()
+)
+
+// Creates the schemas described in a YAML or JSON document.
+type schemaLoader struct {
+	source   string
+	pkg      *ProtoPackage
+	messages map[string]*MessageSchema
+	enums    map[string]*EnumGroup
+	errors   error
+}
+
+// A message whose fields are added after all the messages and enums of the document have been created, so that they can reference each other.
+type pendingMessage struct {
+	schema *MessageSchema
+	fields *yaml.Node
+	oneofs *yaml.Node
+}
+
+// The extensions of the schema documents, which are also watched by the watch command of protoschema.
+var SchemaDocumentExtensions = []string{".yaml", ".yml", ".json"}
+
+// Reads a YAML or JSON document and returns the package that it describes. See LoadPackage for the structure of the document.
+func LoadPackageFile(path string) (*ProtoPackage, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read the schema document %q: %w", path, err)
+	}
+
+	return LoadPackage(content, path)
+}
+
+// Parses a YAML or JSON document and returns the package that it describes, which is created with the same constructors used for the schemas defined in go (so it is processed, validated and generated in the same way).
+// The source is used to indicate the position of the errors, which are reported as "<source>:<line>:<column>: <error>".
+//
+// The document contains the configuration of the package (with the snake_case names of the fields in ProtoPackageConfig) and its files, which contain enums, messages and services:
+//
+//	package:
+//	  name: store.v1
+//	  go_package: github.com/me/store/gen/storev1
+//	files:
+//	  - name: item
+//	    enums:
+//	      - name: Status
+//	        values: [{ name: STATUS_UNSPECIFIED, number: 0 }, { name: STATUS_ACTIVE, number: 1 }]
+//	    messages:
+//	      - name: Item
+//	        fields:
+//	          - { name: id, number: 1, int64: {} }
+//	          - { name: email, number: 2, string: { min_len: 2, email: true } }
+//	          - { name: status, number: 3, enum: { type: Status, defined_only: true } }
+//	          - { name: tags, number: 4, string: {}, repeated: { unique: true } }
+//	          - { name: notes, string: {}, repeated: true }
+//
+// Each field has a single type key (such as string, int32, timestamp, message or enum), whose value contains the rules of the field. Fields without a number are added to the FieldsList of the message.
+// The rules are the snake_case names of the methods of the field builder (for example, min_len calls MinLen), and they can be set to true for the methods without arguments, or to a list for the methods with multiple arguments.
+func LoadPackage(content []byte, source string) (*ProtoPackage, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+
+	l := &schemaLoader{source: source, messages: make(map[string]*MessageSchema), enums: make(map[string]*EnumGroup)}
+
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("%s: The schema document is empty.", source)
+	}
+
+	root := l.mapping(doc.Content[0], "package", "files")
+	if root == nil {
+		return nil, l.errors
+	}
+
+	if root["package"] == nil {
+		l.errorf(doc.Content[0], "The package is not defined.")
+		return nil, l.errors
+	}
+
+	conf, ok := l.packageConfig(root["package"])
+	if !ok {
+		return nil, l.errors
+	}
+
+	l.pkg = NewProtoPackage(conf)
+
+	var pending []pendingMessage
+	var services []*yaml.Node
+	var servicesFiles []*FileSchema
+
+	for _, fileNode := range l.sequence(root["files"]) {
+		f, fileMessages, fileServices := l.file(fileNode)
+		pending = append(pending, fileMessages...)
+		for _, s := range fileServices {
+			services = append(services, s)
+			servicesFiles = append(servicesFiles, f)
+		}
+	}
+
+	for _, m := range pending {
+		l.messageFields(m)
+	}
+
+	for i, s := range services {
+		l.service(servicesFiles[i], s)
+	}
+
+	if l.errors != nil {
+		return nil, l.errors
+	}
+
+	return l.pkg, nil
+}
+
+func (l *schemaLoader) errorf(n *yaml.Node, format string, args ...any) {
+	l.errors = errors.Join(l.errors, fmt.Errorf("%s:%d:%d: %s", l.source, n.Line, n.Column, fmt.Sprintf(format, args...)))
+}
+
+// Returns the entries of a mapping node, reporting the keys that are not allowed. A null node is treated as an empty mapping.
+func (l *schemaLoader) mapping(n *yaml.Node, allowedKeys ...string) map[string]*yaml.Node {
+	if n == nil || n.Tag == "!!null" {
+		return map[string]*yaml.Node{}
+	}
+
+	if n.Kind != yaml.MappingNode {
+		l.errorf(n, "Expected a mapping.")
+		return nil
+	}
+
+	out := make(map[string]*yaml.Node)
+
+	for i := 0; i < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+
+		if !slices.Contains(allowedKeys, key.Value) {
+			l.errorf(key, "Unknown key %q (allowed keys: %s).", key.Value, strings.Join(allowedKeys, ", "))
+			continue
+		}
+
+		if _, exists := out[key.Value]; exists {
+			l.errorf(key, "The key %q is defined more than once.", key.Value)
+			continue
+		}
+
+		out[key.Value] = value
+	}
+
+	return out
+}
+
+// Returns the items of a sequence node. A missing or null node is treated as an empty sequence.
+func (l *schemaLoader) sequence(n *yaml.Node) []*yaml.Node {
+	if n == nil || n.Tag == "!!null" {
+		return nil
+	}
+
+	if n.Kind != yaml.SequenceNode {
+		l.errorf(n, "Expected a list.")
+		return nil
+	}
+
+	return n.Content
+}
+
+// Decodes a node into the target (if the node is defined), reporting the errors at the position of the node.
+func (l *schemaLoader) decode(n *yaml.Node, target any) bool {
+	if n == nil {
+		return true
+	}
+
+	if err := n.Decode(target); err != nil {
+		l.errorf(n, "Invalid value: %s", yamlErrorRegex.ReplaceAllString(err.Error(), ""))
+		return false
+	}
+
+	return true
+}
+
+func (l *schemaLoader) packageConfig(n *yaml.Node) (ProtoPackageConfig, bool) {
+	keys := []string{"name", "proto_root", "go_package", "go_module", "converter_output_dir", "lock_file", "snapshot_file", "descriptor_set_file", "manifest_file"}
+
+	entries := l.mapping(n, append(keys, "conformance_tests")...)
+	if entries == nil {
+		return ProtoPackageConfig{}, false
+	}
+
+	var conf ProtoPackageConfig
+	targets := []*string{
+		&conf.Name, &conf.ProtoRoot, &conf.GoPackage, &conf.GoModule, &conf.ConverterOutputDir,
+		&conf.LockFile, &conf.SnapshotFile, &conf.DescriptorSetFile, &conf.ManifestFile,
+	}
+
+	ok := true
+	for i, key := range keys {
+		ok = l.decode(entries[key], targets[i]) && ok
+	}
+
+	ok = l.decode(entries["conformance_tests"], &conf.ConformanceTests) && ok
+
+	if ok && (conf.Name == "" || conf.GoPackage == "") {
+		l.errorf(n, "The package must have a name and a go_package.")
+		ok = false
+	}
+
+	return conf, ok
+}
+
+// Creates a file with its enums and messages, and returns the messages (whose fields are added later) and the services.
+func (l *schemaLoader) file(n *yaml.Node) (*FileSchema, []pendingMessage, []*yaml.Node) {
+	entries := l.mapping(n, "name", "syntax", "edition", "doc", "trailing_comment", "imports", "enums", "messages", "services")
+	if entries == nil {
+		return nil, nil, nil
+	}
+
+	var schema FileSchema
+	var imports []string
+
+	l.decode(entries["name"], &schema.Name)
+	l.decode(entries["syntax"], &schema.Syntax)
+	l.decode(entries["edition"], &schema.Edition)
+	l.decode(entries["doc"], &schema.Doc)
+	l.decode(entries["trailing_comment"], &schema.TrailingComment)
+	l.decode(entries["imports"], &imports)
+
+	if schema.Name == "" {
+		l.errorf(n, "The file name is missing.")
+		return nil, nil, nil
+	}
+
+	schema.Imports = make(Set)
+	for _, imp := range imports {
+		schema.Imports[imp] = present
+	}
+
+	f := l.pkg.NewFile(schema)
+
+	for _, enumNode := range l.sequence(entries["enums"]) {
+		l.enum(enumNode, f.NewEnum)
+	}
+
+	var pending []pendingMessage
+	for _, messageNode := range l.sequence(entries["messages"]) {
+		pending = append(pending, l.message(messageNode, f.NewMessage)...)
+	}
+
+	return f, pending, l.sequence(entries["services"])
+}
+
+// Creates an enum with the given constructor, and registers it for the enum fields.
+func (l *schemaLoader) enum(n *yaml.Node, newEnum func(EnumGroup) *EnumGroup) {
+	entries := l.mapping(n, "name", "doc", "trailing_comment", "members", "values", "reserved_names", "reserved_numbers")
+	if entries == nil {
+		return
+	}
+
+	var schema EnumGroup
+
+	l.decode(entries["name"], &schema.Name)
+	l.decode(entries["doc"], &schema.Doc)
+	l.decode(entries["trailing_comment"], &schema.TrailingComment)
+	l.decode(entries["members"], &schema.MembersList)
+	l.decode(entries["reserved_names"], &schema.ReservedNames)
+	l.decode(entries["reserved_numbers"], &schema.ReservedNumbers)
+
+	if schema.Name == "" {
+		l.errorf(n, "The enum name is missing.")
+		return
+	}
+
+	for _, valueNode := range l.sequence(entries["values"]) {
+		valueEntries := l.mapping(valueNode, "name", "number", "doc", "trailing_comment")
+		if valueEntries == nil {
+			continue
+		}
+
+		var value EnumValue
+		l.decode(valueEntries["name"], &value.Name)
+		l.decode(valueEntries["number"], &value.Number)
+		l.decode(valueEntries["doc"], &value.Doc)
+		l.decode(valueEntries["trailing_comment"], &value.TrailingComment)
+
+		if value.Name == "" || valueEntries["number"] == nil {
+			l.errorf(valueNode, "Enum values must have a name and a number.")
+			continue
+		}
+
+		schema.Values = append(schema.Values, value)
+	}
+
+	e := newEnum(schema)
+
+	// Registered by name and by full name, for the enum fields
+	if l.checkName(n, e.GetName()) {
+		l.enums[e.GetName()] = e
+		l.enums[l.pkg.GetName()+"."+e.GetName()] = e
+	}
+}
+
+// Checks that the name of a message or enum is not already defined in the document.
+func (l *schemaLoader) checkName(n *yaml.Node, name string) bool {
+	_, isMessage := l.messages[name]
+	_, isEnum := l.enums[name]
+
+	if isMessage || isEnum {
+		l.errorf(n, "The name %q is already defined.", name)
+		return false
+	}
+
+	return true
+}
+
+// Creates a message (along with its nested messages and enums) with the given constructor, and returns the messages whose fields must be added.
+func (l *schemaLoader) message(n *yaml.Node, newMessage func(MessageSchema) *MessageSchema) []pendingMessage {
+	entries := l.mapping(n, "name", "doc", "trailing_comment", "fields", "oneofs", "enums", "messages", "reserved_names", "reserved_numbers")
+	if entries == nil {
+		return nil
+	}
+
+	var schema MessageSchema
+
+	l.decode(entries["name"], &schema.Name)
+	l.decode(entries["doc"], &schema.Doc)
+	l.decode(entries["trailing_comment"], &schema.TrailingComment)
+	l.decode(entries["reserved_names"], &schema.ReservedNames)
+	l.decode(entries["reserved_numbers"], &schema.ReservedNumbers)
+
+	if schema.Name == "" {
+		l.errorf(n, "The message name is missing.")
+		return nil
+	}
+
+	m := newMessage(schema)
+
+	// Registered by name and by full name, for the message fields
+	if l.checkName(n, m.GetName()) {
+		l.messages[m.GetName()] = m
+		l.messages[l.pkg.GetName()+"."+m.GetName()] = m
+	}
+
+	for _, enumNode := range l.sequence(entries["enums"]) {
+		l.enum(enumNode, m.NewEnum)
+	}
+
+	pending := []pendingMessage{{schema: m, fields: entries["fields"], oneofs: entries["oneofs"]}}
+
+	for _, nestedNode := range l.sequence(entries["messages"]) {
+		pending = append(pending, l.message(nestedNode, m.NestedMessage)...)
+	}
+
+	return pending
+}
+
+// Adds the fields and oneofs to a message.
+func (l *schemaLoader) messageFields(pending pendingMessage) {
+	m := pending.schema
+	m.Fields = make(FieldsMap)
+
+	for _, fieldNode := range l.sequence(pending.fields) {
+		l.addField(fieldNode, m.Fields, &m.FieldsList)
+	}
+
+	for _, oneofNode := range l.sequence(pending.oneofs) {
+		oneofEntries := l.mapping(oneofNode, "name", "required", "doc", "trailing_comment", "fields")
+		if oneofEntries == nil {
+			continue
+		}
+
+		oneof := OneofGroup{Fields: make(OneofFields)}
+		l.decode(oneofEntries["name"], &oneof.Name)
+		l.decode(oneofEntries["required"], &oneof.Required)
+		l.decode(oneofEntries["doc"], &oneof.Doc)
+		l.decode(oneofEntries["trailing_comment"], &oneof.TrailingComment)
+
+		if oneof.Name == "" {
+			l.errorf(oneofNode, "The oneof name is missing.")
+			continue
+		}
+
+		for _, fieldNode := range l.sequence(oneofEntries["fields"]) {
+			l.addField(fieldNode, oneof.Fields, &oneof.FieldsList)
+		}
+
+		m.NewOneof(oneof)
+	}
+}
+
+// Creates a field and adds it to the fields map (or to the fields list, if it has no number).
+func (l *schemaLoader) addField(n *yaml.Node, fields map[uint32]FieldBuilder, fieldsList *FieldsList) {
+	entries := l.mapping(n, fieldKeys...)
+	if entries == nil {
+		return
+	}
+
+	var name, doc, trailingComment string
+	var number uint32
+
+	l.decode(entries["name"], &name)
+	l.decode(entries["doc"], &doc)
+	l.decode(entries["trailing_comment"], &trailingComment)
+	if !l.decode(entries["number"], &number) {
+		return
+	}
+
+	if name == "" {
+		l.errorf(n, "The field name is missing.")
+		return
+	}
+
+	var field FieldBuilder
+
+	if mapNode, isMap := entries["map"]; isMap {
+		if slices.ContainsFunc(fieldTypeKeys, func(key string) bool { return entries[key] != nil }) {
+			l.errorf(n, "Map fields define the types of their keys and values in the map key.")
+			return
+		}
+
+		field = l.mapField(name, mapNode)
+	} else {
+		field = l.typedField(name, n, entries)
+
+		// Repeated fields can be defined with their rules or with a boolean
+		repeatedNode := entries["repeated"]
+		isRepeated := repeatedNode != nil
+		if isRepeated && repeatedNode.Kind == yaml.ScalarNode && repeatedNode.Tag != "!!null" {
+			isRepeated = false
+			l.decode(repeatedNode, &isRepeated)
+			repeatedNode = nil
+		}
+
+		if field != nil && isRepeated {
+			repeated := Repeated(name, field)
+			l.applyRules(repeated, repeatedNode)
+			field = repeated
+		}
+	}
+
+	if field == nil {
+		return
+	}
+
+	if doc != "" {
+		l.callRule(field, "Doc", n, []reflect.Value{reflect.ValueOf(doc)})
+	}
+
+	if trailingComment != "" {
+		l.callRule(field, "TrailingComment", n, []reflect.Value{reflect.ValueOf(trailingComment)})
+	}
+
+	if entries["number"] == nil {
+		*fieldsList = append(*fieldsList, field)
+		return
+	}
+
+	if _, exists := fields[number]; exists {
+		l.errorf(entries["number"], "The field number %d is already used.", number)
+		return
+	}
+
+	fields[number] = field
+}
+
+// Creates a field from its type key, and applies its rules.
+func (l *schemaLoader) typedField(name string, n *yaml.Node, entries map[string]*yaml.Node) FieldBuilder {
+	var definedTypeKeys []string
+	for _, key := range fieldTypeKeys {
+		if entries[key] != nil {
+			definedTypeKeys = append(definedTypeKeys, key)
+		}
+	}
+
+	if len(definedTypeKeys) != 1 {
+		l.errorf(n, "Fields must have exactly one type key (such as string, int32, message or enum).")
+		return nil
+	}
+
+	typeKey := definedTypeKeys[0]
+	rulesNode := entries[typeKey]
+
+	if constructor, isType := documentFieldTypes[typeKey]; isType {
+		field := constructor(name)
+		l.applyRules(field, rulesNode)
+		return field
+	}
+
+	// Message and enum fields contain the name of their type, either as a string or in the type key of their rules
+	var typeName string
+
+	switch rulesNode.Kind {
+	case yaml.ScalarNode:
+		typeName = rulesNode.Value
+		rulesNode = nil
+	case yaml.MappingNode:
+		for i := 0; i < len(rulesNode.Content); i += 2 {
+			if rulesNode.Content[i].Value == "type" {
+				typeName = rulesNode.Content[i+1].Value
+			}
+		}
+	}
+
+	if typeName == "" {
+		l.errorf(entries[typeKey], "The %s type is missing.", typeKey)
+		return nil
+	}
+
+	var field FieldBuilder
+
+	if typeKey == "message" {
+		msg, exists := l.messages[typeName]
+		if !exists {
+			l.errorf(entries[typeKey], "Unknown message %q.", typeName)
+			return nil
+		}
+		field = MsgField(name, msg)
+	} else {
+		enum, exists := l.enums[typeName]
+		if !exists {
+			l.errorf(entries[typeKey], "Unknown enum %q.", typeName)
+			return nil
+		}
+		field = EnumField(name, enum)
+	}
+
+	l.applyRules(field, rulesNode, "type")
+
+	return field
+}
+
+// Creates a map field from the types of its keys and values, and applies its rules.
+func (l *schemaLoader) mapField(name string, n *yaml.Node) FieldBuilder {
+	if n.Kind != yaml.MappingNode {
+		l.errorf(n, "Expected a mapping with the types of the keys and values.")
+		return nil
+	}
+
+	var keysNode, valuesNode *yaml.Node
+
+	for i := 0; i < len(n.Content); i += 2 {
+		switch n.Content[i].Value {
+		case "key":
+			keysNode = n.Content[i+1]
+		case "value":
+			valuesNode = n.Content[i+1]
+		}
+	}
+
+	if keysNode == nil || valuesNode == nil {
+		l.errorf(n, "Map fields must define the types of their keys and values, with the key and value keys.")
+		return nil
+	}
+
+	var keys, values FieldBuilder
+
+	for _, typeNode := range []*yaml.Node{keysNode, valuesNode} {
+		entries := l.mapping(typeNode, fieldTypeKeys...)
+		if entries == nil {
+			return nil
+		}
+
+		field := l.typedField("", typeNode, entries)
+		if field == nil {
+			return nil
+		}
+
+		if typeNode == keysNode {
+			keys = field
+		} else {
+			values = field
+		}
+	}
+
+	field := Map(name, keys, values)
+	l.applyRules(field, n, "key", "value")
+
+	return field
+}
+
+// Creates a service with its handlers.
+func (l *schemaLoader) service(f *FileSchema, n *yaml.Node) {
+	entries := l.mapping(n, "resource", "doc", "trailing_comment", "handlers")
+	if entries == nil || f == nil {
+		return
+	}
+
+	schema := ServiceSchema{Handlers: make(HandlersMap)}
+
+	l.decode(entries["resource"], &schema.Resource)
+	l.decode(entries["doc"], &schema.Doc)
+	l.decode(entries["trailing_comment"], &schema.TrailingComment)
+
+	if schema.Resource == "" {
+		l.errorf(n, "The service resource is missing.")
+		return
+	}
+
+	handlersNode := entries["handlers"]
+	if handlersNode != nil && handlersNode.Kind != yaml.MappingNode {
+		l.errorf(handlersNode, "Expected a mapping of handler names to their definitions.")
+		return
+	}
+
+	for i := 0; handlersNode != nil && i < len(handlersNode.Content); i += 2 {
+		handlerName, handlerNode := handlersNode.Content[i].Value, handlersNode.Content[i+1]
+
+		handlerEntries := l.mapping(handlerNode, "request", "response", "doc", "trailing_comment")
+		if handlerEntries == nil {
+			continue
+		}
+
+		var handler Handler
+		l.decode(handlerEntries["doc"], &handler.Doc)
+		l.decode(handlerEntries["trailing_comment"], &handler.TrailingComment)
+
+		for _, key := range []string{"request", "response"} {
+			messageNode := handlerEntries[key]
+			if messageNode == nil {
+				l.errorf(handlerNode, "The %s of the handler %q is missing.", key, handlerName)
+				continue
+			}
+
+			message := l.messages[messageNode.Value]
+			if messageNode.Value == "google.protobuf.Empty" {
+				message = Empty()
+			} else if message == nil {
+				l.errorf(messageNode, "Unknown message %q.", messageNode.Value)
+				continue
+			}
+
+			if key == "request" {
+				handler.Request = message
+			} else {
+				handler.Response = message
+			}
+		}
+
+		schema.Handlers[handlerName] = handler
+	}
+
+	f.NewService(schema)
+}
+
+// Applies the rules in a mapping node to a field builder, by calling the methods with the same names (ignoring the case and the underscores). The ignored keys are handled by the caller.
+func (l *schemaLoader) applyRules(field FieldBuilder, n *yaml.Node, ignoredKeys ...string) {
+	if n == nil || n.Tag == "!!null" {
+		return
+	}
+
+	if n.Kind != yaml.MappingNode {
+		l.errorf(n, "Expected a mapping of rules.")
+		return
+	}
+
+	builder := reflect.ValueOf(field)
+	methods := make(map[string]string)
+
+	for i := range builder.NumMethod() {
+		methodName := builder.Type().Method(i).Name
+		if ruleName := strings.ToLower(methodName); !slices.Contains(nonRuleMethods, ruleName) {
+			methods[ruleName] = methodName
+		}
+	}
+
+	for i := 0; i < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+
+		if slices.Contains(ignoredKeys, key.Value) {
+			continue
+		}
+
+		methodName, exists := methods[strings.ReplaceAll(strings.ToLower(key.Value), "_", "")]
+		if !exists {
+			l.errorf(key, "Unknown rule %q for this field type.", key.Value)
+			continue
+		}
+
+		method := builder.MethodByName(methodName)
+
+		args, ok := l.ruleArgs(method.Type(), value)
+		if !ok {
+			continue
+		}
+
+		if args != nil {
+			l.callRule(field, methodName, key, args)
+		}
+	}
+}
+
+// Converts the value of a rule into the arguments for its method. It returns nil arguments for rules without arguments that are set to false.
+func (l *schemaLoader) ruleArgs(methodType reflect.Type, n *yaml.Node) ([]reflect.Value, bool) {
+	numIn := methodType.NumIn()
+
+	if numIn == 0 {
+		var enabled bool
+		if !l.decode(n, &enabled) {
+			return nil, false
+		}
+
+		if !enabled {
+			return nil, true
+		}
+
+		return []reflect.Value{}, true
+	}
+
+	if numIn == 1 && !methodType.IsVariadic() {
+		arg, ok := l.ruleArg(methodType.In(0), n)
+		return []reflect.Value{arg}, ok
+	}
+
+	items := []*yaml.Node{n}
+	if n.Kind == yaml.SequenceNode {
+		items = n.Content
+	}
+
+	if !methodType.IsVariadic() && len(items) != numIn {
+		l.errorf(n, "Expected a list of %d values.", numIn)
+		return nil, false
+	}
+
+	args := make([]reflect.Value, 0, len(items))
+
+	for i, item := range items {
+		argType := methodType.In(min(i, numIn-1))
+		if methodType.IsVariadic() && i >= numIn-1 {
+			argType = argType.Elem()
+		}
+
+		arg, ok := l.ruleArg(argType, item)
+		if !ok {
+			return nil, false
+		}
+
+		args = append(args, arg)
+	}
+
+	return args, true
+}
+
+// Decodes a single argument for the method of a rule. Timestamps use the RFC 3339 format, and durations use the format of time.ParseDuration.
+func (l *schemaLoader) ruleArg(argType reflect.Type, n *yaml.Node) (reflect.Value, bool) {
+	switch argType {
+	case timestampType:
+		t, err := time.Parse(time.RFC3339, n.Value)
+		if err != nil {
+			l.errorf(n, "Invalid timestamp %q (expected the RFC 3339 format).", n.Value)
+			return reflect.Value{}, false
+		}
+		return reflect.ValueOf(timestamppb.New(t)), true
+	case durationType:
+		d, err := time.ParseDuration(n.Value)
+		if err != nil {
+			l.errorf(n, "Invalid duration %q.", n.Value)
+			return reflect.Value{}, false
+		}
+		return reflect.ValueOf(durationpb.New(d)), true
+	}
+
+	arg := reflect.New(argType)
+	if !l.decode(n, arg.Interface()) {
+		return reflect.Value{}, false
+	}
+
+	return arg.Elem(), true
+}
+
+// Calls the method of a rule. The invalid rules are recorded by the builders as errors of the field, and they are returned when the package is processed.
+// The panics caused by the arguments are recovered and reported at the position of the rule, but the fatal errors (which exit the process) cannot be recovered, so the inputs of the constructors that can cause them (the name and go_package of the package, and the types of the message and enum fields) are checked before calling them.
+func (l *schemaLoader) callRule(field FieldBuilder, methodName string, n *yaml.Node, args []reflect.Value) {
+	defer func() {
+		if r := recover(); r != nil {
+			l.errorf(n, "Failed to apply the rule: %v", r)
+		}
+	}()
+
+	reflect.ValueOf(field).MethodByName(methodName).Call(args)
+}
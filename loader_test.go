@@ -0,0 +1,180 @@
+package protoschema_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadPackage(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	document := `
+package:
+  name: loader.v1
+  go_package: github.com/Rick-Phoenix/protoschema/gen/loaderv1
+  proto_root: ` + tmpDir + `
+  converter_output_dir: ` + filepath.Join(tmpDir, "converter") + `
+files:
+  - name: store
+    doc: The store.
+    enums:
+      - name: Status
+        values:
+          - { name: STATUS_UNSPECIFIED, number: 0 }
+          - { name: STATUS_ACTIVE, number: 1, doc: The item is available. }
+    messages:
+      - name: Item
+        doc: An item in the store.
+        fields:
+          - { name: id, number: 1, int64: { gt: 0 } }
+          - { name: email, number: 2, string: { min_len: 2, email: true } }
+          - { name: status, number: 3, enum: { type: Status, defined_only: true } }
+          - { name: tags, number: 4, string: { max_len: 10 }, repeated: { unique: true } }
+          - { name: prices, number: 5, map: { key: { string: {} }, value: { double: { gte: 0 } }, min_pairs: 1 } }
+          - { name: location, number: 6, message: Item.Location, doc: Where the item is stored. }
+          - { name: created_at, number: 7, timestamp: { lt_now: true } }
+        oneofs:
+          - name: discount
+            fields:
+              - { name: percentage, number: 8, uint32: { lte: 100 } }
+              - { name: amount, number: 9, double: {} }
+        messages:
+          - name: Location
+            fields:
+              - { name: shelf, number: 1, string: { in: [A, B] } }
+      - name: GetItemRequest
+        fields:
+          - { name: id, number: 1, int64: {} }
+    services:
+      - resource: Item
+        handlers:
+          GetItem: { request: GetItemRequest, response: Item }
+`
+
+	pkg, err := sb.LoadPackage([]byte(document), "store.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, pkg.Generate())
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "loader/v1/store.proto"))
+	assert.NoError(t, err)
+
+	output := string(content)
+
+	expected := []string{
+		"// An item in the store.",
+		"(buf.validate.field).int64.gt = 0",
+		"(buf.validate.field).string.min_len = 2",
+		"(buf.validate.field).string.email = true",
+		"(buf.validate.field).enum = {defined_only: true}",
+		"repeated string tags = 4",
+		"(buf.validate.field).repeated.unique = true",
+		"(buf.validate.field).repeated.items = {string: {max_len: 10}}",
+		"map<string, double> prices = 5",
+		"(buf.validate.field).map.min_pairs = 1",
+		"(buf.validate.field).map.values = {double: {gte: 0}}",
+		"// Where the item is stored.\n  Item.Location location = 6;",
+		"(buf.validate.field).timestamp.lt_now = true",
+		"oneof discount",
+		"(buf.validate.field).uint32.lte = 100",
+		`(buf.validate.field).string.in = "A"`,
+		`(buf.validate.field).string.in = "B"`,
+		"rpc GetItem(GetItemRequest) returns(Item);",
+	}
+
+	for _, exp := range expected {
+		assert.Contains(t, output, exp)
+	}
+
+	// JSON documents use the same structure
+	jsonDocument := `{
+  "package": { "name": "loaderjson.v1", "go_package": "github.com/Rick-Phoenix/protoschema/gen/loaderjsonv1" },
+  "files": [{ "name": "user", "messages": [{ "name": "User", "fields": [{ "name": "name", "string": { "min_len": 1 } }] }] }]
+}`
+
+	jsonPkg, err := sb.LoadPackage([]byte(jsonDocument), "user.json")
+	if assert.NoError(t, err) {
+		files, err := jsonPkg.TryBuildFiles()
+		if assert.NoError(t, err) {
+			assert.Equal(t, "name", files[0].Messages[0].Fields[0].Name)
+		}
+	}
+
+	failureCases := map[string]struct {
+		document string
+		errors   []string
+	}{
+		"unknown rule": {
+			document: `
+package: { name: invalid.v1, go_package: github.com/Rick-Phoenix/protoschema/gen/invalidv1 }
+files:
+  - name: item
+    messages:
+      - name: Item
+        fields:
+          - { name: id, number: 1, int64: { min_len: 2 } }
+`,
+			errors: []string{`invalid.yaml:8:45: Unknown rule "min_len"`},
+		},
+		"invalid values": {
+			document: `
+package: { name: invalid.v1, go_package: github.com/Rick-Phoenix/protoschema/gen/invalidv1 }
+files:
+  - name: item
+    messages:
+      - name: Item
+        fields:
+          - { name: id, number: 1, int64: { gt: abc } }
+          - { name: created_at, number: 2, timestamp: { lt: yesterday } }
+          - { name: owner, number: 3, message: User }
+`,
+			errors: []string{
+				"invalid.yaml:8:49: Invalid value: cannot unmarshal !!str `abc` into int64",
+				"invalid.yaml:9:61: Invalid timestamp",
+				`invalid.yaml:10:48: Unknown message "User"`,
+			},
+		},
+		"missing go_package": {
+			document: `
+package: { name: invalid.v1 }
+files:
+  - name: item
+`,
+			errors: []string{"invalid.yaml:2:10: The package must have a name and a go_package."},
+		},
+		"invalid structure": {
+			document: `
+package: { name: invalid.v1, go_package: github.com/Rick-Phoenix/protoschema/gen/invalidv1 }
+files:
+  - name: item
+    mesages: []
+    messages:
+      - name: Item
+        fields:
+          - { name: id, number: 1, int64: {} }
+          - { name: name, number: 1, string: {} }
+          - { name: price, number: 2 }
+`,
+			errors: []string{
+				`invalid.yaml:5:5: Unknown key "mesages"`,
+				"invalid.yaml:10:35: The field number 1 is already used.",
+				"invalid.yaml:11:13: Fields must have exactly one type key",
+			},
+		},
+	}
+
+	for name, data := range failureCases {
+		_, err := sb.LoadPackage([]byte(data.document), "invalid.yaml")
+		if assert.Error(t, err, name) {
+			for _, exp := range data.errors {
+				assert.ErrorContains(t, err, exp, name)
+			}
+		}
+	}
+}
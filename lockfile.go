@@ -0,0 +1,470 @@
+package protoschema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// The default name of the file that stores the numbers assigned automatically to fields and enum values.
+const DefaultLockFileName = "protoschema.lock.json"
+
+// An ordered list of fields. The field numbers for these fields are assigned automatically and recorded in the package's lockfile, so that they remain stable between runs.
+type FieldsList []FieldBuilder
+
+// The contents of the lockfile. The data for each package is stored separately so that the same lockfile can be shared by multiple packages.
+type lockFile struct {
+	Version  int                     `json:"version"`
+	Packages map[string]*packageLock `json:"packages"`
+}
+
+type packageLock struct {
+	Messages map[string]*messageLock `json:"messages,omitempty"`
+	Enums    map[string]*enumLock    `json:"enums,omitempty"`
+}
+
+// The numbers assigned to the fields of a message (and of its oneofs), along with the numbers and names that are no longer in use and must never be assigned again.
+type messageLock struct {
+	Fields         map[string]uint32            `json:"fields,omitempty"`
+	Oneofs         map[string]map[string]uint32 `json:"oneofs,omitempty"`
+	RetiredNumbers []uint32                     `json:"retired_numbers,omitempty"`
+	RetiredNames   []string                     `json:"retired_names,omitempty"`
+}
+
+// The numbers assigned to the values of an enum, along with the numbers and names that are no longer in use and must never be assigned again.
+type enumLock struct {
+	Values         map[string]int32 `json:"values,omitempty"`
+	RetiredNumbers []int32          `json:"retired_numbers,omitempty"`
+	RetiredNames   []string         `json:"retired_names,omitempty"`
+}
+
+const (
+	firstReservedImplNumber = 19000
+	lastReservedImplNumber  = 19999
+)
+
+// Returns the path to the lockfile used by this package.
+func (p *ProtoPackage) GetLockFilePath() string {
+	if p == nil {
+		return ""
+	}
+
+	if p.lockFilePath == "" {
+		return filepath.Join(p.protoRoot, DefaultLockFileName)
+	}
+
+	return p.lockFilePath
+}
+
+func readLockFile(path string) (*lockFile, error) {
+	lock := &lockFile{Version: 1, Packages: make(map[string]*packageLock)}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return lock, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("Failed to read the lockfile at %q: %w", path, err)
+	}
+
+	if err := json.Unmarshal(content, lock); err != nil {
+		return nil, fmt.Errorf("Failed to parse the lockfile at %q: %w", path, err)
+	}
+
+	if lock.Packages == nil {
+		lock.Packages = make(map[string]*packageLock)
+	}
+
+	return lock, nil
+}
+
+// Loads the data for this package from the lockfile, if it was not loaded already.
+func (p *ProtoPackage) loadLock() error {
+	if p.lock != nil {
+		return nil
+	}
+
+	lock, err := readLockFile(p.GetLockFilePath())
+	if err != nil {
+		return err
+	}
+
+	pkgLock := lock.Packages[p.Name]
+	if pkgLock == nil {
+		pkgLock = &packageLock{}
+	}
+
+	if pkgLock.Messages == nil {
+		pkgLock.Messages = make(map[string]*messageLock)
+	}
+
+	if pkgLock.Enums == nil {
+		pkgLock.Enums = make(map[string]*enumLock)
+	}
+
+	p.lock = pkgLock
+
+	return nil
+}
+
+// Writes the numbers assigned to this package's fields and enum values to the lockfile. The data for the other packages in the same lockfile is preserved.
+// If no numbers were assigned automatically and the lockfile does not exist, nothing is written.
+func (p *ProtoPackage) saveLock() error {
+	if p.lock == nil {
+		return nil
+	}
+
+	path := p.GetLockFilePath()
+
+	lock, err := readLockFile(path)
+	if err != nil {
+		return err
+	}
+
+	isEmpty := len(p.lock.Messages) == 0 && len(p.lock.Enums) == 0
+
+	if isEmpty {
+		if _, exists := lock.Packages[p.Name]; !exists {
+			return nil
+		}
+		delete(lock.Packages, p.Name)
+	} else {
+		lock.Packages[p.Name] = p.lock
+	}
+
+	content, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to encode the lockfile: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("Failed to write the lockfile at %q: %w", path, err)
+	}
+
+	return nil
+}
+
+func (p *ProtoPackage) getMessageLock(name string) *messageLock {
+	if p == nil || p.lock == nil {
+		return &messageLock{}
+	}
+
+	lock, exists := p.lock.Messages[name]
+	if !exists {
+		lock = &messageLock{}
+		p.lock.Messages[name] = lock
+	}
+
+	return lock
+}
+
+func (p *ProtoPackage) getEnumLock(name string) *enumLock {
+	if p == nil || p.lock == nil {
+		return &enumLock{}
+	}
+
+	lock, exists := p.lock.Enums[name]
+	if !exists {
+		lock = &enumLock{}
+		p.lock.Enums[name] = lock
+	}
+
+	return lock
+}
+
+// The result of assigning numbers to the fields of a message that are defined in a FieldsList.
+type autoNumbers struct {
+	numbers         map[string]uint32
+	reservedNumbers []uint
+	reservedNames   []string
+}
+
+// Assigns the field numbers to the fields inside the FieldsList of this message and of its oneofs, reusing the numbers recorded in the lockfile.
+// Fields that are in the lockfile but not in the schema anymore are retired, which means that their names and numbers will be reserved and never assigned again.
+func (m *MessageSchema) assignFieldNumbers(explicitFields FieldsMap) (autoNumbers, error) {
+	out := autoNumbers{numbers: make(map[string]uint32)}
+	var err error
+
+	hasAutoFields := len(m.FieldsList) > 0
+	for _, of := range m.oneofs {
+		hasAutoFields = hasAutoFields || len(of.FieldsList) > 0
+	}
+
+	lockExists := m.Package != nil && m.Package.lock != nil && m.Package.lock.Messages[m.GetName()] != nil
+
+	if !hasAutoFields && !lockExists {
+		return out, nil
+	}
+
+	lock := m.Package.getMessageLock(m.GetName())
+
+	var maxNr uint32
+
+	markUsed := func(n uint32) {
+		maxNr = max(maxNr, n)
+	}
+
+	for nr := range explicitFields {
+		markUsed(nr)
+	}
+
+	for _, of := range m.oneofs {
+		for nr := range of.Fields {
+			markUsed(nr)
+		}
+	}
+
+	for _, nr := range m.ReservedNumbers {
+		markUsed(uint32(nr))
+	}
+
+	// The whole range of a mixin is claimed, including the numbers that its set does not use yet
+	for _, mixin := range m.Mixins {
+		if mixin.Range != nil && mixin.Range[1] > 0 {
+			markUsed(uint32(mixin.Range[1]))
+		}
+	}
+
+	for _, r := range slices.Concat(m.ReservedRanges, m.ExtensionRanges) {
+		if r[1] > 0 {
+			markUsed(uint32(r[1]))
+		}
+	}
+
+	for _, nr := range lock.Fields {
+		markUsed(nr)
+	}
+
+	for _, oneofNumbers := range lock.Oneofs {
+		for _, nr := range oneofNumbers {
+			markUsed(nr)
+		}
+	}
+
+	for _, nr := range lock.RetiredNumbers {
+		markUsed(nr)
+	}
+
+	lockedNumbers := make(map[string]uint32)
+	maps.Copy(lockedNumbers, lock.Fields)
+	for _, oneofNumbers := range lock.Oneofs {
+		maps.Copy(lockedNumbers, oneofNumbers)
+	}
+
+	nextNumber := func() uint32 {
+		n := maxNr + 1
+		if n >= firstReservedImplNumber && n <= lastReservedImplNumber {
+			n = lastReservedImplNumber + 1
+		}
+		markUsed(n)
+		return n
+	}
+
+	assign := func(fields FieldsList) map[string]uint32 {
+		numbers := make(map[string]uint32)
+
+		for _, f := range fields {
+			name := f.GetName()
+
+			if _, exists := out.numbers[name]; exists {
+				err = errors.Join(err, fmt.Errorf("Field %q is defined more than once in the fields lists of this message.", name))
+				continue
+			}
+
+			nr, locked := lockedNumbers[name]
+			if !locked {
+				nr = nextNumber()
+			}
+
+			numbers[name] = nr
+			out.numbers[name] = nr
+		}
+
+		return numbers
+	}
+
+	newFields := assign(m.FieldsList)
+	newOneofs := make(map[string]map[string]uint32)
+
+	for _, of := range m.oneofs {
+		if len(of.FieldsList) > 0 {
+			newOneofs[of.Name] = assign(of.FieldsList)
+		}
+	}
+
+	// The fields that are defined with an explicit number (including those from the mixins) are still in use, even if they were in a FieldsList before
+	explicitNames := make(map[string]uint32)
+	for nr, f := range explicitFields {
+		explicitNames[f.GetName()] = nr
+	}
+
+	for _, of := range m.oneofs {
+		for nr, f := range of.Fields {
+			explicitNames[f.GetName()] = nr
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(lockedNumbers)) {
+		if _, stillUsed := out.numbers[name]; stillUsed {
+			continue
+		}
+
+		nr := lockedNumbers[name]
+		explicitNr, isExplicit := explicitNames[name]
+
+		if !isExplicit || explicitNr != nr {
+			_, numberUsed := explicitFields[nr]
+			for _, of := range m.oneofs {
+				_, inOneof := of.Fields[nr]
+				numberUsed = numberUsed || inOneof
+			}
+
+			if !numberUsed && !slices.Contains(lock.RetiredNumbers, nr) {
+				lock.RetiredNumbers = append(lock.RetiredNumbers, nr)
+			}
+		}
+
+		if !isExplicit && !slices.Contains(lock.RetiredNames, name) {
+			lock.RetiredNames = append(lock.RetiredNames, name)
+		}
+	}
+
+	lock.Fields = newFields
+	lock.Oneofs = newOneofs
+
+	if len(lock.Oneofs) == 0 {
+		lock.Oneofs = nil
+	}
+
+	for _, nr := range lock.RetiredNumbers {
+		if !slices.Contains(m.ReservedNumbers, uint(nr)) {
+			out.reservedNumbers = append(out.reservedNumbers, uint(nr))
+		}
+	}
+
+	for _, name := range lock.RetiredNames {
+		// A retired name can be used again by a new field, but it will receive a new number
+		_, isExplicit := explicitNames[name]
+		if _, isActive := out.numbers[name]; !isActive && !isExplicit && !slices.Contains(m.ReservedNames, name) {
+			out.reservedNames = append(out.reservedNames, name)
+		}
+	}
+
+	return out, err
+}
+
+// Assigns the numbers to the members inside the MembersList of this enum, reusing the numbers recorded in the lockfile.
+// Members that are in the lockfile but not in the schema anymore are retired, which means that their names and numbers will be reserved and never assigned again.
+func (e *EnumGroup) assignMemberNumbers() (map[string]int32, []int32, []string, error) {
+	numbers := make(map[string]int32)
+	var reservedNumbers []int32
+	var reservedNames []string
+	var err error
+
+	lockExists := e.Package != nil && e.Package.lock != nil && e.Package.lock.Enums[e.GetName()] != nil
+
+	if len(e.MembersList) == 0 && !lockExists {
+		return numbers, nil, nil, nil
+	}
+
+	lock := e.Package.getEnumLock(e.GetName())
+
+	maxNr := int32(-1)
+
+	markUsed := func(n int32) {
+		maxNr = max(maxNr, n)
+	}
+
+	for nr := range e.Members {
+		markUsed(nr)
+	}
+
+	for _, v := range e.Values {
+		markUsed(v.Number)
+	}
+
+	for _, nr := range e.ReservedNumbers {
+		markUsed(nr)
+	}
+
+	for _, r := range e.ReservedRanges {
+		markUsed(r[1])
+	}
+
+	for _, nr := range lock.Values {
+		markUsed(nr)
+	}
+
+	for _, nr := range lock.RetiredNumbers {
+		markUsed(nr)
+	}
+
+	for _, name := range e.MembersList {
+		if _, exists := numbers[name]; exists {
+			err = errors.Join(err, fmt.Errorf("Member %q is defined more than once in the members list of this enum.", name))
+			continue
+		}
+
+		nr, locked := lock.Values[name]
+		if !locked {
+			nr = maxNr + 1
+			markUsed(nr)
+		}
+
+		numbers[name] = nr
+	}
+
+	// The members that are defined with an explicit number are still in use, even if they were in the MembersList before
+	explicitNames := make(map[string]int32)
+	explicitNumbers := make(map[int32]bool)
+	for nr, name := range e.Members {
+		explicitNames[name] = nr
+		explicitNumbers[nr] = true
+	}
+
+	for _, v := range e.Values {
+		explicitNames[v.Name] = v.Number
+		explicitNumbers[v.Number] = true
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(lock.Values)) {
+		if _, stillUsed := numbers[name]; stillUsed {
+			continue
+		}
+
+		nr := lock.Values[name]
+		explicitNr, isExplicit := explicitNames[name]
+
+		if (!isExplicit || explicitNr != nr) && !explicitNumbers[nr] && !slices.Contains(lock.RetiredNumbers, nr) {
+			lock.RetiredNumbers = append(lock.RetiredNumbers, nr)
+		}
+
+		if !isExplicit && !slices.Contains(lock.RetiredNames, name) {
+			lock.RetiredNames = append(lock.RetiredNames, name)
+		}
+	}
+
+	lock.Values = numbers
+
+	for _, nr := range lock.RetiredNumbers {
+		if !slices.Contains(e.ReservedNumbers, nr) {
+			reservedNumbers = append(reservedNumbers, nr)
+		}
+	}
+
+	for _, name := range lock.RetiredNames {
+		_, isExplicit := explicitNames[name]
+		if _, isActive := numbers[name]; !isActive && !isExplicit && !slices.Contains(e.ReservedNames, name) {
+			reservedNames = append(reservedNames, name)
+		}
+	}
+
+	return numbers, reservedNumbers, reservedNames, err
+}
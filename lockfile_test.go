@@ -0,0 +1,189 @@
+package protoschema_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockfileNumbering(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	newPackage := func(fields sb.FieldsList, oneofFields sb.FieldsList, members []string) *sb.ProtoPackage {
+		pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+			Name:               "lock.v1",
+			GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/lockv1",
+			ProtoRoot:          tmpDir,
+			ConverterOutputDir: filepath.Join(tmpDir, "converter"),
+		})
+
+		file := pkg.NewFile(sb.FileSchema{Name: "lock"})
+		msg := file.NewMessage(sb.MessageSchema{
+			Name:       "Item",
+			Fields:     sb.FieldsMap{1: sb.Int64("id")},
+			FieldsList: fields,
+		})
+		msg.NewOneof(sb.OneofGroup{Name: "choice", FieldsList: oneofFields})
+		file.NewEnum(sb.EnumGroup{Name: "Status", MembersList: members})
+
+		return pkg
+	}
+
+	extractNumbers := func(files []sb.FileData) (map[string]uint32, map[string]int32) {
+		fields := make(map[string]uint32)
+		members := make(map[string]int32)
+
+		msg := files[0].Messages[0]
+		for _, f := range msg.Fields {
+			fields[f.Name] = f.FieldNr
+		}
+		for _, f := range msg.Oneofs[0].Fields {
+			fields[f.Name] = f.FieldNr
+		}
+		for nr, name := range files[0].Enums[0].Members {
+			members[name] = nr
+		}
+
+		return fields, members
+	}
+
+	first := newPackage(
+		sb.FieldsList{sb.String("name"), sb.String("description"), sb.Int32("count")},
+		sb.FieldsList{sb.String("text")},
+		[]string{"STATUS_UNSPECIFIED", "STATUS_ACTIVE"},
+	)
+
+	fields, members := extractNumbers(first.BuildFiles())
+	assert.Equal(t, map[string]uint32{"id": 1, "name": 2, "description": 3, "count": 4, "text": 5}, fields)
+	assert.Equal(t, map[string]int32{"STATUS_UNSPECIFIED": 0, "STATUS_ACTIVE": 1}, members)
+
+	err := first.Generate()
+	assert.NoError(t, err)
+	assert.FileExists(t, first.GetLockFilePath())
+
+	// Removing "description" and "STATUS_ACTIVE", reordering and adding new items
+	second := newPackage(
+		sb.FieldsList{sb.Int32("count"), sb.String("name"), sb.Bool("archived")},
+		sb.FieldsList{sb.String("text")},
+		[]string{"STATUS_UNSPECIFIED", "STATUS_ARCHIVED"},
+	)
+
+	files := second.BuildFiles()
+	fields, members = extractNumbers(files)
+	assert.Equal(t, map[string]uint32{"id": 1, "name": 2, "count": 4, "text": 5, "archived": 6}, fields, "Locked numbers should be reused and retired numbers should never be assigned again")
+	assert.Equal(t, map[string]int32{"STATUS_UNSPECIFIED": 0, "STATUS_ARCHIVED": 2}, members)
+
+	assert.Contains(t, files[0].Messages[0].ReservedNumbers, uint(3))
+	assert.Contains(t, files[0].Messages[0].ReservedNames, "description")
+	assert.Contains(t, files[0].Enums[0].ReservedNumbers, int32(1))
+	assert.Contains(t, files[0].Enums[0].ReservedNames, "STATUS_ACTIVE")
+
+	err = second.Generate()
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(second.GetLockFilePath())
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `"retired_names": [`)
+}
+
+func TestLockfileExplicitFields(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	newPackage := func(fields sb.FieldsMap, fieldsList sb.FieldsList) *sb.ProtoPackage {
+		pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+			Name:               "lock.v1",
+			GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/lockv1",
+			ProtoRoot:          tmpDir,
+			ConverterOutputDir: filepath.Join(tmpDir, "converter"),
+		})
+
+		file := pkg.NewFile(sb.FileSchema{Name: "lock"})
+		file.NewMessage(sb.MessageSchema{
+			Name:       "Item",
+			Fields:     fields,
+			FieldsList: fieldsList,
+			Mixins:     []sb.FieldMixin{sb.NewFieldSet("audit", sb.Int64("created_at")).InRange(sb.Range{10, 19})},
+		})
+
+		return pkg
+	}
+
+	first := newPackage(sb.FieldsMap{1: sb.Int64("id")}, sb.FieldsList{sb.String("name"), sb.String("description")})
+
+	files := first.BuildFiles()
+	numbers := make(map[string]uint32)
+	for _, f := range files[0].Messages[0].Fields {
+		numbers[f.Name] = f.FieldNr
+	}
+	assert.Equal(t, map[string]uint32{"id": 1, "created_at": 10, "name": 20, "description": 21}, numbers, "The numbers inside the range of a mixin should never be assigned")
+
+	err := first.Generate()
+	assert.NoError(t, err)
+
+	// Moving "name" to the explicit fields with the same number and "description" with a different one
+	second := newPackage(sb.FieldsMap{1: sb.Int64("id"), 20: sb.String("name"), 30: sb.String("description")}, nil)
+
+	files, err = second.TryBuildFiles()
+	assert.NoError(t, err)
+	assert.NotContains(t, files[0].Messages[0].ReservedNames, "name")
+	assert.NotContains(t, files[0].Messages[0].ReservedNames, "description")
+	assert.NotContains(t, files[0].Messages[0].ReservedNumbers, uint(20))
+	assert.Contains(t, files[0].Messages[0].ReservedNumbers, uint(21))
+
+	err = second.Generate()
+	assert.NoError(t, err)
+}
+
+func TestLockfileExplicitMembers(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	newPackage := func(members sb.EnumMembers, values []sb.EnumValue, membersList []string) *sb.ProtoPackage {
+		pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+			Name:               "lock.v1",
+			GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/lockv1",
+			ProtoRoot:          tmpDir,
+			ConverterOutputDir: filepath.Join(tmpDir, "converter"),
+		})
+
+		pkg.NewFile(sb.FileSchema{Name: "lock"}).NewEnum(sb.EnumGroup{Name: "Status", Members: members, Values: values, MembersList: membersList})
+
+		return pkg
+	}
+
+	first := newPackage(nil, nil, []string{"STATUS_UNSPECIFIED", "STATUS_ACTIVE", "STATUS_ARCHIVED", "STATUS_DELETED"})
+
+	err := first.Generate()
+	assert.NoError(t, err)
+
+	// Moving the members to the explicit ones with the same number, except for "STATUS_DELETED", which receives a different one
+	second := newPackage(
+		sb.EnumMembers{0: "STATUS_UNSPECIFIED", 1: "STATUS_ACTIVE"},
+		[]sb.EnumValue{{Name: "STATUS_ARCHIVED", Number: 2}, {Name: "STATUS_DELETED", Number: 10}},
+		nil,
+	)
+
+	files, err := second.TryBuildFiles()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	enum := files[0].Enums[0]
+	assert.Empty(t, enum.ReservedNames)
+	assert.Equal(t, []int32{3}, enum.ReservedNumbers)
+
+	err = second.Generate()
+	assert.NoError(t, err)
+
+	// The next generation does not find any collision with the reserved numbers
+	third := newPackage(
+		sb.EnumMembers{0: "STATUS_UNSPECIFIED", 1: "STATUS_ACTIVE"},
+		[]sb.EnumValue{{Name: "STATUS_ARCHIVED", Number: 2}, {Name: "STATUS_DELETED", Number: 10}},
+		nil,
+	)
+
+	_, err = third.TryBuildFiles()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,221 @@
+package protoschema
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// The default name of the file that records the outputs of each generation.
+const DefaultManifestFileName = "protoschema.manifest.json"
+
+// The header that marks the files generated by protoschema. Existing files without this header are never overwritten or deleted.
+const GeneratedHeader = "Code generated by protoschema. DO NOT EDIT."
+
+// The contents of the manifest. The outputs of each package are stored separately so that the same manifest can be shared by multiple packages.
+type manifestFile struct {
+	Version  int                                 `json:"version"`
+	Packages map[string]map[string]manifestEntry `json:"packages"`
+}
+
+// A generated file, with the hash of the content rendered by protoschema and the hash of the file after being formatted.
+type manifestEntry struct {
+	Hash       string `json:"hash"`
+	OutputHash string `json:"output_hash"`
+}
+
+// Returns the path to the manifest used by this package.
+func (p *ProtoPackage) GetManifestFilePath() string {
+	if p == nil {
+		return ""
+	}
+
+	if p.manifestFilePath == "" {
+		return filepath.Join(p.protoRoot, DefaultManifestFileName)
+	}
+
+	return p.manifestFilePath
+}
+
+func readManifestFile(path string) (*manifestFile, error) {
+	manifest := &manifestFile{Version: 1, Packages: make(map[string]map[string]manifestEntry)}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("Failed to read the manifest at %q: %w", path, err)
+	}
+
+	if err := json.Unmarshal(content, manifest); err != nil {
+		return nil, fmt.Errorf("Failed to parse the manifest at %q: %w", path, err)
+	}
+
+	if manifest.Packages == nil {
+		manifest.Packages = make(map[string]map[string]manifestEntry)
+	}
+
+	return manifest, nil
+}
+
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Checks if the content starts with the header of the generated files (within its first lines).
+func hasGeneratedHeader(content []byte) bool {
+	return bytes.Contains(content[:min(len(content), 512)], []byte(GeneratedHeader))
+}
+
+// Loads the outputs of the previous generation of this package, and resets the outputs of the current one.
+func (p *ProtoPackage) loadManifest() error {
+	manifest, err := readManifestFile(p.GetManifestFilePath())
+	if err != nil {
+		return err
+	}
+
+	p.prevOutputs = manifest.Packages[p.Name]
+	p.outputs = make(map[string]manifestEntry)
+
+	return nil
+}
+
+// Returns the key of a file in the manifest, which is its path relative to the directory of the manifest.
+func (p *ProtoPackage) manifestKey(outputPath string) string {
+	manifestDir, err := filepath.Abs(filepath.Dir(p.GetManifestFilePath()))
+	if err != nil {
+		return filepath.ToSlash(outputPath)
+	}
+
+	absPath, err := filepath.Abs(outputPath)
+	if err != nil {
+		return filepath.ToSlash(outputPath)
+	}
+
+	if rel, err := filepath.Rel(manifestDir, absPath); err == nil {
+		return filepath.ToSlash(rel)
+	}
+
+	return filepath.ToSlash(absPath)
+}
+
+// Writes a generated file, unless it is identical to the output of the previous generation. Returns true if the file was written.
+// If the file exists but it does not have the GeneratedHeader, it is considered as owned by the user and an error is returned, unless OverwriteUnmarkedFiles is set in the package config.
+func (p *ProtoPackage) writeGeneratedFile(outputPath string, content []byte) (bool, error) {
+	key := p.manifestKey(outputPath)
+	hash := hashContent(content)
+
+	existing, err := os.ReadFile(outputPath)
+	if err == nil {
+		if !hasGeneratedHeader(existing) && !p.overwriteUnmarked {
+			return false, fmt.Errorf("The file at %q was not generated by protoschema (it does not contain the %q header), so it will not be overwritten. To overwrite the files generated by a version of protoschema without the header, set OverwriteUnmarkedFiles in the package config for one generation.", outputPath, GeneratedHeader)
+		}
+
+		if prev, exists := p.prevOutputs[key]; exists && prev.Hash == hash && prev.OutputHash == hashContent(existing) {
+			p.outputs[key] = prev
+			return false, nil
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return false, err
+	}
+
+	if err := os.WriteFile(outputPath, content, 0644); err != nil {
+		return false, err
+	}
+
+	p.outputs[key] = manifestEntry{Hash: hash, OutputHash: hash}
+
+	return true, nil
+}
+
+// Updates the hash of a generated file after it has been modified by a formatter.
+func (p *ProtoPackage) updateOutputHash(outputPath string) {
+	key := p.manifestKey(outputPath)
+
+	entry, exists := p.outputs[key]
+	if !exists {
+		return
+	}
+
+	if content, err := os.ReadFile(outputPath); err == nil {
+		entry.OutputHash = hashContent(content)
+		p.outputs[key] = entry
+	}
+}
+
+// Removes the files that were generated previously but that are no longer produced, and writes the outputs of this generation to the manifest. The data for the other packages in the same manifest is preserved.
+func (p *ProtoPackage) saveManifest() error {
+	if p.outputs == nil {
+		return nil
+	}
+
+	manifestPath := p.GetManifestFilePath()
+	manifestDir := filepath.Dir(manifestPath)
+
+	for _, key := range slices.Sorted(maps.Keys(p.prevOutputs)) {
+		if _, exists := p.outputs[key]; exists {
+			continue
+		}
+
+		stalePath := filepath.Join(manifestDir, filepath.FromSlash(key))
+
+		content, err := os.ReadFile(stalePath)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if !hasGeneratedHeader(content) {
+			fmt.Printf("Warning: the stale file at %q was not removed because it does not contain the %q header.\n", stalePath, GeneratedHeader)
+			continue
+		}
+
+		if err := os.Remove(stalePath); err != nil {
+			return fmt.Errorf("Failed to remove the stale file at %q: %w", stalePath, err)
+		}
+
+		fmt.Printf("🗑️ Removed stale file: %s\n", stalePath)
+	}
+
+	manifest, err := readManifestFile(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	if len(p.outputs) == 0 {
+		if _, exists := manifest.Packages[p.Name]; !exists {
+			return nil
+		}
+		delete(manifest.Packages, p.Name)
+	} else {
+		manifest.Packages[p.Name] = p.outputs
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to encode the manifest: %w", err)
+	}
+
+	if err := os.MkdirAll(manifestDir, 0755); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(manifestPath, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("Failed to write the manifest at %q: %w", manifestPath, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package protoschema_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIncrementalGeneration(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	newPackage := func(fileNames ...string) *sb.ProtoPackage {
+		pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+			Name:               "incremental.v1",
+			GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/incrementalv1",
+			ProtoRoot:          tmpDir,
+			ConverterOutputDir: filepath.Join(tmpDir, "converter"),
+		})
+
+		for _, name := range fileNames {
+			pkg.NewFile(sb.FileSchema{Name: name}).NewMessage(sb.MessageSchema{Name: "Message" + name, Fields: sb.FieldsMap{1: sb.String("name")}})
+		}
+
+		return pkg
+	}
+
+	itemPath := filepath.Join(tmpDir, "incremental/v1/item.proto")
+	orderPath := filepath.Join(tmpDir, "incremental/v1/order.proto")
+
+	assert.NoError(t, newPackage("item", "order").Generate())
+
+	content, err := os.ReadFile(itemPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "// "+sb.GeneratedHeader)
+
+	_, err = os.Stat(filepath.Join(tmpDir, sb.DefaultManifestFileName))
+	assert.NoError(t, err)
+
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	assert.NoError(t, os.Chtimes(itemPath, past, past))
+
+	// The unchanged file is not rewritten, and the file that is no longer generated is removed
+	assert.NoError(t, newPackage("item").Generate())
+
+	info, err := os.Stat(itemPath)
+	if assert.NoError(t, err) {
+		assert.True(t, info.ModTime().Equal(past), "Unchanged files should not be rewritten")
+	}
+
+	_, err = os.Stat(orderPath)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	// A generated file that was modified is restored
+	assert.NoError(t, os.WriteFile(itemPath, []byte("// "+sb.GeneratedHeader+"\n// Edited\n"), 0644))
+	assert.NoError(t, newPackage("item").Generate())
+
+	content, err = os.ReadFile(itemPath)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(content), "Edited")
+
+	// Files without the header are owned by the user, so they are never overwritten or removed
+	userContent := []byte("syntax = \"proto3\";\n")
+	assert.NoError(t, os.WriteFile(orderPath, userContent, 0644))
+	assert.Error(t, newPackage("item", "order").Generate())
+
+	assert.NoError(t, newPackage("item").Generate())
+
+	content, err = os.ReadFile(orderPath)
+	assert.NoError(t, err)
+	assert.Equal(t, userContent, content)
+
+	// Without an entry in the manifest, the files without the header are still owned by the user
+	legacyDir := t.TempDir()
+	legacyPath := filepath.Join(legacyDir, "incremental/v1/item.proto")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(legacyPath), 0755))
+	assert.NoError(t, os.WriteFile(legacyPath, userContent, 0644))
+
+	newLegacyPackage := func(overwrite bool) *sb.ProtoPackage {
+		pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+			Name:                   "incremental.v1",
+			GoPackage:              "github.com/Rick-Phoenix/protoschema/gen/incrementalv1",
+			ProtoRoot:              legacyDir,
+			ConverterOutputDir:     filepath.Join(legacyDir, "converter"),
+			OverwriteUnmarkedFiles: overwrite,
+		})
+		pkg.NewFile(sb.FileSchema{Name: "item"}).NewMessage(sb.MessageSchema{Name: "Messageitem", Fields: sb.FieldsMap{1: sb.String("name")}})
+
+		return pkg
+	}
+
+	assert.ErrorContains(t, newLegacyPackage(false).Generate(), "OverwriteUnmarkedFiles")
+
+	content, err = os.ReadFile(legacyPath)
+	assert.NoError(t, err)
+	assert.Equal(t, userContent, content)
+
+	// The files without the header can be overwritten only when it is explicitly allowed
+	assert.NoError(t, newLegacyPackage(true).Generate())
+
+	content, err = os.ReadFile(legacyPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "// "+sb.GeneratedHeader)
+}
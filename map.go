@@ -53,8 +53,16 @@ func (b *MapField) Build(fieldNr uint32, imports Set) (FieldData, error) {
 		err = errors.Join(err, fmt.Errorf("Cannot use a map as a value type of another map (must be wrapped in a message type first.)"))
 	}
 
-	options := make([]string, len(b.repeatedOptions))
-	copy(options, b.repeatedOptions)
+	if b.defaultValue != nil {
+		err = errors.Join(err, fmt.Errorf("Map fields cannot have a default value."))
+	}
+
+	if b.labelRequired {
+		err = errors.Join(err, fmt.Errorf("Map fields cannot have the required label."))
+	}
+
+	options, celErr := b.buildRepeatedOptions()
+	err = errors.Join(err, celErr)
 
 	for _, item := range []struct {
 		MapType string
@@ -76,11 +84,15 @@ func (b *MapField) Build(fieldNr uint32, imports Set) (FieldData, error) {
 	options, optErr := getOptions(b.options, options)
 
 	err = errors.Join(err, optErr)
+
+	data := FieldData{Name: b.name, ProtoType: fmt.Sprintf("map<%s, %s>", keysField.ProtoType, valuesField.ProtoType), GoType: b.goType, Optional: keysField.Optional, FieldNr: fieldNr, Options: options, IsNonScalar: true, IsMap: b.isMap, Features: b.features, ExtensionOptions: extensionOptions(b.options), Doc: b.doc, TrailingComment: b.trailingComment}
+
+	err = errors.Join(err, checkFieldFeatureTypes(data))
 	if err != nil {
 		return FieldData{}, err
 	}
 
-	return FieldData{Name: b.name, ProtoType: fmt.Sprintf("map<%s, %s>", keysField.ProtoType, valuesField.ProtoType), GoType: b.goType, Optional: keysField.Optional, FieldNr: fieldNr, Options: options, IsNonScalar: true, IsMap: b.isMap}, nil
+	return data, nil
 }
 
 // Rule: this map must have at least this amount of key-value pairs.
@@ -27,15 +27,27 @@ type MessageSchema struct {
 	// The name of the message. Use the getter to retrieve it, as it adds the parent message's prefix automatically (if there is one).
 	Name string
 	// The map of fields for this message. The number corresponds to the field's number in the proto file.
-	Fields   FieldsMap
-	oneofs   []OneofGroup
-	enums    []*EnumGroup
-	messages []*MessageSchema
+	Fields FieldsMap
+	// Reusable sets of fields to add to this message, placed with the At or InRange methods of a FieldSet. Explicit fields take precedence in case of collisions, which are reported as warnings.
+	Mixins []FieldMixin
+	// An ordered list of fields that can be used instead of (or together with) the Fields map. Their numbers are assigned automatically and recorded in the package's lockfile, and the fields that are removed from this list will have their names and numbers reserved automatically.
+	FieldsList FieldsList
+	oneofs     []OneofGroup
+	enums      []*EnumGroup
+	messages   []*MessageSchema
 	// The options for this message. The methods on this message and its fields that uses protovalidate rules will automatically add the necessary options to this.
 	Options         []ProtoOption
 	ReservedNumbers []uint
 	ReservedRanges  []Range
 	ReservedNames   []string
+	// The ranges of field numbers that can be used by extensions of this message (not available in proto3).
+	ExtensionRanges []Range
+	// The features for this message (editions only). Only the Go api_level can be set on messages.
+	Features Features
+	// A comment that is rendered above this message in the proto file, and that is used as its leading comment in the descriptor's SourceCodeInfo.
+	Doc string
+	// A comment that is rendered on the same line as the message's opening brace. Line breaks are replaced with spaces.
+	TrailingComment string
 	// The struct to which this schema should conform. If nil, validation is skipped. If defined, a method will check if every field in the model (that is not included in the ModelIgnore slice) has the right name and type in the schema's output, or if there are missing or extra fields, causing a fatal error if that is the case.
 	// Must be a pointer.
 	Model any
@@ -69,7 +81,11 @@ type MessageData struct {
 	ReservedNumbers []uint
 	ReservedRanges  []Range
 	ReservedNames   []string
+	ExtensionRanges []Range
 	Options         []ProtoOption
+	Features        Features
+	Doc             string
+	TrailingComment string
 	Enums           []EnumGroup
 	File            *FileSchema
 	Package         *ProtoPackage
@@ -95,9 +111,17 @@ func (m *MessageSchema) GetImportPath() string {
 	return m.ImportPath
 }
 
-// Gets a FieldBuilder instance with a specific name, causes a fatal error if the field is not found. Modifying this field will also modify the original.
+// Gets a FieldBuilder instance with a specific name (including those added by mixins), causes a fatal error if the field is not found. Modifying this field will also modify the original.
 func (m *MessageSchema) GetField(n string) FieldBuilder {
-	for _, f := range m.Fields {
+	fields, _, _ := m.mergeFields()
+
+	for _, f := range fields {
+		if f.GetName() == n {
+			return f
+		}
+	}
+
+	for _, f := range m.FieldsList {
 		if f.GetName() == n {
 			return f
 		}
@@ -107,15 +131,43 @@ func (m *MessageSchema) GetField(n string) FieldBuilder {
 	return nil
 }
 
-// Returns a map with the field names as keys and the FieldBuilder instances as the values. Modifying these will modify their original values.
+// Returns the field with the given name, including the fields added by mixins and the fields of the oneofs, and false if the message has no such field.
+func (m *MessageSchema) LookupField(name string) (FieldBuilder, bool) {
+	if f, exists := m.GetFields()[name]; exists {
+		return f, true
+	}
+
+	for _, of := range m.oneofs {
+		for _, f := range of.Fields {
+			if f.GetName() == name {
+				return f, true
+			}
+		}
+
+		for _, f := range of.FieldsList {
+			if f.GetName() == name {
+				return f, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// Returns a map with the field names as keys and the FieldBuilder instances as the values (including those added by mixins). Modifying these will modify their original values.
 func (m *MessageSchema) GetFields() map[string]FieldBuilder {
 	out := make(map[string]FieldBuilder)
+	fields, _, _ := m.mergeFields()
 
-	keys := slices.Sorted(maps.Keys(m.Fields))
+	keys := slices.Sorted(maps.Keys(fields))
 
 	for _, k := range keys {
-		f := m.Fields[k]
+		f := fields[k]
+
+		out[f.GetName()] = f
+	}
 
+	for _, f := range m.FieldsList {
 		out[f.GetName()] = f
 	}
 
@@ -123,14 +175,19 @@ func (m *MessageSchema) GetFields() map[string]FieldBuilder {
 }
 
 func (m *MessageSchema) GetFieldNames() []string {
-	out := make([]string, len(m.Fields))
+	fields, _, _ := m.mergeFields()
+	out := make([]string, len(fields))
 	i := 0
 
-	for _, field := range m.Fields {
+	for _, field := range fields {
 		out[i] = field.GetName()
 		i++
 	}
 
+	for _, field := range m.FieldsList {
+		out = append(out, field.GetName())
+	}
+
 	return out
 }
 
@@ -157,7 +214,7 @@ func (m *MessageSchema) GetFullName(pkg *ProtoPackage) string {
 		return m.GetName()
 	}
 
-	return m.Package.GetName() + "." + m.GetName()
+	return referenceName(pkg, m.Package, m.GetName())
 }
 
 // Returns true if the package given as the argument is the same as the MessageSchema's.
@@ -198,6 +255,11 @@ func (m *MessageSchema) CelOption(id, message, expression string) {
 	m.Options = append(m.Options, opt)
 }
 
+// Adds a Cel option to this message with an expression that is built (and checked) when the message is processed, such as one created with the cel subpackage. The expression can reference the fields that are added to the message after this call.
+func (m *MessageSchema) CelRule(id, message string, expression CelExpression) {
+	m.Options = append(m.Options, ProtoOption{Name: "(buf.validate.message).cel", Value: celRule{id: id, message: message, expression: expression}})
+}
+
 func (m *MessageSchema) checkModel() error {
 	model := reflect.TypeOf(m.Model).Elem()
 	modelName := model.String()
@@ -213,7 +275,13 @@ func (m *MessageSchema) checkModel() error {
 	hasConverterFunc := m.Package != nil && m.ConverterFunc != nil
 
 	if !hasConverterFunc {
-		m.Package.converter.MessageConverters = append(m.Package.converter.MessageConverters, conv)
+		// The converter of a message that was already processed (such as when the files are built more than once) is replaced
+		converters := m.Package.converter.MessageConverters
+		if idx := slices.IndexFunc(converters, func(c *messageConverter) bool { return c.Resource == conv.Resource }); idx != -1 {
+			converters[idx] = conv
+		} else {
+			m.Package.converter.MessageConverters = append(converters, conv)
+		}
 		m.Package.converter.Imports[getPkgPath(model)] = present
 	}
 
@@ -235,10 +303,7 @@ func (m *MessageSchema) checkModel() error {
 				}
 				continue
 			}
-			modelFieldName := field.Tag.Get("json")
-			if modelFieldName == "" {
-				modelFieldName = toSnakeCase(field.Name)
-			}
+			modelFieldName := getModelFieldName(field)
 			ignore := ignores.Has(modelFieldName)
 			fieldType := field.Type.String()
 
@@ -287,6 +352,15 @@ func (m *MessageSchema) checkModel() error {
 	return err
 }
 
+// Returns the name of the message field that corresponds to a field of the model, which is the json tag or, if missing, the name of the field in snake case.
+func getModelFieldName(field reflect.StructField) string {
+	if name := field.Tag.Get("json"); name != "" {
+		return name
+	}
+
+	return toSnakeCase(field.Name)
+}
+
 func (m *MessageSchema) build(imports Set) (MessageData, error) {
 	var protoFields []FieldData
 	var errAgg error
@@ -298,10 +372,26 @@ func (m *MessageSchema) build(imports Set) (MessageData, error) {
 		}
 	}
 
-	fieldNumbers := slices.Sorted(maps.Keys(m.Fields))
+	fields, warnings, mixinErr := m.mergeFields()
+	errAgg = errors.Join(errAgg, mixinErr)
+
+	for _, warning := range warnings {
+		fmt.Printf("Warning for message %q: %s\n", m.GetName(), warning)
+	}
+
+	autoNrs, numbersErr := m.assignFieldNumbers(fields)
+	errAgg = errors.Join(errAgg, numbersErr)
+
+	for _, f := range m.FieldsList {
+		if nr, ok := autoNrs.numbers[f.GetName()]; ok {
+			fields[nr] = f
+		}
+	}
+
+	fieldNumbers := slices.Sorted(maps.Keys(fields))
 
 	for _, fieldNr := range fieldNumbers {
-		fieldBuilder := m.Fields[fieldNr]
+		fieldBuilder := fields[fieldNr]
 		field, err := fieldBuilder.Build(fieldNr, imports)
 		if err != nil {
 			errAgg = errors.Join(errAgg, indentErrors(fmt.Sprintf("Errors for field %q", field.Name), err))
@@ -313,7 +403,7 @@ func (m *MessageSchema) build(imports Set) (MessageData, error) {
 	oneOfs := []OneofData{}
 
 	for _, oneof := range m.oneofs {
-		data, oneofErr := oneof.build(imports)
+		data, oneofErr := oneof.build(imports, autoNrs.numbers)
 
 		if oneofErr != nil {
 			errAgg = errors.Join(errAgg, indentErrors(fmt.Sprintf("Errors for oneof %q", data.Name), oneofErr))
@@ -332,7 +422,28 @@ func (m *MessageSchema) build(imports Set) (MessageData, error) {
 		subMessages = append(subMessages, data)
 	}
 
-	out := MessageData{Name: m.Name, Fields: protoFields, ReservedNumbers: m.ReservedNumbers, ReservedRanges: m.ReservedRanges, ReservedNames: m.ReservedNames, Options: m.Options, Oneofs: oneOfs, Enums: u.ToValSlice(m.enums), Messages: subMessages, File: m.File, Package: m.Package, Metadata: m.Metadata}
+	options, celErr := buildCelRules(m.Options)
+	errAgg = errors.Join(errAgg, celErr)
+
+	enums := []EnumGroup{}
+
+	for _, e := range m.enums {
+		data, err := e.build()
+		if err != nil {
+			errAgg = errors.Join(errAgg, indentErrors(fmt.Sprintf("Errors for enum %q", e.Name), err))
+		}
+
+		enums = append(enums, data)
+	}
+
+	out := MessageData{Name: m.Name, Fields: protoFields, ReservedNumbers: slices.Concat(m.ReservedNumbers, autoNrs.reservedNumbers), ReservedRanges: m.ReservedRanges, ReservedNames: slices.Concat(m.ReservedNames, autoNrs.reservedNames), ExtensionRanges: m.ExtensionRanges, Options: slices.Concat(options, m.Features.options()), Features: m.Features, Doc: m.Doc, TrailingComment: m.TrailingComment, Oneofs: oneOfs, Enums: enums, Messages: subMessages, File: m.File, Package: m.Package, Metadata: m.Metadata}
+
+	errAgg = errors.Join(errAgg, checkMessageNumbers(out))
+	errAgg = errors.Join(errAgg, checkMessageSyntax(&out, m.File))
+
+	if m.Features.GoAPILevel != "" {
+		imports[goFeaturesImport] = present
+	}
 
 	if m.Hook != nil {
 		err := m.Hook(out)
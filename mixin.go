@@ -0,0 +1,156 @@
+package protoschema
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// A reusable, ordered set of fields (for example audit or tenancy columns like id, created_at and updated_at) that can be added to many messages while keeping the same names and rules.
+// The FieldBuilder instances are shared among all the messages that use this set, so modifying one of them will affect all of those messages.
+type FieldSet struct {
+	// The name of the set. Used to identify it in warnings and errors.
+	Name string
+	// The fields of the set, in the order in which they will be numbered.
+	Fields []FieldBuilder
+}
+
+// A FieldSet that has been placed at a specific position in a message's field numbers. It should be created with the At or InRange methods from a FieldSet.
+type FieldMixin struct {
+	// The set of fields that this mixin adds to the message.
+	Set *FieldSet
+	// The field number assigned to the first field in the set. The following fields receive consecutive numbers.
+	Base uint32
+	// If defined, the whole range is claimed by this mixin, and the fields are numbered starting from its first number.
+	Range *Range
+}
+
+// The constructor for a FieldSet.
+func NewFieldSet(name string, fields ...FieldBuilder) *FieldSet {
+	return &FieldSet{Name: name, Fields: fields}
+}
+
+// Places the fields of this set at consecutive field numbers, starting from the base number.
+func (fs *FieldSet) At(base uint32) FieldMixin {
+	return FieldMixin{Set: fs, Base: base}
+}
+
+// Places the fields of this set in a number range that is entirely claimed by this set, so that it can grow without colliding with the message's own fields.
+// The fields are numbered starting from the first number of the range.
+func (fs *FieldSet) InRange(r Range) FieldMixin {
+	return FieldMixin{Set: fs, Base: uint32(r[0]), Range: &r}
+}
+
+// Returns the name of the mixin's field set, defaulting to an empty string if the set is nil.
+func (fm FieldMixin) GetName() string {
+	if fm.Set == nil {
+		return ""
+	}
+
+	return fm.Set.Name
+}
+
+// Returns the fields of this mixin, mapped to their assigned field numbers.
+func (fm FieldMixin) Fields() (FieldsMap, error) {
+	out := make(FieldsMap)
+
+	if fm.Set == nil {
+		return out, fmt.Errorf("Mixin has no field set.")
+	}
+
+	if fm.Range != nil {
+		r := *fm.Range
+		if r[0] <= 0 || r[1] < r[0] {
+			return out, fmt.Errorf("Invalid range %d to %d for the field set %q.", r[0], r[1], fm.Set.Name)
+		}
+
+		if size := int(r[1]-r[0]) + 1; len(fm.Set.Fields) > size {
+			return out, fmt.Errorf("The field set %q has %d fields, but its range (%d to %d) only has room for %d.", fm.Set.Name, len(fm.Set.Fields), r[0], r[1], size)
+		}
+	} else if fm.Base == 0 {
+		return out, fmt.Errorf("Missing base field number for the field set %q.", fm.Set.Name)
+	}
+
+	for i, f := range fm.Set.Fields {
+		out[fm.Base+uint32(i)] = f
+	}
+
+	return out, nil
+}
+
+func (fm FieldMixin) claims(nr uint32) bool {
+	if fm.Range != nil {
+		return int64(nr) >= int64(fm.Range[0]) && int64(nr) <= int64(fm.Range[1])
+	}
+
+	return fm.Set != nil && nr >= fm.Base && nr < fm.Base+uint32(len(fm.Set.Fields))
+}
+
+// Merges the message's explicit fields with the fields from its mixins. Explicit fields take precedence over mixin fields, and every collision is reported as a warning.
+func (m *MessageSchema) mergeFields() (FieldsMap, []string, error) {
+	out := maps.Clone(m.Fields)
+	if out == nil {
+		out = make(FieldsMap)
+	}
+
+	if len(m.Mixins) == 0 {
+		return out, nil, nil
+	}
+
+	var warnings []string
+	var err error
+
+	explicitNames := make(map[string]uint32)
+	explicitNumbers := slices.Sorted(maps.Keys(m.Fields))
+
+	for _, nr := range explicitNumbers {
+		explicitNames[m.Fields[nr].GetName()] = nr
+	}
+
+	mixinNames := make(map[string]string)
+
+	for _, mixin := range m.Mixins {
+		fields, mixinErr := mixin.Fields()
+		if mixinErr != nil {
+			err = errors.Join(err, mixinErr)
+			continue
+		}
+
+		for _, nr := range explicitNumbers {
+			if _, assigned := fields[nr]; !assigned && mixin.claims(nr) {
+				warnings = append(warnings, fmt.Sprintf("Field %q (%d) is inside the range claimed by the field set %q.", m.Fields[nr].GetName(), nr, mixin.GetName()))
+			}
+		}
+
+		for _, nr := range slices.Sorted(maps.Keys(fields)) {
+			field := fields[nr]
+			name := field.GetName()
+
+			if existing, exists := m.Fields[nr]; exists {
+				warnings = append(warnings, fmt.Sprintf("Field number %d from the field set %q (%q) collides with the explicit field %q. The explicit field will be used.", nr, mixin.GetName(), name, existing.GetName()))
+				continue
+			}
+
+			if explicitNr, exists := explicitNames[name]; exists {
+				warnings = append(warnings, fmt.Sprintf("Field %q from the field set %q collides with the explicit field with the same name (%d). The explicit field will be used.", name, mixin.GetName(), explicitNr))
+				continue
+			}
+
+			if existing, exists := out[nr]; exists {
+				err = errors.Join(err, fmt.Errorf("Field number %d is used by both %q and %q from different field sets.", nr, existing.GetName(), name))
+				continue
+			}
+
+			if otherSet, exists := mixinNames[name]; exists {
+				err = errors.Join(err, fmt.Errorf("Field %q is defined by both the %q and %q field sets.", name, otherSet, mixin.GetName()))
+				continue
+			}
+
+			mixinNames[name] = mixin.GetName()
+			out[nr] = field
+		}
+	}
+
+	return out, warnings, err
+}
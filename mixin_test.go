@@ -0,0 +1,60 @@
+package protoschema_test
+
+import (
+	"testing"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldMixins(t *testing.T) {
+	pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:      "mixins.v1",
+		GoPackage: "github.com/Rick-Phoenix/protoschema/gen/mixinsv1",
+		ProtoRoot: t.TempDir(),
+	})
+
+	file := pkg.NewFile(sb.FileSchema{Name: "mixins"})
+
+	audit := sb.NewFieldSet("audit",
+		sb.Timestamp("created_at"),
+		sb.Timestamp("updated_at"),
+		sb.Timestamp("deleted_at"),
+	)
+
+	tenancy := sb.NewFieldSet("tenancy", sb.Int64("tenant_id").Gt(0))
+
+	msg := file.NewMessage(sb.MessageSchema{
+		Name: "Post",
+		Fields: sb.FieldsMap{
+			1:   sb.Int64("id"),
+			2:   sb.String("title"),
+			101: sb.String("updated_at_override"),
+		},
+		Mixins: []sb.FieldMixin{audit.At(100), tenancy.InRange(sb.Range{200, 209})},
+	})
+
+	fields := msg.GetFields()
+	for _, name := range []string{"id", "title", "created_at", "deleted_at", "tenant_id", "updated_at_override"} {
+		assert.Contains(t, fields, name)
+	}
+	// The explicit field takes precedence
+	assert.NotContains(t, fields, "updated_at")
+
+	files := pkg.BuildFiles()
+	numbers := make(map[string]uint32)
+	for _, f := range files[0].Messages[0].Fields {
+		numbers[f.Name] = f.FieldNr
+	}
+
+	assert.Equal(t, uint32(100), numbers["created_at"])
+	assert.Equal(t, uint32(101), numbers["updated_at_override"])
+	assert.Equal(t, uint32(102), numbers["deleted_at"])
+	assert.Equal(t, uint32(200), numbers["tenant_id"])
+
+	_, err := sb.NewFieldSet("too_small", sb.Int64("a"), sb.Int64("b")).InRange(sb.Range{10, 10}).Fields()
+	assert.Error(t, err, "A field set should not fit in a range that is too small")
+
+	_, err = audit.At(0).Fields()
+	assert.Error(t, err, "A field set without a base number should cause an error")
+}
@@ -0,0 +1,390 @@
+package protoschema
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+
+	"buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	u "github.com/Rick-Phoenix/goutils"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// A rule that is not satisfied by an instance of a model.
+type Violation struct {
+	// The path of the field, such as "posts[0].title"
+	Field   string
+	RuleId  string
+	Message string
+	// Whether the violation refers to the key of a map entry rather than its value
+	ForKey bool
+	// The violation in the protovalidate format, with the structured paths of the field and of the rule
+	Proto *validate.Violation
+}
+
+// Validates an instance of a model (a struct or a pointer to a struct, such as a database row) against the rules of this message. The model is copied to a dynamic message of this schema, so the go types of the message and the converters do not need to be generated.
+// The fields of the model are mapped to those of the message in the same way as the Model of the schema (with their json tag or their name in snake case, skipping the fields in ModelIgnore), and the fields of embedded structs are included. Nested structs are validated with the rules of the message of their MsgField, as well as the items of slices and the values of maps, and time.Time and time.Duration are used for timestamps and durations.
+// The values that implement driver.Valuer (such as sql.NullString or sql.NullTime) are converted with their Value method, and the fields are left unset if it returns nil.
+// The rules are evaluated in the same way as Validate, so the field paths and the rule ids are the same as those of protovalidate.
+// This causes a fatal error if the files of the package cannot be compiled, if the value is not a struct or if the value of a field cannot be converted to the type of the field. Use TryValidateModel to handle the error instead.
+func (m *MessageSchema) ValidateModel(v any) []Violation {
+	violations, err := m.TryValidateModel(v)
+	if err != nil {
+		log.Fatalf("Could not validate the model for %q: %v", m.GetName(), err)
+	}
+
+	return violations
+}
+
+// Validates an instance of a model in the same way as ValidateModel, returning an error if the files of the package cannot be compiled or if the value is not a struct, and an error for each value that cannot be converted to the type of its field.
+func (m *MessageSchema) TryValidateModel(v any) ([]Violation, error) {
+	desc, err := m.Descriptor()
+	if err != nil {
+		return nil, err
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Expected a struct, found %T.", v)
+	}
+
+	conv := &modelConversion{skipped: make(map[protoreflect.FieldDescriptor]bool)}
+
+	msg := dynamicpb.NewMessage(desc)
+	conv.setFields(msg, rv, m, "")
+
+	if len(conv.errors) > 0 {
+		return nil, indentErrors(fmt.Sprintf("Could not convert the model to the %q message", m.GetName()), errors.Join(conv.errors...))
+	}
+
+	ev := &ruleEvaluator{now: time.Now(), skipped: conv.skipped}
+	ev.message(msg, nil)
+
+	var violations []Violation
+	for _, violation := range ev.violations {
+		violations = append(violations, Violation{
+			Field:   FieldPathString(violation.GetField()),
+			RuleId:  violation.GetRuleId(),
+			Message: violation.GetMessage(),
+			ForKey:  violation.GetForKey(),
+			Proto:   violation,
+		})
+	}
+
+	return violations, nil
+}
+
+// The state of the conversion of a model to a message, with the fields that should not be validated and the values that could not be converted.
+type modelConversion struct {
+	skipped map[protoreflect.FieldDescriptor]bool
+	errors  []error
+}
+
+// Records a value that cannot be converted to the expected type.
+func (c *modelConversion) errorf(path string, value reflect.Value, expected string) {
+	c.errors = append(c.errors, fmt.Errorf("The value of %q (of type %s) cannot be converted to %s.", path, value.Type(), expected))
+}
+
+// Returns the name of the type of a single value of a field, which is the full name of its message, or its kind for the other types.
+func fieldTypeName(fd protoreflect.FieldDescriptor) string {
+	if fd.Message() != nil {
+		return string(fd.Message().FullName())
+	}
+
+	return fd.Kind().String()
+}
+
+// Sets the fields of a message with the values of the fields of a model struct. The schema (if known) is used for the schemas of the nested messages and for the fields to ignore, which are added to the skipped fields.
+func (c *modelConversion) setFields(msg protoreflect.Message, model reflect.Value, schema *MessageSchema, path string) {
+	fields := msg.Descriptor().Fields()
+
+	var ignored []string
+	var schemaFields map[string]FieldBuilder
+
+	if schema != nil {
+		ignored = schema.ModelIgnore
+		schemaFields = schema.GetFields()
+	}
+
+	ignores := u.NewSet(ignored...)
+
+	for _, name := range ignored {
+		if fd := fields.ByName(protoreflect.Name(name)); fd != nil {
+			c.skipped[fd] = true
+		}
+	}
+
+	var setFields func(t reflect.Value)
+	setFields = func(t reflect.Value) {
+		for i := range t.NumField() {
+			field := t.Type().Field(i)
+			value := t.Field(i)
+
+			if field.Anonymous {
+				if value.Kind() == reflect.Pointer {
+					if value.IsNil() {
+						continue
+					}
+					value = value.Elem()
+				}
+
+				if value.Kind() == reflect.Struct {
+					setFields(value)
+				}
+				continue
+			}
+
+			if !field.IsExported() {
+				continue
+			}
+
+			name := getModelFieldName(field)
+			fd := fields.ByName(protoreflect.Name(name))
+
+			if fd == nil || ignores.Has(name) {
+				continue
+			}
+
+			var fieldSchema *MessageSchema
+			if builder, exists := schemaFields[name]; exists {
+				fieldSchema = builder.GetMessageRef()
+			}
+
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+
+			c.setField(msg, fd, value, fieldSchema, fieldPath)
+		}
+	}
+
+	setFields(model)
+}
+
+// Sets a field of a message with a value of a model. Nil pointers are left unset.
+func (c *modelConversion) setField(msg protoreflect.Message, fd protoreflect.FieldDescriptor, value reflect.Value, schema *MessageSchema, path string) {
+	value, isSet := derefModelValue(value)
+	if !isSet {
+		return
+	}
+
+	switch {
+	case fd.IsList():
+		if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+			c.errorf(path, value, "a list of "+fieldTypeName(fd))
+			return
+		}
+
+		list := msg.Mutable(fd).List()
+		for i := range value.Len() {
+			item, isSet := derefModelValue(value.Index(i))
+			if !isSet {
+				continue
+			}
+
+			if pv, ok := c.value(item, fd, list.NewElement, schema, fmt.Sprintf("%s[%d]", path, i)); ok {
+				list.Append(pv)
+			}
+		}
+	case fd.IsMap():
+		if value.Kind() != reflect.Map {
+			c.errorf(path, value, "a map")
+			return
+		}
+
+		entries := msg.Mutable(fd).Map()
+		iter := value.MapRange()
+
+		for iter.Next() {
+			entryPath := fmt.Sprintf("%s[%v]", path, iter.Key())
+			key, keyOk := c.value(iter.Key(), fd.MapKey(), nil, nil, entryPath)
+			entryValue, isSet := derefModelValue(iter.Value())
+
+			if !keyOk || !isSet {
+				continue
+			}
+
+			if pv, ok := c.value(entryValue, fd.MapValue(), entries.NewValue, schema, entryPath); ok {
+				entries.Set(key.MapKey(), pv)
+			}
+		}
+	default:
+		if pv, ok := c.value(value, fd, func() protoreflect.Value { return msg.NewField(fd) }, schema, path); ok {
+			msg.Set(fd, pv)
+		}
+	}
+}
+
+// Dereferences the pointers and interfaces of a model value, returning false if the value is nil.
+func derefModelValue(value reflect.Value) (reflect.Value, bool) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return value, false
+		}
+
+		if _, isMessage := value.Interface().(proto.Message); isMessage {
+			return value, true
+		}
+
+		value = value.Elem()
+	}
+
+	return value, value.IsValid()
+}
+
+var (
+	timeType         = reflect.TypeFor[time.Time]()
+	timeDurationType = reflect.TypeFor[time.Duration]()
+)
+
+// Converts a single model value to the protobuf value for a field (or for the items of a list, or the keys and values of a map), using newMessage to create the messages.
+// The second result is false if the value should not be set, because its driver.Valuer returned nil or because it cannot be converted, which is recorded as an error.
+func (c *modelConversion) value(value reflect.Value, fd protoreflect.FieldDescriptor, newMessage func() protoreflect.Value, schema *MessageSchema, path string) (protoreflect.Value, bool) {
+	if valuer, ok := modelValuer(value); ok {
+		driverValue, err := valuer.Value()
+		if err != nil {
+			c.errors = append(c.errors, fmt.Errorf("Failed to get the value of %q: %w", path, err))
+			return protoreflect.Value{}, false
+		}
+
+		if driverValue == nil {
+			return protoreflect.Value{}, false
+		}
+
+		value = reflect.ValueOf(driverValue)
+	}
+
+	if pv, ok := c.convert(value, fd, newMessage, schema, path); ok {
+		return pv, true
+	}
+
+	c.errorf(path, value, fieldTypeName(fd))
+
+	return protoreflect.Value{}, false
+}
+
+// Returns the driver.Valuer implemented by a model value or by its pointer, unless the value is a protobuf message.
+func modelValuer(value reflect.Value) (driver.Valuer, bool) {
+	if !value.CanInterface() || isProtoMessage(value) {
+		return nil, false
+	}
+
+	if valuer, ok := value.Interface().(driver.Valuer); ok {
+		return valuer, true
+	}
+
+	if value.CanAddr() {
+		valuer, ok := value.Addr().Interface().(driver.Valuer)
+		return valuer, ok
+	}
+
+	return nil, false
+}
+
+// Converts a model value to the protobuf value for a field, returning false if its type does not match the type of the field.
+func (c *modelConversion) convert(value reflect.Value, fd protoreflect.FieldDescriptor, newMessage func() protoreflect.Value, schema *MessageSchema, path string) (protoreflect.Value, bool) {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if newMessage == nil {
+			return protoreflect.Value{}, false
+		}
+
+		pv := newMessage()
+		msg := pv.Message()
+
+		switch {
+		case value.Type() == timeType && fd.Message().FullName() == "google.protobuf.Timestamp":
+			t := value.Interface().(time.Time)
+			setSecondsAndNanos(msg, t.Unix(), int64(t.Nanosecond()))
+		case value.Type() == timeDurationType && fd.Message().FullName() == "google.protobuf.Duration":
+			d := value.Interface().(time.Duration)
+			setSecondsAndNanos(msg, int64(d/time.Second), int64(d%time.Second))
+		case value.CanInterface() && isProtoMessage(value):
+			// Messages from generated types are copied through their binary format, since their descriptors may be different
+			data, err := proto.Marshal(value.Interface().(proto.Message))
+			if err != nil || proto.Unmarshal(data, msg.Interface()) != nil {
+				return protoreflect.Value{}, false
+			}
+		case value.Kind() == reflect.Struct:
+			c.setFields(msg, value, schema, path)
+		default:
+			return protoreflect.Value{}, false
+		}
+
+		return pv, true
+	case protoreflect.EnumKind:
+		switch {
+		case value.CanInt():
+			return protoreflect.ValueOfEnum(protoreflect.EnumNumber(value.Int())), true
+		case value.CanUint():
+			return protoreflect.ValueOfEnum(protoreflect.EnumNumber(value.Uint())), true
+		case value.Kind() == reflect.String:
+			if ev := fd.Enum().Values().ByName(protoreflect.Name(value.String())); ev != nil {
+				return protoreflect.ValueOfEnum(ev.Number()), true
+			}
+		}
+	case protoreflect.BoolKind:
+		if value.Kind() == reflect.Bool {
+			return protoreflect.ValueOfBool(value.Bool()), true
+		}
+	case protoreflect.StringKind:
+		if value.Kind() == reflect.String {
+			return protoreflect.ValueOfString(value.String()), true
+		}
+	case protoreflect.BytesKind:
+		if value.Kind() == reflect.String {
+			return protoreflect.ValueOfBytes([]byte(value.String())), true
+		}
+
+		if value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Uint8 {
+			return protoreflect.ValueOfBytes(value.Bytes()), true
+		}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		if value.CanInt() {
+			return protoreflect.ValueOfInt32(int32(value.Int())), true
+		}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		if value.CanInt() {
+			return protoreflect.ValueOfInt64(value.Int()), true
+		}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		if value.CanUint() {
+			return protoreflect.ValueOfUint32(uint32(value.Uint())), true
+		}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		if value.CanUint() {
+			return protoreflect.ValueOfUint64(value.Uint()), true
+		}
+	case protoreflect.FloatKind:
+		if value.CanFloat() {
+			return protoreflect.ValueOfFloat32(float32(value.Float())), true
+		}
+	case protoreflect.DoubleKind:
+		if value.CanFloat() {
+			return protoreflect.ValueOfFloat64(value.Float()), true
+		}
+	}
+
+	return protoreflect.Value{}, false
+}
+
+func isProtoMessage(value reflect.Value) bool {
+	_, isMessage := value.Interface().(proto.Message)
+	return isMessage
+}
+
+func setSecondsAndNanos(msg protoreflect.Message, seconds, nanos int64) {
+	fields := msg.Descriptor().Fields()
+	msg.Set(fields.ByName("seconds"), protoreflect.ValueOfInt64(seconds))
+	msg.Set(fields.ByName("nanos"), protoreflect.ValueOfInt32(int32(nanos)))
+}
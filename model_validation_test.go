@@ -0,0 +1,141 @@
+package protoschema_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"github.com/stretchr/testify/assert"
+)
+
+type ModelAuthor struct {
+	Email string `json:"email"`
+}
+
+type ModelTimestamps struct {
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ModelPost struct {
+	ModelTimestamps
+	Title    string             `json:"title"`
+	Summary  *string            `json:"summary"`
+	Tags     []string           `json:"tags"`
+	Authors  []*ModelAuthor     `json:"authors"`
+	Scores   map[string]int64   `json:"scores"`
+	Editor   *ModelAuthor       `json:"editor"`
+	Internal string             `json:"internal"`
+	Extra    map[string]float64 `json:"extra"`
+}
+
+type ModelRow struct {
+	Title   sql.NullString `json:"title"`
+	Summary sql.NullString `json:"summary"`
+	Tags    string         `json:"tags"`
+	Editor  sql.NullInt64  `json:"editor"`
+}
+
+func TestValidateModel(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:               "models.v1",
+		GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/modelsv1",
+		ProtoRoot:          tmpDir,
+		ConverterOutputDir: filepath.Join(tmpDir, "converter"),
+	})
+
+	file := pkg.NewFile(sb.FileSchema{Name: "post"})
+
+	author := file.NewMessage(sb.MessageSchema{
+		Name:   "Author",
+		Fields: sb.FieldsMap{1: sb.String("email").Email()},
+	})
+
+	post := file.NewMessage(sb.MessageSchema{
+		Name: "Post",
+		Fields: sb.FieldsMap{
+			1: sb.String("title").MinLen(3),
+			2: sb.String("summary").MaxLen(5).Optional(),
+			3: sb.Repeated("tags", sb.String("").In("go", "proto")).MinItems(1),
+			4: sb.Repeated("authors", sb.MsgField("", author)),
+			5: sb.Map("scores", sb.String(""), sb.Int64("").Gt(0)),
+			6: sb.MsgField("editor", author).Required(),
+			7: sb.Timestamp("created_at").LtNow(),
+			8: sb.String("internal").MinLen(100),
+		},
+		ModelIgnore: []string{"internal"},
+	})
+
+	summary := "Too long"
+
+	violations := post.ValidateModel(&ModelPost{
+		ModelTimestamps: ModelTimestamps{CreatedAt: time.Now().Add(time.Hour)},
+		Title:           "Hi",
+		Summary:         &summary,
+		Tags:            []string{"go", "rust"},
+		Authors:         []*ModelAuthor{{Email: "me@example.com"}, {Email: "nope"}},
+		Scores:          map[string]int64{"a": 1, "b": 0},
+		Internal:        "ignored",
+	})
+
+	var paths []string
+	for _, v := range violations {
+		paths = append(paths, v.Field+" "+v.RuleId)
+	}
+
+	assert.ElementsMatch(t, []string{
+		"created_at timestamp.lt_now",
+		"title string.min_len",
+		"summary string.max_len",
+		"tags[1] string.in",
+		"authors[1].email string.email",
+		`scores["b"] int64.gt`,
+		"editor required",
+	}, paths)
+
+	for _, v := range violations {
+		if v.Field == "title" {
+			assert.Equal(t, "value length must be at least 3 characters", v.Message)
+			assert.Equal(t, "title", v.Proto.GetField().GetElements()[0].GetFieldName())
+		}
+	}
+
+	// Nil pointers are not set, so the optional fields are not validated
+	valid := post.ValidateModel(ModelPost{
+		ModelTimestamps: ModelTimestamps{CreatedAt: time.Now().Add(-time.Hour)},
+		Title:           "Hello",
+		Tags:            []string{"go"},
+		Editor:          &ModelAuthor{Email: "editor@example.com"},
+	})
+
+	assert.Empty(t, valid)
+
+	_, err := post.TryValidateModel("not a struct")
+	assert.ErrorContains(t, err, "Expected a struct, found string.")
+
+	// The values of the sql types are converted with their Value method, and the other values that do not match the type of their field are errors
+	rowPost := file.NewMessage(sb.MessageSchema{
+		Name: "RowPost",
+		Fields: sb.FieldsMap{
+			1: sb.String("title").MinLen(3),
+			2: sb.String("summary").MaxLen(5).Optional(),
+		},
+	})
+
+	rowViolations, err := rowPost.TryValidateModel(ModelRow{Title: sql.NullString{String: "Hi", Valid: true}})
+	if assert.NoError(t, err) && assert.Len(t, rowViolations, 1) {
+		assert.Equal(t, "title", rowViolations[0].Field)
+	}
+
+	_, err = post.TryValidateModel(ModelRow{
+		Title:  sql.NullString{String: "Hello", Valid: true},
+		Tags:   "go",
+		Editor: sql.NullInt64{Int64: 1, Valid: true},
+	})
+
+	assert.ErrorContains(t, err, `The value of "tags" (of type string) cannot be converted to a list of string.`)
+	assert.ErrorContains(t, err, `The value of "editor" (of type int64) cannot be converted to models.v1.Author.`)
+}
@@ -0,0 +1,169 @@
+package protoschema
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// The highest field number allowed by the protobuf spec (2^29 - 1).
+const maxFieldNumber = 1<<29 - 1
+
+// Checks that the numbers and names of the message's fields (including those inside oneofs) are valid, unique and not reserved.
+func checkMessageNumbers(data MessageData) error {
+	var err error
+
+	numbers := make(map[uint32]string)
+	names := make(map[string]string)
+
+	check := func(f FieldData, location string) {
+		switch {
+		case f.FieldNr == 0:
+			err = errors.Join(err, fmt.Errorf("Field %q%s has the number 0, which is not a valid field number.", f.Name, location))
+		case f.FieldNr > maxFieldNumber:
+			err = errors.Join(err, fmt.Errorf("Field %q%s has the number %d, which is higher than the maximum allowed (%d).", f.Name, location, f.FieldNr, maxFieldNumber))
+		case f.FieldNr >= firstReservedImplNumber && f.FieldNr <= lastReservedImplNumber:
+			err = errors.Join(err, fmt.Errorf("Field %q%s has the number %d, but the numbers from %d to %d are reserved for the protobuf implementation.", f.Name, location, f.FieldNr, firstReservedImplNumber, lastReservedImplNumber))
+		}
+
+		if existing, exists := numbers[f.FieldNr]; exists {
+			err = errors.Join(err, fmt.Errorf("Field %q%s uses the number %d, which is already used by %s.", f.Name, location, f.FieldNr, existing))
+		} else {
+			numbers[f.FieldNr] = fmt.Sprintf("%q%s", f.Name, location)
+		}
+
+		if existing, exists := names[f.Name]; exists {
+			err = errors.Join(err, fmt.Errorf("The name %q%s is already used by %s.", f.Name, location, existing))
+		} else {
+			names[f.Name] = fmt.Sprintf("the field with number %d%s", f.FieldNr, location)
+		}
+
+		if slices.Contains(data.ReservedNumbers, uint(f.FieldNr)) {
+			err = errors.Join(err, fmt.Errorf("Field %q%s uses the number %d, which is reserved.", f.Name, location, f.FieldNr))
+		}
+
+		if r, inRange := findRange(int64(f.FieldNr), data.ReservedRanges); inRange {
+			err = errors.Join(err, fmt.Errorf("Field %q%s uses the number %d, which is inside the reserved range from %d to %d.", f.Name, location, f.FieldNr, r[0], r[1]))
+		}
+
+		if slices.Contains(data.ReservedNames, f.Name) {
+			err = errors.Join(err, fmt.Errorf("Field %q%s uses a reserved name.", f.Name, location))
+		}
+
+		if r, inRange := findRange(int64(f.FieldNr), data.ExtensionRanges); inRange {
+			err = errors.Join(err, fmt.Errorf("Field %q%s uses the number %d, which is inside the extension range from %d to %d.", f.Name, location, f.FieldNr, r[0], r[1]))
+		}
+	}
+
+	for _, f := range data.Fields {
+		check(f, "")
+	}
+
+	for _, of := range data.Oneofs {
+		location := fmt.Sprintf(" (in oneof %q)", of.Name)
+
+		for _, f := range of.Fields {
+			check(f, location)
+		}
+	}
+
+	for _, of := range data.Oneofs {
+		if existing, exists := names[of.Name]; exists {
+			err = errors.Join(err, fmt.Errorf("The name of the oneof %q is already used by %s.", of.Name, existing))
+		} else {
+			names[of.Name] = fmt.Sprintf("the oneof %q", of.Name)
+		}
+	}
+
+	for _, r := range data.ReservedRanges {
+		if r[0] <= 0 || r[1] < r[0] || r[1] > maxFieldNumber {
+			err = errors.Join(err, fmt.Errorf("Invalid reserved range from %d to %d.", r[0], r[1]))
+		}
+	}
+
+	for _, r := range data.ExtensionRanges {
+		if r[0] <= 0 || r[1] < r[0] || r[1] > maxFieldNumber {
+			err = errors.Join(err, fmt.Errorf("Invalid extension range from %d to %d.", r[0], r[1]))
+		}
+
+		if reserved, overlaps := findOverlappingRange(r, data.ReservedRanges); overlaps {
+			err = errors.Join(err, fmt.Errorf("The extension range from %d to %d overlaps with the reserved range from %d to %d.", r[0], r[1], reserved[0], reserved[1]))
+		}
+	}
+
+	return err
+}
+
+// Checks that the numbers and names of the enum's values are unique and not reserved. Numbers can be shared by multiple values only if the enum allows aliases.
+func checkEnumNumbers(e EnumGroup) error {
+	var err error
+
+	names := make(map[string]int32)
+	numbers := make(map[int32]string)
+	allowAlias := e.allowsAlias()
+	hasAliases := false
+
+	for _, v := range e.Values {
+		if existing, exists := names[v.Name]; exists {
+			err = errors.Join(err, fmt.Errorf("Member %q is used for both %d and %d.", v.Name, existing, v.Number))
+		} else {
+			names[v.Name] = v.Number
+		}
+
+		if existing, exists := numbers[v.Number]; exists {
+			hasAliases = true
+
+			if !allowAlias {
+				err = errors.Join(err, fmt.Errorf("Member %q uses the number %d, which is already used by %q. To define aliases, the allow_alias option must be set.", v.Name, v.Number, existing))
+			}
+		} else {
+			numbers[v.Number] = v.Name
+		}
+
+		if slices.Contains(e.ReservedNumbers, v.Number) {
+			err = errors.Join(err, fmt.Errorf("Member %q uses the number %d, which is reserved.", v.Name, v.Number))
+		}
+
+		if r, inRange := findRange(int64(v.Number), e.ReservedRanges); inRange {
+			err = errors.Join(err, fmt.Errorf("Member %q uses the number %d, which is inside the reserved range from %d to %d.", v.Name, v.Number, r[0], r[1]))
+		}
+
+		if slices.Contains(e.ReservedNames, v.Name) {
+			err = errors.Join(err, fmt.Errorf("Member %q uses a reserved name.", v.Name))
+		}
+	}
+
+	if allowAlias && !hasAliases {
+		err = errors.Join(err, errors.New("The allow_alias option is set, but the enum has no aliases, which is rejected by protoc."))
+	}
+
+	for _, r := range e.ReservedRanges {
+		if r[1] < r[0] {
+			err = errors.Join(err, fmt.Errorf("Invalid reserved range from %d to %d.", r[0], r[1]))
+		}
+	}
+
+	return err
+}
+
+// Returns the first range that contains the given number, if there is one.
+func findRange(nr int64, ranges []Range) (Range, bool) {
+	for _, r := range ranges {
+		if nr >= int64(r[0]) && nr <= int64(r[1]) {
+			return r, true
+		}
+	}
+
+	return Range{}, false
+}
+
+// Returns the first range that overlaps with the given range, if there is one.
+func findOverlappingRange(target Range, ranges []Range) (Range, bool) {
+	for _, r := range ranges {
+		if target[0] <= r[1] && r[0] <= target[1] {
+			return r, true
+		}
+	}
+
+	return Range{}, false
+}
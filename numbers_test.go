@@ -0,0 +1,115 @@
+package protoschema_test
+
+import (
+	"testing"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumbersValidation(t *testing.T) {
+	newFile := func() *sb.FileSchema {
+		pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+			Name:      "numbers.v1",
+			GoPackage: "github.com/Rick-Phoenix/protoschema/gen/numbersv1",
+			ProtoRoot: t.TempDir(),
+		})
+
+		return pkg.NewFile(sb.FileSchema{Name: "numbers"})
+	}
+
+	messageTests := map[string]struct {
+		schema sb.MessageSchema
+		oneof  sb.OneofFields
+		errMsg string
+	}{
+		"oneof number collision": {
+			schema: sb.MessageSchema{Fields: sb.FieldsMap{1: sb.String("name")}},
+			oneof:  sb.OneofFields{1: sb.String("other")},
+			errMsg: `uses the number 1, which is already used by "name"`,
+		},
+		"oneof name collision": {
+			schema: sb.MessageSchema{Fields: sb.FieldsMap{1: sb.String("name")}},
+			oneof:  sb.OneofFields{2: sb.String("name")},
+			errMsg: `The name "name" (in oneof "choice") is already used`,
+		},
+		"reserved number": {
+			schema: sb.MessageSchema{Fields: sb.FieldsMap{3: sb.String("name")}, ReservedNumbers: []uint{3}},
+			errMsg: "which is reserved",
+		},
+		"reserved range": {
+			schema: sb.MessageSchema{Fields: sb.FieldsMap{3: sb.String("name")}, ReservedRanges: []sb.Range{{2, 4}}},
+			errMsg: "inside the reserved range from 2 to 4",
+		},
+		"reserved name": {
+			schema: sb.MessageSchema{Fields: sb.FieldsMap{1: sb.String("name")}, ReservedNames: []string{"name"}},
+			errMsg: "uses a reserved name",
+		},
+		"implementation range": {
+			schema: sb.MessageSchema{Fields: sb.FieldsMap{19500: sb.String("name")}},
+			errMsg: "reserved for the protobuf implementation",
+		},
+		"number too high": {
+			schema: sb.MessageSchema{Fields: sb.FieldsMap{1 << 29: sb.String("name")}},
+			errMsg: "higher than the maximum allowed",
+		},
+		"number zero": {
+			schema: sb.MessageSchema{Fields: sb.FieldsMap{0: sb.String("name")}},
+			errMsg: "not a valid field number",
+		},
+	}
+
+	for name, test := range messageTests {
+		file := newFile()
+		test.schema.Name = "Item"
+		msg := file.NewMessage(test.schema)
+		if test.oneof != nil {
+			msg.NewOneof(sb.OneofGroup{Name: "choice", Fields: test.oneof})
+		}
+
+		_, err := file.Package.TryBuildFiles()
+		if assert.Error(t, err, name) {
+			assert.Contains(t, err.Error(), test.errMsg, name)
+		}
+	}
+
+	enumTests := map[string]struct {
+		enum   sb.EnumGroup
+		errMsg string
+	}{
+		"duplicate name": {
+			enum:   sb.EnumGroup{Members: sb.EnumMembers{0: "A", 1: "A"}},
+			errMsg: `Member "A" is used for both 0 and 1`,
+		},
+		"reserved number": {
+			enum:   sb.EnumGroup{Members: sb.EnumMembers{0: "A", 1: "B"}, ReservedNumbers: []int32{1}},
+			errMsg: "which is reserved",
+		},
+		"reserved range": {
+			enum:   sb.EnumGroup{Members: sb.EnumMembers{0: "A", 5: "B"}, ReservedRanges: []sb.Range{{4, 6}}},
+			errMsg: "inside the reserved range from 4 to 6",
+		},
+		"reserved name": {
+			enum:   sb.EnumGroup{Members: sb.EnumMembers{0: "A"}, ReservedNames: []string{"A"}},
+			errMsg: "uses a reserved name",
+		},
+	}
+
+	for name, test := range enumTests {
+		file := newFile()
+		test.enum.Name = "Status"
+		file.NewEnum(test.enum)
+
+		_, err := file.Package.TryBuildFiles()
+		if assert.Error(t, err, name) {
+			assert.Contains(t, err.Error(), test.errMsg, name)
+		}
+	}
+
+	file := newFile()
+	msg := file.NewMessage(sb.MessageSchema{Name: "Valid", Fields: sb.FieldsMap{1: sb.String("name"), 20000: sb.String("high")}, ReservedRanges: []sb.Range{{2, 10}}})
+	msg.NewOneof(sb.OneofGroup{Name: "choice", Fields: sb.OneofFields{11: sb.String("a"), 12: sb.String("b")}})
+
+	_, err := file.Package.TryBuildFiles()
+	assert.NoError(t, err)
+}
@@ -17,13 +17,15 @@ type OneofHook func(OneofData) error
 
 // The processed data for the Oneof. Gets passed to the Hook after being generated.
 type OneofData struct {
-	Name     string
-	Fields   []FieldData
-	Options  []ProtoOption
-	Metadata map[string]any
-	Package  *ProtoPackage
-	File     *FileSchema
-	Message  *MessageSchema
+	Name            string
+	Fields          []FieldData
+	Options         []ProtoOption
+	Doc             string
+	TrailingComment string
+	Metadata        map[string]any
+	Package         *ProtoPackage
+	File            *FileSchema
+	Message         *MessageSchema
 }
 
 // The schema for a protobuf Oneof.This should be created with the constructor from a MessageSchema instance to automatically populate the Package, File and Message fields. It can also be used as a struct to define a Oneof that was not defined by using this library.
@@ -31,12 +33,18 @@ type OneofGroup struct {
 	Name     string
 	Required bool
 	Fields   OneofFields
-	Options  []ProtoOption
-	Package  *ProtoPackage
-	File     *FileSchema
-	Message  *MessageSchema
-	Metadata map[string]any
-	Hook     OneofHook
+	// An ordered list of fields that can be used instead of (or together with) the Fields map. Their numbers are assigned automatically (from the same pool as the parent message's fields) and recorded in the package's lockfile.
+	FieldsList FieldsList
+	Options    []ProtoOption
+	// A comment that is rendered above this oneof in the proto file, and that is used as its leading comment in the descriptor's SourceCodeInfo.
+	Doc string
+	// A comment that is rendered on the same line as the oneof's opening brace. Line breaks are replaced with spaces.
+	TrailingComment string
+	Package         *ProtoPackage
+	File            *FileSchema
+	Message         *MessageSchema
+	Metadata        map[string]any
+	Hook            OneofHook
 }
 
 // Returns a field with a specific name, causing a fatal error if the field is not found. Modifying this field will modify the original value.
@@ -46,18 +54,34 @@ func (of *OneofGroup) GetField(name string) FieldBuilder {
 			return v
 		}
 	}
+	for _, v := range of.FieldsList {
+		if v.GetName() == name {
+			return v
+		}
+	}
 	log.Fatalf("Could not find field %q in oneof %q", name, of.Name)
 	return nil
 }
 
-func (of *OneofGroup) build(imports Set) (OneofData, error) {
+func (of *OneofGroup) build(imports Set, autoNumbers map[string]uint32) (OneofData, error) {
 	choicesData := []FieldData{}
 	var fieldErr error
 
-	oneofKeys := slices.Sorted(maps.Keys(of.Fields))
+	fields := maps.Clone(of.Fields)
+	if fields == nil {
+		fields = make(OneofFields)
+	}
+
+	for _, f := range of.FieldsList {
+		if nr, ok := autoNumbers[f.GetName()]; ok {
+			fields[nr] = f
+		}
+	}
+
+	oneofKeys := slices.Sorted(maps.Keys(fields))
 
 	for _, number := range oneofKeys {
-		field := of.Fields[number]
+		field := fields[number]
 
 		data, err := field.Build(number, imports)
 		fieldErr = errors.Join(fieldErr, err)
@@ -83,7 +107,7 @@ func (of *OneofGroup) build(imports Set) (OneofData, error) {
 	if of.Required {
 		options = append(options, ProtoOption{
 			Name:  "(buf.validate.oneof).required",
-			Value: "true",
+			Value: true,
 		})
 	}
 
@@ -92,13 +116,15 @@ func (of *OneofGroup) build(imports Set) (OneofData, error) {
 	}
 
 	out := OneofData{
-		Name:     of.Name,
-		Options:  options,
-		Fields:   choicesData,
-		Metadata: of.Metadata,
-		Package:  of.Package,
-		Message:  of.Message,
-		File:     of.File,
+		Name:            of.Name,
+		Options:         options,
+		Doc:             of.Doc,
+		TrailingComment: of.TrailingComment,
+		Fields:          choicesData,
+		Metadata:        of.Metadata,
+		Package:         of.Package,
+		Message:         of.Message,
+		File:            of.File,
 	}
 
 	if of.Hook != nil {
@@ -38,6 +38,27 @@ type ProtoPackageConfig struct {
 	// If defined, this function will receive a rich set of data for each message field to define its own logic for generating files or performing custom actions.
 	// It can also be overridden for a single message.
 	ConverterFunc ConverterFunc
+	// (Default: "<ProtoRoot>/protoschema.lock.json") The path to the lockfile where the numbers that are automatically assigned to the fields in a FieldsList (and to the members in an enum's MembersList) are recorded.
+	LockFile string
+	// The path to the file where a snapshot of the package's schema is stored after each generation. If defined, every new generation is compared with the stored snapshot, and the generation is stopped if any breaking change with the error severity is detected.
+	// Each package should use its own snapshot file.
+	SnapshotFile string
+	// The severities for the rules used to detect breaking changes. Rules that are not defined here use the severity in DefaultBreakingPolicy.
+	BreakingPolicy BreakingPolicy
+	// The configuration for the linter, which runs on the processed schemas before the files are generated. Lint issues are printed as warnings unless Strict is true.
+	Lint LintConfig
+	// The path where the descriptors of the package's files are written (as a binary FileDescriptorSet, including the SourceCodeInfo) after each generation. If undefined, no descriptor set is written.
+	DescriptorSetFile string
+	// The language-specific options (such as go_package or java_package) for the files of this package. The go_package option is always emitted (unless disabled), using GoPackage as its default value.
+	LanguageOptions LanguageOptions
+	// The configuration for the buf.yaml and buf.gen.yaml files, which can be generated in the ProtoRoot. Their dependencies are detected from the imports in the files, and their lint and breaking settings match the Lint and BreakingPolicy of the package.
+	Buf BufConfig
+	// (Default: "<ProtoRoot>/protoschema.manifest.json") The path to the manifest where the hashes of the generated files are recorded. It is used to skip the files that have not changed and to remove the files that are no longer generated.
+	ManifestFile string
+	// If true, the existing files at the output paths are overwritten even if they do not contain the GeneratedHeader. This is meant for the first generation after upgrading from a version of protoschema that did not add the header, and it should be disabled again afterwards, since it also overwrites the files owned by the user.
+	OverwriteUnmarkedFiles bool
+	// If true, a "<converter package>_rules_test.go" file is generated next to the converter, with a test for each message with rules that checks (with protovalidate) that a valid instance of its generated type is accepted, and that an invalid instance for each rule is rejected with the expected rule id. The tests import the go types generated by protoc-gen-go and buf.build/go/protovalidate, which is not a dependency of this module, so the go module of the converter must depend on it.
+	ConformanceTests bool
 }
 
 // The ProtoPackage struct, which holds the data and methods for file generation (if created with the constructor). Can also be used without the constructor to define the package data for imported message types.
@@ -63,6 +84,21 @@ type ProtoPackage struct {
 	fileSchemas        []*FileSchema
 	converter          converterData
 	converterFunc      ConverterFunc
+	lockFilePath       string
+	lock               *packageLock
+	snapshotFilePath   string
+	breakingPolicy     BreakingPolicy
+	lintConfig         LintConfig
+	descriptorSetPath  string
+	languageOptions    LanguageOptions
+	workspace          *Workspace
+	bufConfig          BufConfig
+	manifestFilePath   string
+	prevOutputs        map[string]manifestEntry
+	outputs            map[string]manifestEntry
+	overwriteUnmarked  bool
+	conformanceTests   bool
+	compiled           *compiledPackage
 }
 
 // Returns the name of the package, defaulting to an empty string if the pointer is nil.
@@ -113,6 +149,15 @@ func (p *ProtoPackage) GetGoPackagePath() string {
 	return p.GoPackagePath
 }
 
+// Returns the root directory of the proto files, where the buf files are generated.
+func (p *ProtoPackage) GetProtoRoot() string {
+	if p == nil {
+		return ""
+	}
+
+	return p.protoRoot
+}
+
 // The constructor for a ProtoPackage instance.
 func NewProtoPackage(conf ProtoPackageConfig) *ProtoPackage {
 	p := &ProtoPackage{
@@ -127,6 +172,16 @@ func NewProtoPackage(conf ProtoPackageConfig) *ProtoPackage {
 		messageHook:        conf.MessageHook,
 		oneofHook:          conf.OneofHook,
 		converterFunc:      conf.ConverterFunc,
+		lockFilePath:       conf.LockFile,
+		snapshotFilePath:   conf.SnapshotFile,
+		breakingPolicy:     conf.BreakingPolicy,
+		lintConfig:         conf.Lint,
+		descriptorSetPath:  conf.DescriptorSetFile,
+		languageOptions:    conf.LanguageOptions,
+		bufConfig:          conf.Buf,
+		manifestFilePath:   conf.ManifestFile,
+		overwriteUnmarked:  conf.OverwriteUnmarkedFiles,
+		conformanceTests:   conf.ConformanceTests,
 	}
 
 	if conf.Name == "" {
@@ -166,15 +221,23 @@ func NewProtoPackage(conf ProtoPackageConfig) *ProtoPackage {
 // Adds a file to the package and returns a pointer to it.
 func (p *ProtoPackage) NewFile(s FileSchema) *FileSchema {
 	newFile := &FileSchema{
-		Name:       s.Name + ".proto",
-		Package:    p,
-		Imports:    make(Set),
-		Options:    s.Options,
-		Extensions: s.Extensions,
-		enums:      s.enums,
-		messages:   s.messages,
-		services:   s.services,
-		Hook:       s.Hook,
+		Name:            s.Name + ".proto",
+		Package:         p,
+		Imports:         make(Set),
+		Options:         s.Options,
+		Extensions:      s.Extensions,
+		Syntax:          s.Syntax,
+		Edition:         s.Edition,
+		Features:        s.Features,
+		Doc:             s.Doc,
+		TrailingComment: s.TrailingComment,
+		LanguageOptions: s.LanguageOptions,
+		Extends:         s.Extends,
+		enums:           s.enums,
+		messages:        s.messages,
+		services:        s.services,
+		Hook:            s.Hook,
+		Metadata:        s.Metadata,
 	}
 	maps.Copy(newFile.Imports, s.Imports)
 	if s.Hook == nil {
@@ -186,10 +249,28 @@ func (p *ProtoPackage) NewFile(s FileSchema) *FileSchema {
 
 // Processes all the files' data and returns it. This is called automatically when .Generate() is called.
 // In most cases it's better to use the FileHook to perform custom actions on the data, but this can also be used to collect all the processed data and use it directly.
+// If any errors occur, they are printed and the program exits. To handle the errors directly, use TryBuildFiles.
 func (p *ProtoPackage) BuildFiles() []FileData {
+	out, err := p.TryBuildFiles()
+
+	if err != nil {
+		fmt.Printf("  ❌ The following errors occurred:\n")
+		fmt.Print(err.Error())
+		os.Exit(1)
+	}
+
+	return out
+}
+
+// Processes all the files' data and returns it, along with all the errors that occurred while processing the schemas.
+func (p *ProtoPackage) TryBuildFiles() ([]FileData, error) {
 	out := []FileData{}
 	var fileErrors error
 
+	if err := p.loadLock(); err != nil {
+		fileErrors = errors.Join(fileErrors, err)
+	}
+
 	for _, f := range p.fileSchemas {
 		file, err := f.build()
 		if err != nil {
@@ -200,11 +281,5 @@ func (p *ProtoPackage) BuildFiles() []FileData {
 
 	}
 
-	if fileErrors != nil {
-		fmt.Printf("  ❌ The following errors occurred:\n")
-		fmt.Print(fileErrors.Error())
-		os.Exit(1)
-	}
-
-	return out
+	return out, fileErrors
 }
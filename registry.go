@@ -0,0 +1,40 @@
+package protoschema
+
+import "slices"
+
+var registry struct {
+	packages   []*ProtoPackage
+	workspaces []*Workspace
+}
+
+// Registers a package, so that it can be used by the protoschema command. It should be called in an init function of the go package where the schemas are defined.
+func Register(p *ProtoPackage) {
+	if p != nil && !slices.Contains(registry.packages, p) {
+		registry.packages = append(registry.packages, p)
+	}
+}
+
+// Registers a workspace, so that it can be used by the protoschema command. Its packages are always processed and generated together.
+func RegisterWorkspace(w *Workspace) {
+	if w != nil && !slices.Contains(registry.workspaces, w) {
+		registry.workspaces = append(registry.workspaces, w)
+	}
+}
+
+// Returns the registered packages which do not belong to a registered workspace, in the order in which they were registered.
+func RegisteredPackages() []*ProtoPackage {
+	var out []*ProtoPackage
+
+	for _, p := range registry.packages {
+		if p.workspace == nil || !slices.Contains(registry.workspaces, p.workspace) {
+			out = append(out, p)
+		}
+	}
+
+	return out
+}
+
+// Returns the registered workspaces, in the order in which they were registered.
+func RegisteredWorkspaces() []*Workspace {
+	return slices.Clone(registry.workspaces)
+}
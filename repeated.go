@@ -55,12 +55,20 @@ func (b *RepeatedField) Build(fieldNr uint32, imports Set) (FieldData, error) {
 		err = errors.Join(err, fmt.Errorf("Cannot nest repeated fields inside one another (must be wrapped inside a message type first)"))
 	}
 
+	if fieldData.Default != nil {
+		err = errors.Join(err, fmt.Errorf("Repeated fields cannot have a default value."))
+	}
+
+	if fieldData.LabelRequired {
+		err = errors.Join(err, fmt.Errorf("Repeated fields cannot have the required label."))
+	}
+
 	if fieldData.Required {
 		fmt.Printf("Ignoring ineffective 'required' option for repeated field '%s' (you can set min_len to 1 instead to require at least one element)", b.name)
 	}
 
-	options := make([]string, len(b.repeatedOptions))
-	copy(options, b.repeatedOptions)
+	options, celErr := b.buildRepeatedOptions()
+	err = errors.Join(err, celErr)
 
 	if len(fieldData.Rules) > 0 {
 		rulesMap := make(map[string]any)
@@ -82,11 +90,14 @@ func (b *RepeatedField) Build(fieldNr uint32, imports Set) (FieldData, error) {
 		err = errors.Join(err, optErr)
 	}
 
+	data := FieldData{Name: b.name, ProtoType: fieldData.ProtoType, GoType: b.goType, Optional: fieldData.Optional, FieldNr: fieldNr, Repeated: true, Options: options, IsNonScalar: true, MessageRef: fieldData.MessageRef, EnumRef: fieldData.EnumRef, Features: b.features, ExtensionOptions: extensionOptions(b.options), Doc: b.doc, TrailingComment: b.trailingComment}
+
+	err = errors.Join(err, checkFieldFeatureTypes(data))
 	if err != nil {
 		return FieldData{}, err
 	}
 
-	return FieldData{Name: b.name, ProtoType: fieldData.ProtoType, GoType: b.goType, Optional: fieldData.Optional, FieldNr: fieldNr, Repeated: true, Options: options, IsNonScalar: true, MessageRef: fieldData.MessageRef}, nil
+	return data, nil
 }
 
 // Rule: this repeated field must contain unique values. Causes an error if the fields are non-scalar.
@@ -0,0 +1,181 @@
+package protoschema
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// The files of a package compiled in memory, with a registry that contains their descriptors and those of their imports, which is used to create and parse dynamic messages.
+type compiledPackage struct {
+	sources  map[string]string
+	paths    []string
+	registry *protoregistry.Files
+}
+
+// Returns the compiled files of this package, which are used to create the dynamic messages.
+// The files are processed on each call, so the changes to the schemas are always included, but they are only compiled again if their rendered content (or that of the other files in the workspace) changed since the last call.
+func (p *ProtoPackage) compiledFiles() (*compiledPackage, error) {
+	if p.tmpl == nil {
+		return nil, fmt.Errorf("The package %q was not created with NewProtoPackage, so its files cannot be compiled.", p.GetName())
+	}
+
+	filesData, err := p.TryBuildFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	sources, paths, err := p.renderSources(filesData)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.compiled != nil && maps.Equal(p.compiled.sources, sources) && slices.Equal(p.compiled.paths, paths) {
+		return p.compiled, nil
+	}
+
+	compiled, err := p.compileSources(sources, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	files := new(protoregistry.Files)
+
+	var register func(f protoreflect.FileDescriptor) error
+	register = func(f protoreflect.FileDescriptor) error {
+		if _, err := files.FindFileByPath(f.Path()); err == nil {
+			return nil
+		}
+
+		imports := f.Imports()
+		for i := range imports.Len() {
+			if err := register(imports.Get(i).FileDescriptor); err != nil {
+				return err
+			}
+		}
+
+		return files.RegisterFile(f)
+	}
+
+	for _, f := range compiled {
+		if err := register(f); err != nil {
+			return nil, fmt.Errorf("Failed to register the file %q: %w", f.Path(), err)
+		}
+	}
+
+	p.compiled = &compiledPackage{sources: sources, paths: paths, registry: files}
+
+	return p.compiled, nil
+}
+
+// Returns the descriptor of this message, along with the resolver for the types that it can reference.
+// The messages of the well-known types (and of other packages registered in the global registry) are resolved from the global registry, while the messages of a package created with NewProtoPackage are compiled in memory (including all the other files of the package).
+func (m *MessageSchema) runtimeDescriptor() (protoreflect.MessageDescriptor, *dynamicpb.Types, error) {
+	if m == nil || m.Package == nil {
+		return nil, nil, fmt.Errorf("The message schema must belong to a package.")
+	}
+
+	fullName := protoreflect.FullName(m.Package.GetName() + "." + m.GetName())
+
+	files := protoregistry.GlobalFiles
+
+	if m.Package.tmpl != nil {
+		compiled, err := m.Package.compiledFiles()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		files = compiled.registry
+	}
+
+	desc, err := files.FindDescriptorByName(fullName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Could not find the descriptor for the message %q: %w", fullName, err)
+	}
+
+	msgDesc, isMessage := desc.(protoreflect.MessageDescriptor)
+	if !isMessage {
+		return nil, nil, fmt.Errorf("The descriptor for %q is not a message.", fullName)
+	}
+
+	return msgDesc, dynamicpb.NewTypes(files), nil
+}
+
+// Returns the descriptor of this message. The descriptor can be used to create any number of dynamic messages (with dynamicpb.NewMessage) without compiling the package again.
+func (m *MessageSchema) Descriptor() (protoreflect.MessageDescriptor, error) {
+	desc, _, err := m.runtimeDescriptor()
+	return desc, err
+}
+
+// Creates an empty dynamic message for this schema, causing a fatal error if the package's files cannot be compiled. Use TryNew to handle the error instead.
+// Dynamic messages can be used in tests and tools before the go types are generated, and they can be validated natively with Validate.
+func (m *MessageSchema) New() *dynamicpb.Message {
+	msg, err := m.TryNew()
+	if err != nil {
+		log.Fatalf("Could not create the dynamic message for %q: %v", m.GetName(), err)
+	}
+
+	return msg
+}
+
+// Creates an empty dynamic message for this schema. The compiled files of the package are reused by the following calls until the schemas change, but Descriptor can be used to create many messages without processing the files again.
+func (m *MessageSchema) TryNew() (*dynamicpb.Message, error) {
+	desc, err := m.Descriptor()
+	if err != nil {
+		return nil, err
+	}
+
+	return dynamicpb.NewMessage(desc), nil
+}
+
+// Parses a message in the protobuf JSON format into a dynamic message for this schema. The messages in Any fields are resolved from the files of the package.
+func (m *MessageSchema) FromJSON(data []byte) (*dynamicpb.Message, error) {
+	desc, types, err := m.runtimeDescriptor()
+	if err != nil {
+		return nil, err
+	}
+
+	msg := dynamicpb.NewMessage(desc)
+	if err := (protojson.UnmarshalOptions{Resolver: types}).Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("Failed to parse the JSON message for %q: %w", m.GetName(), err)
+	}
+
+	return msg, nil
+}
+
+// Parses a message in the protobuf binary format into a dynamic message for this schema.
+func (m *MessageSchema) FromBinary(data []byte) (*dynamicpb.Message, error) {
+	desc, types, err := m.runtimeDescriptor()
+	if err != nil {
+		return nil, err
+	}
+
+	msg := dynamicpb.NewMessage(desc)
+	if err := (proto.UnmarshalOptions{Resolver: types}).Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("Failed to parse the binary message for %q: %w", m.GetName(), err)
+	}
+
+	return msg, nil
+}
+
+// Serializes a message (such as a dynamic message for this schema) in the protobuf JSON format. The messages in Any fields are resolved from the files of the package.
+func (m *MessageSchema) ToJSON(msg proto.Message) ([]byte, error) {
+	_, types, err := m.runtimeDescriptor()
+	if err != nil {
+		return nil, err
+	}
+
+	return protojson.MarshalOptions{Resolver: types}.Marshal(msg)
+}
+
+// Serializes a message (such as a dynamic message for this schema) in the protobuf binary format, with a deterministic order for the entries of maps.
+func (m *MessageSchema) ToBinary(msg proto.Message) ([]byte, error) {
+	return proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+}
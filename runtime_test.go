@@ -0,0 +1,184 @@
+package protoschema_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestRuntime(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:               "runtime.v1",
+		GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/runtimev1",
+		ProtoRoot:          tmpDir,
+		ConverterOutputDir: filepath.Join(tmpDir, "converter"),
+	})
+
+	file := pkg.NewFile(sb.FileSchema{Name: "post"})
+
+	author := file.NewMessage(sb.MessageSchema{
+		Name:   "Author",
+		Fields: sb.FieldsMap{1: sb.String("email").Email()},
+	})
+
+	post := file.NewMessage(sb.MessageSchema{
+		Name: "Post",
+		Fields: sb.FieldsMap{
+			1: sb.String("title").MinLen(3).MaxLen(20),
+			2: sb.Int32("views").Gte(0).Lt(1000),
+			3: sb.Repeated("tags", sb.String("").In("go", "proto")).MaxItems(2),
+			4: sb.Map("scores", sb.String("").MinLen(2), sb.Int64("").Gt(0)),
+			5: sb.MsgField("author", author).Required(),
+		},
+	})
+
+	gallery := file.NewMessage(sb.MessageSchema{
+		Name:   "Gallery",
+		Fields: sb.FieldsMap{1: sb.Repeated("images", sb.String("")).MinItems(1), 2: sb.Map("captions", sb.String(""), sb.String("")).MinPairs(1)},
+	})
+
+	msg := post.New()
+	fields := msg.Descriptor().Fields()
+
+	msg.Set(fields.ByName("title"), protoreflect.ValueOfString("Hi"))
+	msg.Set(fields.ByName("views"), protoreflect.ValueOfInt32(1000))
+
+	tags := msg.Mutable(fields.ByName("tags")).List()
+	for _, tag := range []string{"go", "rust", "proto"} {
+		tags.Append(protoreflect.ValueOfString(tag))
+	}
+
+	scores := msg.Mutable(fields.ByName("scores")).Map()
+	scores.Set(protoreflect.ValueOfString("a").MapKey(), protoreflect.ValueOfInt64(0))
+
+	err := sb.Validate(msg)
+
+	var validationErr *sb.ValidationError
+	if !assert.True(t, errors.As(err, &validationErr)) {
+		return
+	}
+
+	var violations []string
+	for _, v := range validationErr.Violations {
+		violations = append(violations, sb.FieldPathString(v.GetField())+" "+v.GetRuleId())
+	}
+
+	// The key and the value of the map entry are both invalid
+	assert.ElementsMatch(t, []string{
+		"title string.min_len",
+		"views int32.gte_lt",
+		"tags repeated.max_items",
+		"tags[1] string.in",
+		`scores["a"] string.min_len`,
+		`scores["a"] int64.gt`,
+		"author required",
+	}, violations)
+
+	assert.Len(t, validationErr.ToProto().GetViolations(), 7)
+	assert.Contains(t, err.Error(), "title: value length must be at least 3 characters [string.min_len]")
+
+	// Fixing the values removes the violations, including those of the nested messages
+	msg.Set(fields.ByName("title"), protoreflect.ValueOfString("Hello"))
+	msg.Set(fields.ByName("views"), protoreflect.ValueOfInt32(10))
+	tags.Truncate(1)
+	scores.Clear(protoreflect.ValueOfString("a").MapKey())
+	scores.Set(protoreflect.ValueOfString("ab").MapKey(), protoreflect.ValueOfInt64(3))
+
+	authorMsg := msg.Mutable(fields.ByName("author")).Message()
+	authorMsg.Set(authorMsg.Descriptor().Fields().ByName("email"), protoreflect.ValueOfString("not-an-email"))
+
+	err = sb.Validate(msg)
+	if assert.True(t, errors.As(err, &validationErr)) && assert.Len(t, validationErr.Violations, 1) {
+		assert.Equal(t, "author.email", sb.FieldPathString(validationErr.Violations[0].GetField()))
+		assert.Equal(t, "string.email", validationErr.Violations[0].GetRuleId())
+	}
+
+	authorMsg.Set(authorMsg.Descriptor().Fields().ByName("email"), protoreflect.ValueOfString("me@example.com"))
+	assert.NoError(t, sb.Validate(msg))
+
+	// The dynamic messages can be serialized and parsed
+	jsonData, err := post.ToJSON(msg)
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(jsonData), `"title":"Hello"`)
+
+		parsed, err := post.FromJSON(jsonData)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "Hello", parsed.Get(parsed.Descriptor().Fields().ByName("title")).String())
+		}
+	}
+
+	binaryData, err := post.ToBinary(msg)
+	if assert.NoError(t, err) {
+		parsed, err := post.FromBinary(binaryData)
+		if assert.NoError(t, err) {
+			assert.Equal(t, int64(10), parsed.Get(parsed.Descriptor().Fields().ByName("views")).Int())
+			assert.NoError(t, sb.Validate(parsed))
+		}
+	}
+
+	_, err = post.FromJSON([]byte(`{"unknown": 1}`))
+	assert.ErrorContains(t, err, "Failed to parse the JSON message")
+
+	// The rules of empty repeated and map fields are evaluated, as in protovalidate
+	err = sb.Validate(gallery.New())
+	if assert.True(t, errors.As(err, &validationErr)) && assert.Len(t, validationErr.Violations, 2) {
+		assert.Equal(t, "repeated.min_items", validationErr.Violations[0].GetRuleId())
+		assert.Equal(t, "map.min_pairs", validationErr.Violations[1].GetRuleId())
+	}
+}
+
+type runtimeNote struct {
+	Id    int64
+	Title string
+}
+
+func TestRuntimeCache(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:               "runtime.v1",
+		GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/runtimev1",
+		ProtoRoot:          tmpDir,
+		ConverterOutputDir: filepath.Join(tmpDir, "converter"),
+	})
+
+	file := pkg.NewFile(sb.FileSchema{Name: "note"})
+
+	note := file.NewMessage(sb.MessageSchema{
+		Name:   "Note",
+		Fields: sb.FieldsMap{1: sb.Int64("id"), 2: sb.String("title")},
+		Model:  &runtimeNote{},
+	})
+
+	// The compiled files are reused while the schemas do not change
+	first, err := note.Descriptor()
+	assert.NoError(t, err)
+
+	second, err := note.Descriptor()
+	assert.NoError(t, err)
+	assert.True(t, first == second, "The descriptor should be reused when the schemas do not change")
+
+	assert.NoError(t, pkg.Generate())
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "converter/converter.go"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, strings.Count(string(content), "func NoteToNoteMsg("), "The converter should not be duplicated when the files are built more than once")
+	}
+
+	// The schemas changed after the first use are included without processing the files manually
+	file.NewMessage(sb.MessageSchema{Name: "Tag", Fields: sb.FieldsMap{1: sb.String("name")}})
+
+	third, err := note.Descriptor()
+	if assert.NoError(t, err) {
+		assert.False(t, first == third)
+		assert.NotNil(t, third.ParentFile().Messages().ByName("Tag"))
+	}
+}
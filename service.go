@@ -12,11 +12,13 @@ import (
 type ServiceHook func(s ServiceData)
 
 type HandlerData struct {
-	Name     string
-	Request  *MessageSchema
-	Response *MessageSchema
-	Query    *db.QueryData
-	Metadata map[string]any
+	Name            string
+	Doc             string
+	TrailingComment string
+	Request         *MessageSchema
+	Response        *MessageSchema
+	Query           *db.QueryData
+	Metadata        map[string]any
 }
 
 // Maps handlers to their names.
@@ -28,16 +30,22 @@ type Handler struct {
 	Response *MessageSchema
 	Query    *db.QueryData
 	Metadata map[string]any
+	// A comment that is rendered above this rpc in the proto file, and that is used as its leading comment in the descriptor's SourceCodeInfo.
+	Doc string
+	// A comment that is rendered on the same line as the rpc. Line breaks are replaced with spaces.
+	TrailingComment string
 }
 
 // The output struct of the schema after it has been processed. Gets passed as an argument to the ServiceHook.
 type ServiceData struct {
-	Resource string
-	File     *FileSchema
-	Package  *ProtoPackage
-	Options  []ProtoOption
-	Handlers []*HandlerData
-	Metadata map[string]any
+	Resource        string
+	File            *FileSchema
+	Package         *ProtoPackage
+	Options         []ProtoOption
+	Doc             string
+	TrailingComment string
+	Handlers        []*HandlerData
+	Metadata        map[string]any
 }
 
 // The schema for a proto service. It should be created with the constructor from a FileSchema instance, as that populates the File and Package fields automatically.
@@ -51,6 +59,10 @@ type ServiceSchema struct {
 	// A map of handlers. These correspond to the "rpc" directives in a proto file.
 	Handlers HandlersMap
 	Options  []ProtoOption
+	// A comment that is rendered above this service in the proto file, and that is used as its leading comment in the descriptor's SourceCodeInfo.
+	Doc string
+	// A comment that is rendered on the same line as the service's opening brace. Line breaks are replaced with spaces.
+	TrailingComment string
 	// Schema-specific ServiceHook. If this is unset, and the service was created with the constructor, it defaults to the package-level ServiceHook. Otherwise, it overrides it.
 	Hook ServiceHook
 	// A map to store custom metadata to use in the hook. This gets passed directly to ServiceData instance.
@@ -68,7 +80,7 @@ func (s *ServiceSchema) GetGoPackagePath() string {
 
 func (f *ServiceSchema) build(imports Set) ServiceData {
 	out := ServiceData{
-		Resource: f.Resource, Options: f.Options, Metadata: f.Metadata,
+		Resource: f.Resource, Options: f.Options, Metadata: f.Metadata, Doc: f.Doc, TrailingComment: f.TrailingComment,
 	}
 
 	handlerKeys := slices.SortedFunc(maps.Keys(f.Handlers), func(a, b string) int {
@@ -105,11 +117,13 @@ func (f *ServiceSchema) build(imports Set) ServiceData {
 		h := f.Handlers[name]
 
 		handlerData := HandlerData{
-			Name:     name,
-			Request:  h.Request,
-			Response: h.Response,
-			Query:    h.Query,
-			Metadata: h.Metadata,
+			Name:            name,
+			Request:         h.Request,
+			Response:        h.Response,
+			Query:           h.Query,
+			Metadata:        h.Metadata,
+			Doc:             h.Doc,
+			TrailingComment: h.TrailingComment,
 		}
 
 		for _, v := range []*MessageSchema{h.Request, h.Response} {
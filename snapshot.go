@@ -0,0 +1,290 @@
+package protoschema
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// The current version of the snapshot format.
+const snapshotVersion = 1
+
+// A serializable representation of the schemas of one or more proto packages, used to detect breaking changes between builds.
+type SchemaSnapshot struct {
+	Version int            `json:"version"`
+	Files   []FileSnapshot `json:"files"`
+}
+
+// The snapshot of a single proto file.
+type FileSnapshot struct {
+	Name     string            `json:"name"`
+	Package  string            `json:"package"`
+	Messages []MessageSnapshot `json:"messages,omitempty"`
+	Enums    []EnumSnapshot    `json:"enums,omitempty"`
+	Services []ServiceSnapshot `json:"services,omitempty"`
+}
+
+// The snapshot of a message. Nested messages are listed as separate messages, and their names include the names of their parent messages (i.e. "User.Address").
+type MessageSnapshot struct {
+	Name            string          `json:"name"`
+	Fields          []FieldSnapshot `json:"fields,omitempty"`
+	ReservedNumbers []uint32        `json:"reserved_numbers,omitempty"`
+	ReservedRanges  []Range         `json:"reserved_ranges,omitempty"`
+	ReservedNames   []string        `json:"reserved_names,omitempty"`
+}
+
+// The snapshot of a message field.
+type FieldSnapshot struct {
+	Name   string `json:"name"`
+	Number uint32 `json:"number"`
+	// The fully qualified type of the field (i.e. "string", "myapp.v1.Post", "map<string, int64>").
+	Type string `json:"type"`
+	// One of "optional", "repeated", "map" or an empty string for singular fields without explicit presence.
+	Cardinality string `json:"cardinality,omitempty"`
+	// The name of the oneof group that contains this field, if there is one.
+	Oneof string `json:"oneof,omitempty"`
+}
+
+// The snapshot of an enum. Enums defined within a message have the names of their parent messages as a prefix (i.e. "User.Status").
+type EnumSnapshot struct {
+	Name            string              `json:"name"`
+	Values          []EnumValueSnapshot `json:"values,omitempty"`
+	ReservedNumbers []int32             `json:"reserved_numbers,omitempty"`
+	ReservedRanges  []Range             `json:"reserved_ranges,omitempty"`
+	ReservedNames   []string            `json:"reserved_names,omitempty"`
+}
+
+// The snapshot of an enum value.
+type EnumValueSnapshot struct {
+	Name   string `json:"name"`
+	Number int32  `json:"number"`
+}
+
+// The snapshot of a service.
+type ServiceSnapshot struct {
+	Name    string           `json:"name"`
+	Methods []MethodSnapshot `json:"methods,omitempty"`
+}
+
+// The snapshot of an rpc method. The request and response types are fully qualified.
+type MethodSnapshot struct {
+	Name     string `json:"name"`
+	Request  string `json:"request"`
+	Response string `json:"response"`
+}
+
+// Creates a snapshot from the processed data of a package's files.
+func NewSnapshot(files []FileData) *SchemaSnapshot {
+	out := &SchemaSnapshot{Version: snapshotVersion}
+
+	for _, f := range files {
+		file := FileSnapshot{Name: path.Join(f.Package.GetBasePath(), f.Name), Package: f.Package.GetName()}
+
+		for _, e := range f.Enums {
+			file.Enums = append(file.Enums, newEnumSnapshot(e, ""))
+		}
+
+		for _, m := range f.Messages {
+			messages, enums := newMessageSnapshots(m, "")
+			file.Messages = append(file.Messages, messages...)
+			file.Enums = append(file.Enums, enums...)
+		}
+
+		for _, s := range f.Services {
+			service := ServiceSnapshot{Name: addServiceSuffix(s.Resource)}
+
+			for _, h := range s.Handlers {
+				service.Methods = append(service.Methods, MethodSnapshot{
+					Name: h.Name, Request: h.Request.GetFullName(nil), Response: h.Response.GetFullName(nil),
+				})
+			}
+
+			file.Services = append(file.Services, service)
+		}
+
+		out.Files = append(out.Files, file)
+	}
+
+	return out
+}
+
+func newEnumSnapshot(e EnumGroup, prefix string) EnumSnapshot {
+	out := EnumSnapshot{
+		Name: prefix + e.Name, ReservedNumbers: e.ReservedNumbers, ReservedRanges: e.ReservedRanges, ReservedNames: e.ReservedNames,
+	}
+
+	for _, v := range e.Values {
+		out.Values = append(out.Values, EnumValueSnapshot{Name: v.Name, Number: v.Number})
+	}
+
+	return out
+}
+
+func newMessageSnapshots(m MessageData, prefix string) ([]MessageSnapshot, []EnumSnapshot) {
+	name := prefix + m.Name
+	msg := MessageSnapshot{Name: name, ReservedRanges: m.ReservedRanges, ReservedNames: m.ReservedNames}
+
+	for _, nr := range m.ReservedNumbers {
+		msg.ReservedNumbers = append(msg.ReservedNumbers, uint32(nr))
+	}
+
+	for _, f := range m.Fields {
+		msg.Fields = append(msg.Fields, newFieldSnapshot(f, ""))
+	}
+
+	for _, of := range m.Oneofs {
+		for _, f := range of.Fields {
+			msg.Fields = append(msg.Fields, newFieldSnapshot(f, of.Name))
+		}
+	}
+
+	slices.SortFunc(msg.Fields, func(a, b FieldSnapshot) int {
+		return cmp.Compare(a.Number, b.Number)
+	})
+
+	messages := []MessageSnapshot{msg}
+	enums := []EnumSnapshot{}
+
+	for _, e := range m.Enums {
+		enums = append(enums, newEnumSnapshot(e, name+"."))
+	}
+
+	for _, nested := range m.Messages {
+		nestedMessages, nestedEnums := newMessageSnapshots(nested, name+".")
+		messages = append(messages, nestedMessages...)
+		enums = append(enums, nestedEnums...)
+	}
+
+	return messages, enums
+}
+
+func newFieldSnapshot(f FieldData, oneof string) FieldSnapshot {
+	out := FieldSnapshot{Name: f.Name, Number: f.FieldNr, Type: f.ProtoType, Oneof: oneof}
+
+	if f.MessageRef != nil && f.MessageRef.Name != "" && !f.IsMap {
+		out.Type = f.MessageRef.GetFullName(nil)
+	} else if f.EnumRef != nil && !f.IsMap {
+		out.Type = f.EnumRef.GetFullName(nil)
+	}
+
+	switch {
+	case f.IsMap:
+		out.Cardinality = "map"
+	case f.Repeated:
+		out.Cardinality = "repeated"
+	case f.Optional:
+		out.Cardinality = "optional"
+	}
+
+	return out
+}
+
+// Processes the files of this package and returns a snapshot of their schemas, along with the errors that occurred while processing them.
+func (p *ProtoPackage) Snapshot() (*SchemaSnapshot, error) {
+	filesData, err := p.TryBuildFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSnapshot(filesData), nil
+}
+
+// Returns the path to the file where the snapshot for this package is stored, if one was defined.
+func (p *ProtoPackage) GetSnapshotFilePath() string {
+	if p == nil {
+		return ""
+	}
+
+	return p.snapshotFilePath
+}
+
+// Reads a snapshot from a JSON file. If the file does not exist, it returns nil without an error.
+func LoadSnapshot(path string) (*SchemaSnapshot, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("Failed to read the snapshot at %q: %w", path, err)
+	}
+
+	snapshot := &SchemaSnapshot{}
+
+	if err := json.Unmarshal(content, snapshot); err != nil {
+		return nil, fmt.Errorf("Failed to parse the snapshot at %q: %w", path, err)
+	}
+
+	if snapshot.Version > snapshotVersion {
+		return nil, fmt.Errorf("The snapshot at %q has version %d, which is not supported by this version of protoschema.", path, snapshot.Version)
+	}
+
+	return snapshot, nil
+}
+
+// Writes the snapshot to a JSON file, creating the parent directories if necessary.
+func (s *SchemaSnapshot) Save(path string) error {
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to encode the snapshot: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("Failed to write the snapshot at %q: %w", path, err)
+	}
+
+	return nil
+}
+
+// Returns the snapshot of the message with the given name (i.e. "myapp.v1.User.Address"), along with the name of its file.
+func (s *SchemaSnapshot) findMessage(fullName string) (*MessageSnapshot, string) {
+	for _, f := range s.Files {
+		for i, m := range f.Messages {
+			if qualifiedName(f.Package, m.Name) == fullName {
+				return &f.Messages[i], f.Name
+			}
+		}
+	}
+
+	return nil, ""
+}
+
+func (s *SchemaSnapshot) findEnum(fullName string) (*EnumSnapshot, string) {
+	for _, f := range s.Files {
+		for i, e := range f.Enums {
+			if qualifiedName(f.Package, e.Name) == fullName {
+				return &f.Enums[i], f.Name
+			}
+		}
+	}
+
+	return nil, ""
+}
+
+func (s *SchemaSnapshot) findService(fullName string) *ServiceSnapshot {
+	for _, f := range s.Files {
+		for i, serv := range f.Services {
+			if qualifiedName(f.Package, serv.Name) == fullName {
+				return &f.Services[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+func qualifiedName(pkg, name string) string {
+	if pkg == "" {
+		return name
+	}
+
+	return strings.Join([]string{pkg, name}, ".")
+}
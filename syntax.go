@@ -0,0 +1,211 @@
+package protoschema
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// The syntaxes supported for proto files.
+const (
+	SyntaxProto2 = "proto2"
+	SyntaxProto3 = "proto3"
+)
+
+// Returns the syntax of the file, defaulting to proto3. Files with an Edition always return "editions".
+func (f *FileSchema) GetSyntax() string {
+	if f == nil {
+		return SyntaxProto3
+	}
+
+	if f.Edition != "" {
+		return SyntaxEditions
+	}
+
+	if f.Syntax == "" {
+		return SyntaxProto3
+	}
+
+	return f.Syntax
+}
+
+// An extend block that adds fields to a message that declares extension ranges. Extend blocks targeting user-defined messages are not allowed in proto3 files.
+type ExtendBlock struct {
+	// The message being extended.
+	Target *MessageSchema
+	// The extension fields. The numbers must be inside one of the target's extension ranges.
+	Fields FieldsMap
+}
+
+// The processed data for an ExtendBlock.
+type ExtendData struct {
+	Target  *MessageSchema
+	Package *ProtoPackage
+	Fields  []FieldData
+}
+
+// Returns the label for a field, depending on the syntax of its file.
+func fieldLabel(f FieldData, syntax string, inOneof bool) string {
+	switch {
+	case f.Repeated:
+		return "repeated"
+	case f.IsMap || inOneof || syntax == SyntaxEditions:
+		return ""
+	case syntax == SyntaxProto2 && f.LabelRequired:
+		return "required"
+	case syntax == SyntaxProto2 || f.Optional:
+		return "optional"
+	}
+
+	return ""
+}
+
+// Checks that the field only uses constructs that are available in the syntax of the given file.
+// In files that use editions, this also adds the features that replace the optional keyword and the required label.
+func checkFieldSyntax(f *FieldData, file *FileSchema, inOneof bool) error {
+	syntax := file.GetSyntax()
+
+	if syntax == SyntaxEditions {
+		return checkFieldFeatures(f, file, inOneof)
+	}
+
+	var err error
+
+	if !f.Features.IsZero() {
+		err = errors.Join(err, fmt.Errorf("Field %q cannot set features, which are only available in files that use editions.", f.Name))
+	}
+
+	if syntax == SyntaxProto3 {
+		if f.LabelRequired {
+			err = errors.Join(err, fmt.Errorf("Field %q cannot have the required label, which is only available in proto2 files.", f.Name))
+		}
+
+		if f.Default != nil {
+			err = errors.Join(err, fmt.Errorf("Field %q cannot have a default value, which is only available in proto2 files.", f.Name))
+		}
+
+		if f.EnumRef != nil && f.EnumRef.isClosed() {
+			err = errors.Join(err, fmt.Errorf("Field %q uses the enum %q, which is closed and cannot be used in proto3 files.", f.Name, f.EnumRef.GetName()))
+		}
+
+		return err
+	}
+
+	// Open enums (such as those defined in proto3 files) cannot be used by proto2 messages
+	if f.EnumRef != nil && f.EnumRef.File != nil && !f.EnumRef.isClosed() {
+		err = errors.Join(err, fmt.Errorf("Field %q uses the enum %q, which is open and cannot be used in proto2 files.", f.Name, f.EnumRef.GetName()))
+	}
+
+	if f.LabelRequired && inOneof {
+		err = errors.Join(err, fmt.Errorf("Field %q cannot have the required label because it is part of a oneof.", f.Name))
+	}
+
+	if f.LabelRequired && f.Optional {
+		err = errors.Join(err, fmt.Errorf("Field %q cannot be both optional and required.", f.Name))
+	}
+
+	return err
+}
+
+// Sets the labels for the message's fields and checks that the message only uses constructs that are available in the syntax of the given file.
+func checkMessageSyntax(data *MessageData, file *FileSchema) error {
+	var err error
+	syntax := file.GetSyntax()
+
+	if syntax == SyntaxProto3 && len(data.ExtensionRanges) > 0 {
+		err = errors.Join(err, fmt.Errorf("Extension ranges are not available in proto3 files."))
+	}
+
+	if !data.Features.IsZero() {
+		if syntax != SyntaxEditions {
+			err = errors.Join(err, fmt.Errorf("Features can only be set in files that use editions."))
+		}
+
+		err = errors.Join(err, data.Features.validate(featureTargetMessage))
+	}
+
+	for i := range data.Fields {
+		data.Fields[i].Label = fieldLabel(data.Fields[i], syntax, false)
+		err = errors.Join(err, checkFieldSyntax(&data.Fields[i], file, false))
+	}
+
+	for _, of := range data.Oneofs {
+		for i := range of.Fields {
+			of.Fields[i].Label = fieldLabel(of.Fields[i], syntax, true)
+			err = errors.Join(err, checkFieldSyntax(&of.Fields[i], file, true))
+		}
+	}
+
+	return err
+}
+
+// Checks that the enum only uses constructs that are available in the syntax of its file.
+func checkEnumSyntax(e EnumGroup) error {
+	var err error
+	syntax := e.File.GetSyntax()
+
+	if !e.Features.IsZero() {
+		if syntax != SyntaxEditions {
+			err = errors.Join(err, fmt.Errorf("Features can only be set in files that use editions."))
+		}
+
+		err = errors.Join(err, e.Features.validate(featureTargetEnum))
+	}
+
+	if !e.isClosed() && len(e.Values) > 0 && e.Values[0].Number != 0 {
+		err = errors.Join(err, fmt.Errorf("The first value of an open enum must be zero, but %q has the number %d.", e.Values[0].Name, e.Values[0].Number))
+	}
+
+	return err
+}
+
+func (eb ExtendBlock) build(f *FileSchema, imports Set) (ExtendData, error) {
+	out := ExtendData{Target: eb.Target, Package: f.Package}
+
+	if eb.Target == nil {
+		return out, fmt.Errorf("Missing target message for extend block.")
+	}
+
+	var err error
+	syntax := f.GetSyntax()
+	target := eb.Target.GetFullName(f.Package)
+
+	if syntax == SyntaxProto3 && eb.Target.Package.GetName() != "google.protobuf" {
+		err = errors.Join(err, fmt.Errorf("Cannot extend %q. In proto3 files, extend blocks can only target the options messages from google/protobuf/descriptor.proto.", target))
+	}
+
+	if importPath := eb.Target.GetImportPath(); importPath != "" {
+		imports[importPath] = present
+	}
+
+	for _, nr := range slices.Sorted(maps.Keys(eb.Fields)) {
+		field, fieldErr := eb.Fields[nr].Build(nr, imports)
+		if fieldErr != nil {
+			err = errors.Join(err, indentErrors(fmt.Sprintf("Errors for field %q", field.Name), fieldErr))
+			continue
+		}
+
+		if field.IsMap {
+			err = errors.Join(err, fmt.Errorf("Extension field %q cannot be a map.", field.Name))
+		}
+
+		if field.LabelRequired {
+			err = errors.Join(err, fmt.Errorf("Extension field %q cannot have the required label.", field.Name))
+		}
+
+		err = errors.Join(err, checkFieldSyntax(&field, f, false))
+
+		// The ranges can only be checked for messages defined with this library
+		if eb.Target.File != nil {
+			if _, inRange := findRange(int64(nr), eb.Target.ExtensionRanges); !inRange {
+				err = errors.Join(err, fmt.Errorf("Extension field %q uses the number %d, which is not inside any of the extension ranges of %q.", field.Name, nr, target))
+			}
+		}
+
+		field.Label = fieldLabel(field, syntax, false)
+		out.Fields = append(out.Fields, field)
+	}
+
+	return out, err
+}
@@ -0,0 +1,156 @@
+package protoschema_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"github.com/bufbuild/protocompile"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestProto2Syntax(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	pkg := sb.NewProtoPackage(sb.ProtoPackageConfig{
+		Name:               "legacy.v1",
+		GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/legacyv1",
+		ProtoRoot:          tmpDir,
+		ConverterOutputDir: filepath.Join(tmpDir, "converter"),
+	})
+
+	file := pkg.NewFile(sb.FileSchema{Name: "legacy", Syntax: sb.SyntaxProto2})
+
+	status := file.NewEnum(sb.EnumGroup{Name: "Status", Members: sb.EnumMembers{1: "STATUS_ACTIVE", 2: "STATUS_INACTIVE"}})
+
+	account := file.NewMessage(sb.MessageSchema{
+		Name: "Account",
+		Fields: sb.FieldsMap{
+			1: sb.Int64("id").RequiredLabel(),
+			2: sb.String("name").Default("anonymous"),
+			3: sb.Repeated("tags", sb.String("")),
+			4: sb.EnumField("status", status).Default("STATUS_INACTIVE"),
+		},
+		ExtensionRanges: []sb.Range{{100, 199}},
+	})
+
+	file.Extends = []sb.ExtendBlock{{Target: account, Fields: sb.FieldsMap{100: sb.String("nickname")}}}
+
+	assert.NoError(t, pkg.Generate())
+
+	// Compiling the file checks the labels, defaults and extensions against the rules of proto2
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: []string{tmpDir}}),
+	}
+
+	results, err := compiler.Compile(context.Background(), "legacy/v1/legacy.proto")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	desc := protodesc.ToFileDescriptorProto(results[0])
+
+	outputPath := filepath.Join(tmpDir, "legacy/v1/legacy.proto")
+	generated, err := os.ReadFile(outputPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(generated), `syntax = "proto2";`)
+	assert.Contains(t, string(generated), `default = "anonymous"`)
+	assert.Contains(t, string(generated), `default = STATUS_INACTIVE`)
+
+	fields := make(map[string]*descriptorpb.FieldDescriptorProto)
+	for _, f := range desc.GetMessageType()[0].GetField() {
+		fields[f.GetName()] = f
+	}
+
+	assert.Equal(t, descriptorpb.FieldDescriptorProto_LABEL_REQUIRED, fields["id"].GetLabel())
+	assert.Equal(t, descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, fields["name"].GetLabel())
+	assert.Equal(t, descriptorpb.FieldDescriptorProto_LABEL_REPEATED, fields["tags"].GetLabel())
+	assert.Equal(t, "anonymous", fields["name"].GetDefaultValue())
+	assert.Equal(t, "STATUS_INACTIVE", fields["status"].GetDefaultValue())
+
+	extRanges := desc.GetMessageType()[0].GetExtensionRange()
+	if assert.Len(t, extRanges, 1) {
+		assert.Equal(t, int32(100), extRanges[0].GetStart())
+	}
+
+	if assert.Len(t, desc.GetExtension(), 1) {
+		assert.Equal(t, "nickname", desc.GetExtension()[0].GetName())
+		assert.Equal(t, ".legacy.v1.Account", desc.GetExtension()[0].GetExtendee())
+	}
+
+	newFile := func(syntax string) *sb.FileSchema {
+		return sb.NewProtoPackage(sb.ProtoPackageConfig{
+			Name:      "legacy.v1",
+			GoPackage: "github.com/Rick-Phoenix/protoschema/gen/legacyv1",
+			ProtoRoot: t.TempDir(),
+		}).NewFile(sb.FileSchema{Name: "invalid", Syntax: syntax})
+	}
+
+	shouldFail := map[string]func() *sb.FileSchema{
+		"required label in proto3": func() *sb.FileSchema {
+			f := newFile(sb.SyntaxProto3)
+			f.NewMessage(sb.MessageSchema{Name: "Item", Fields: sb.FieldsMap{1: sb.String("name").RequiredLabel()}})
+			return f
+		},
+		"default value in proto3": func() *sb.FileSchema {
+			f := newFile(sb.SyntaxProto3)
+			f.NewMessage(sb.MessageSchema{Name: "Item", Fields: sb.FieldsMap{1: sb.String("name").Default("x")}})
+			return f
+		},
+		"extension ranges in proto3": func() *sb.FileSchema {
+			f := newFile(sb.SyntaxProto3)
+			f.NewMessage(sb.MessageSchema{Name: "Item", Fields: sb.FieldsMap{1: sb.String("name")}, ExtensionRanges: []sb.Range{{100, 200}}})
+			return f
+		},
+		"extend block in proto3": func() *sb.FileSchema {
+			f := newFile(sb.SyntaxProto3)
+			f.Extends = []sb.ExtendBlock{{Target: account, Fields: sb.FieldsMap{100: sb.String("nickname")}}}
+			return f
+		},
+		"nonzero first enum value in proto3": func() *sb.FileSchema {
+			f := newFile(sb.SyntaxProto3)
+			f.NewEnum(sb.EnumGroup{Name: "Kind", Members: sb.EnumMembers{1: "KIND_A"}})
+			return f
+		},
+		"proto2 enum in proto3": func() *sb.FileSchema {
+			f := newFile(sb.SyntaxProto3)
+			f.NewMessage(sb.MessageSchema{Name: "Item", Fields: sb.FieldsMap{1: sb.EnumField("status", status)}})
+			return f
+		},
+		"proto3 enum in proto2": func() *sb.FileSchema {
+			proto3File := newFile(sb.SyntaxProto3)
+			kind := proto3File.NewEnum(sb.EnumGroup{Name: "Kind", Members: sb.EnumMembers{0: "KIND_UNSPECIFIED"}})
+			f := proto3File.Package.NewFile(sb.FileSchema{Name: "legacy", Syntax: sb.SyntaxProto2})
+			f.NewMessage(sb.MessageSchema{Name: "Item", Fields: sb.FieldsMap{1: sb.EnumField("kind", kind)}})
+			return f
+		},
+		"extension outside of the ranges": func() *sb.FileSchema {
+			f := newFile(sb.SyntaxProto2)
+			f.Extends = []sb.ExtendBlock{{Target: account, Fields: sb.FieldsMap{300: sb.String("nickname")}}}
+			return f
+		},
+		"required field in oneof": func() *sb.FileSchema {
+			f := newFile(sb.SyntaxProto2)
+			msg := f.NewMessage(sb.MessageSchema{Name: "Item", Fields: sb.FieldsMap{1: sb.String("name")}})
+			msg.NewOneof(sb.OneofGroup{Name: "choice", Fields: sb.OneofFields{2: sb.String("a").RequiredLabel()}})
+			return f
+		},
+		"default value for a message field": func() *sb.FileSchema {
+			f := newFile(sb.SyntaxProto2)
+			f.NewMessage(sb.MessageSchema{Name: "Item", Fields: sb.FieldsMap{1: sb.MsgField("account", account).Default("x")}})
+			return f
+		},
+		"unsupported syntax": func() *sb.FileSchema {
+			return newFile("proto4")
+		},
+	}
+
+	for name, build := range shouldFail {
+		_, err := build().Package.TryBuildFiles()
+		assert.Error(t, err, name)
+	}
+}
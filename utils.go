@@ -25,8 +25,14 @@ const (
 
 type Set map[string]struct{}
 
+// A value that is rendered as a plain identifier (such as the name of an enum value) instead of a string literal.
+type identifier string
+
 func formatProtoValue[T any](value T) (string, error) {
 	switch v := any(value).(type) {
+	// Feature values are enum values, so they are rendered as identifiers
+	case identifier, FieldPresence, EnumType, RepeatedFieldEncoding, Utf8Validation, GoAPILevel:
+		return fmt.Sprint(v), nil
 	case string:
 		return fmt.Sprintf("%q", v), nil
 	case []byte:
@@ -285,3 +291,30 @@ func getPkgPath(t reflect.Type) string {
 
 	return t.PkgPath()
 }
+
+// Formats a (possibly multiline) comment as a series of line comments with the given indentation, each followed by a newline. Returns an empty string if the comment is empty.
+func formatComment(doc string, indent string) string {
+	if doc == "" {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	for line := range strings.SplitSeq(strings.TrimRight(doc, "\n"), "\n") {
+		sb.WriteString(indent)
+		sb.WriteString(strings.TrimRight("// "+line, " "))
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// Formats a comment that is rendered at the end of a line, replacing its line breaks with spaces. Returns an empty string if the comment is empty.
+func formatTrailingComment(comment string) string {
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		return ""
+	}
+
+	return " // " + strings.Join(strings.Fields(comment), " ")
+}
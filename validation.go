@@ -0,0 +1,1054 @@
+package protoschema
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// The error returned when a message does not satisfy its protovalidate rules. The violations have the same structure as those of protovalidate, so they can be compared with its results or returned in the same way.
+type ValidationError struct {
+	Violations []*validate.Violation
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString("validation error:")
+
+	for _, v := range e.Violations {
+		b.WriteString("\n - ")
+		if field := FieldPathString(v.GetField()); field != "" {
+			b.WriteString(field + ": ")
+		}
+		fmt.Fprintf(&b, "%s [%s]", v.GetMessage(), v.GetRuleId())
+	}
+
+	return b.String()
+}
+
+// Returns the violations as a buf.validate.Violations message.
+func (e *ValidationError) ToProto() *validate.Violations {
+	return &validate.Violations{Violations: e.Violations}
+}
+
+// Returns the string representation of a field path, such as "items[0].tags[\"key\"]".
+func FieldPathString(path *validate.FieldPath) string {
+	var b strings.Builder
+
+	for i, el := range path.GetElements() {
+		if i > 0 {
+			b.WriteString(".")
+		}
+		b.WriteString(el.GetFieldName())
+
+		switch sub := el.GetSubscript().(type) {
+		case *validate.FieldPathElement_Index:
+			fmt.Fprintf(&b, "[%d]", sub.Index)
+		case *validate.FieldPathElement_BoolKey:
+			fmt.Fprintf(&b, "[%t]", sub.BoolKey)
+		case *validate.FieldPathElement_IntKey:
+			fmt.Fprintf(&b, "[%d]", sub.IntKey)
+		case *validate.FieldPathElement_UintKey:
+			fmt.Fprintf(&b, "[%d]", sub.UintKey)
+		case *validate.FieldPathElement_StringKey:
+			fmt.Fprintf(&b, "[%q]", sub.StringKey)
+		}
+	}
+
+	return b.String()
+}
+
+// Validates a message (such as a dynamic message created with MessageSchema.New, or a generated type) against the protovalidate rules in its descriptor, returning a *ValidationError with all the violations if any rule is not satisfied.
+// The rules are evaluated natively, so messages can be validated without protovalidate and without generating the go types. This covers the rules of the builders of this library: lengths, ranges, patterns, formats (such as email, hostname, ip, uri and uuid), in and not_in, const, required, the rules of repeated and map fields (including those for their items, keys and values), enums, durations, timestamps and oneofs.
+// CEL expressions are not evaluated, as they require a CEL runtime.
+func Validate(msg proto.Message) error {
+	ev := &ruleEvaluator{now: time.Now()}
+	ev.message(msg.ProtoReflect(), nil)
+
+	if len(ev.violations) > 0 {
+		return &ValidationError{Violations: ev.violations}
+	}
+
+	return nil
+}
+
+// Evaluates the protovalidate rules of messages, collecting the violations.
+type ruleEvaluator struct {
+	now        time.Time
+	violations []*validate.Violation
+	// The fields whose rules are not evaluated, such as those ignored by a model
+	skipped map[protoreflect.FieldDescriptor]bool
+	// The rules used instead of those of the compiled fields, if defined
+	rules fieldRulesOverride
+}
+
+// The location of the value being evaluated.
+type ruleTarget struct {
+	field []*validate.FieldPathElement
+	// The path of the rules that apply to the value, such as repeated.items for the items of a repeated field
+	rulePrefix []*validate.FieldPathElement
+	forKey     bool
+}
+
+func pathElement(fd protoreflect.FieldDescriptor) *validate.FieldPathElement {
+	el := &validate.FieldPathElement{
+		FieldNumber: proto.Int32(int32(fd.Number())),
+		FieldName:   proto.String(string(fd.Name())),
+		FieldType:   descriptorpb.FieldDescriptorProto_Type(fd.Kind()).Enum(),
+	}
+
+	if fd.IsExtension() {
+		el.FieldName = proto.String("[" + string(fd.FullName()) + "]")
+	}
+
+	return el
+}
+
+func (ev *ruleEvaluator) add(target ruleTarget, rulePath []protoreflect.FieldDescriptor, ruleID, message string) {
+	violation := &validate.Violation{RuleId: proto.String(ruleID), Message: proto.String(message)}
+
+	if len(target.field) > 0 {
+		violation.Field = &validate.FieldPath{Elements: slices.Clone(target.field)}
+	}
+
+	if len(target.rulePrefix) > 0 || len(rulePath) > 0 {
+		violation.Rule = &validate.FieldPath{Elements: slices.Clone(target.rulePrefix)}
+		for _, fd := range rulePath {
+			violation.Rule.Elements = append(violation.Rule.Elements, pathElement(fd))
+		}
+	}
+
+	if target.forKey {
+		violation.ForKey = proto.Bool(true)
+	}
+
+	ev.violations = append(ev.violations, violation)
+}
+
+// Returns the field of a rules message with the given name. The descriptors of different protovalidate versions may not have the same rules, so a missing field is reported as nil.
+func ruleField(rules protoreflect.Message, name protoreflect.Name) protoreflect.FieldDescriptor {
+	return rules.Descriptor().Fields().ByName(name)
+}
+
+func hasRule(rules protoreflect.Message, name protoreflect.Name) bool {
+	fd := ruleField(rules, name)
+	return fd != nil && rules.Has(fd)
+}
+
+// Evaluates the message rules, the oneof rules and the rules of every field of a message.
+func (ev *ruleEvaluator) message(m protoreflect.Message, path []*validate.FieldPathElement) {
+	desc := m.Descriptor()
+	target := ruleTarget{field: path}
+
+	if rules := optionExtension(desc.Options(), "buf.validate.message"); rules != nil {
+		if hasRule(rules, "disabled") && rules.Get(ruleField(rules, "disabled")).Bool() {
+			return
+		}
+
+		if oneofField := ruleField(rules, "oneof"); oneofField != nil {
+			list := rules.Get(oneofField).List()
+			for i := range list.Len() {
+				ev.messageOneof(m, list.Get(i).Message(), target, oneofField)
+			}
+		}
+	}
+
+	oneofs := desc.Oneofs()
+	for i := range oneofs.Len() {
+		od := oneofs.Get(i)
+		if od.IsSynthetic() {
+			continue
+		}
+
+		rules := optionExtension(od.Options(), "buf.validate.oneof")
+		if rules == nil || !hasRule(rules, "required") || !rules.Get(ruleField(rules, "required")).Bool() {
+			continue
+		}
+
+		if m.WhichOneof(od) == nil {
+			oneofTarget := ruleTarget{field: append(slices.Clone(path), &validate.FieldPathElement{FieldName: proto.String(string(od.Name()))})}
+			ev.add(oneofTarget, nil, "required", "exactly one field is required in oneof")
+		}
+	}
+
+	fields := desc.Fields()
+	for i := range fields.Len() {
+		if fd := fields.Get(i); !ev.skipped[fd] {
+			ev.field(m, fd, path)
+		}
+	}
+}
+
+// Evaluates a rule of the protovalidate version of oneof, where at most one of the fields can be set (and exactly one if it is required).
+func (ev *ruleEvaluator) messageOneof(m protoreflect.Message, rule protoreflect.Message, target ruleTarget, oneofField protoreflect.FieldDescriptor) {
+	names := rule.Get(ruleField(rule, "fields")).List()
+
+	var fieldNames []string
+	set := 0
+
+	for i := range names.Len() {
+		name := names.Get(i).String()
+		fieldNames = append(fieldNames, name)
+
+		if fd := m.Descriptor().Fields().ByName(protoreflect.Name(name)); fd != nil && m.Has(fd) {
+			set++
+		}
+	}
+
+	required := hasRule(rule, "required") && rule.Get(ruleField(rule, "required")).Bool()
+	list := strings.Join(fieldNames, ", ")
+
+	switch {
+	case set > 1:
+		ev.add(target, []protoreflect.FieldDescriptor{oneofField}, "message.oneof", fmt.Sprintf("only one of %s can be set", list))
+	case set == 0 && required:
+		ev.add(target, []protoreflect.FieldDescriptor{oneofField}, "message.oneof", fmt.Sprintf("one of %s must be set", list))
+	}
+}
+
+// Returns the name of the ignore rule without the prefix (such as IF_ZERO_VALUE), or an empty string if it is not set.
+func ignoreRule(rules protoreflect.Message) string {
+	if rules == nil || !hasRule(rules, "ignore") {
+		return ""
+	}
+
+	fd := ruleField(rules, "ignore")
+	value := fd.Enum().Values().ByNumber(rules.Get(fd).Enum())
+	if value == nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(string(value.Name()), "IGNORE_")
+}
+
+// Evaluates the rules of a field, and the rules of the messages that it contains.
+func (ev *ruleEvaluator) field(m protoreflect.Message, fd protoreflect.FieldDescriptor, path []*validate.FieldPathElement) {
+	rules := ev.rules.fieldRules(fd)
+	target := ruleTarget{field: append(slices.Clone(path), pathElement(fd))}
+	populated := m.Has(fd)
+
+	ignore := ignoreRule(rules)
+	if ignore == "ALWAYS" {
+		return
+	}
+
+	if rules != nil && hasRule(rules, "required") && rules.Get(ruleField(rules, "required")).Bool() && !populated {
+		ev.add(target, []protoreflect.FieldDescriptor{ruleField(rules, "required")}, "required", "value is required")
+		return
+	}
+
+	// The fields with explicit presence are only validated when they are set, and the others (including empty repeated and map fields) when the ignore rule allows it
+	if !populated && (fd.HasPresence() || ignore != "") {
+		return
+	}
+
+	value := m.Get(fd)
+
+	switch {
+	case fd.IsList():
+		ev.list(value.List(), fd, rules, target)
+	case fd.IsMap():
+		ev.mapEntries(value.Map(), fd, rules, target)
+	default:
+		ev.value(value, fd, rules, target)
+
+		if fd.Message() != nil && populated {
+			ev.message(value.Message(), target.field)
+		}
+	}
+}
+
+// Returns the nested rules with the given name (such as repeated, or the items of repeated fields), along with the field that contains them.
+func typeRules(rules protoreflect.Message, name protoreflect.Name) (protoreflect.Message, protoreflect.FieldDescriptor) {
+	if rules == nil || !hasRule(rules, name) {
+		return nil, nil
+	}
+
+	fd := ruleField(rules, name)
+	return rules.Get(fd).Message(), fd
+}
+
+func (ev *ruleEvaluator) list(list protoreflect.List, fd protoreflect.FieldDescriptor, rules protoreflect.Message, target ruleTarget) {
+	repeated, repeatedField := typeRules(rules, "repeated")
+
+	if repeated != nil {
+		count := uint64(list.Len())
+
+		if hasRule(repeated, "min_items") {
+			if minItems := repeated.Get(ruleField(repeated, "min_items")).Uint(); count < minItems {
+				ev.add(target, []protoreflect.FieldDescriptor{repeatedField, ruleField(repeated, "min_items")}, "repeated.min_items", fmt.Sprintf("value must contain at least %d item(s)", minItems))
+			}
+		}
+
+		if hasRule(repeated, "max_items") {
+			if maxItems := repeated.Get(ruleField(repeated, "max_items")).Uint(); count > maxItems {
+				ev.add(target, []protoreflect.FieldDescriptor{repeatedField, ruleField(repeated, "max_items")}, "repeated.max_items", fmt.Sprintf("value must contain no more than %d item(s)", maxItems))
+			}
+		}
+
+		if hasRule(repeated, "unique") && repeated.Get(ruleField(repeated, "unique")).Bool() && fd.Message() == nil {
+			seen := make(map[any]bool)
+			for i := range list.Len() {
+				key := list.Get(i).Interface()
+				if b, isBytes := key.([]byte); isBytes {
+					key = string(b)
+				}
+
+				if seen[key] {
+					ev.add(target, []protoreflect.FieldDescriptor{repeatedField, ruleField(repeated, "unique")}, "repeated.unique", "repeated value must contain unique items")
+					break
+				}
+				seen[key] = true
+			}
+		}
+	}
+
+	items, itemsField := typeRules(repeated, "items")
+
+	for i := range list.Len() {
+		itemTarget := ruleTarget{field: slices.Clone(target.field)}
+		last := proto.Clone(itemTarget.field[len(itemTarget.field)-1]).(*validate.FieldPathElement)
+		last.Subscript = &validate.FieldPathElement_Index{Index: uint64(i)}
+		itemTarget.field[len(itemTarget.field)-1] = last
+
+		if items != nil {
+			itemTarget.rulePrefix = []*validate.FieldPathElement{pathElement(repeatedField), pathElement(itemsField)}
+			ev.value(list.Get(i), fd, items, itemTarget)
+		}
+
+		if fd.Message() != nil {
+			ev.message(list.Get(i).Message(), itemTarget.field)
+		}
+	}
+}
+
+func (ev *ruleEvaluator) mapEntries(entries protoreflect.Map, fd protoreflect.FieldDescriptor, rules protoreflect.Message, target ruleTarget) {
+	mapRules, mapField := typeRules(rules, "map")
+
+	if mapRules != nil {
+		count := uint64(entries.Len())
+
+		if hasRule(mapRules, "min_pairs") {
+			if minPairs := mapRules.Get(ruleField(mapRules, "min_pairs")).Uint(); count < minPairs {
+				ev.add(target, []protoreflect.FieldDescriptor{mapField, ruleField(mapRules, "min_pairs")}, "map.min_pairs", fmt.Sprintf("map must be at least %d entries", minPairs))
+			}
+		}
+
+		if hasRule(mapRules, "max_pairs") {
+			if maxPairs := mapRules.Get(ruleField(mapRules, "max_pairs")).Uint(); count > maxPairs {
+				ev.add(target, []protoreflect.FieldDescriptor{mapField, ruleField(mapRules, "max_pairs")}, "map.max_pairs", fmt.Sprintf("map must be at most %d entries", maxPairs))
+			}
+		}
+	}
+
+	keys, keysField := typeRules(mapRules, "keys")
+	values, valuesField := typeRules(mapRules, "values")
+
+	var mapKeys []protoreflect.MapKey
+	entries.Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+		mapKeys = append(mapKeys, k)
+		return true
+	})
+
+	// The entries are evaluated in the order of their keys, so that the violations are deterministic
+	slices.SortFunc(mapKeys, func(a, b protoreflect.MapKey) int {
+		return cmp.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+	})
+
+	for _, k := range mapKeys {
+		entryTarget := ruleTarget{field: slices.Clone(target.field)}
+		last := proto.Clone(entryTarget.field[len(entryTarget.field)-1]).(*validate.FieldPathElement)
+		last.KeyType = descriptorpb.FieldDescriptorProto_Type(fd.MapKey().Kind()).Enum()
+		last.ValueType = descriptorpb.FieldDescriptorProto_Type(fd.MapValue().Kind()).Enum()
+
+		switch key := k.Interface().(type) {
+		case string:
+			last.Subscript = &validate.FieldPathElement_StringKey{StringKey: key}
+		case bool:
+			last.Subscript = &validate.FieldPathElement_BoolKey{BoolKey: key}
+		case int32:
+			last.Subscript = &validate.FieldPathElement_IntKey{IntKey: int64(key)}
+		case int64:
+			last.Subscript = &validate.FieldPathElement_IntKey{IntKey: key}
+		case uint32:
+			last.Subscript = &validate.FieldPathElement_UintKey{UintKey: uint64(key)}
+		case uint64:
+			last.Subscript = &validate.FieldPathElement_UintKey{UintKey: key}
+		}
+		entryTarget.field[len(entryTarget.field)-1] = last
+
+		if keys != nil {
+			keyTarget := ruleTarget{field: entryTarget.field, rulePrefix: []*validate.FieldPathElement{pathElement(mapField), pathElement(keysField)}, forKey: true}
+			ev.value(k.Value(), fd.MapKey(), keys, keyTarget)
+		}
+
+		value := entries.Get(k)
+
+		if values != nil {
+			valueTarget := ruleTarget{field: entryTarget.field, rulePrefix: []*validate.FieldPathElement{pathElement(mapField), pathElement(valuesField)}}
+			ev.value(value, fd.MapValue(), values, valueTarget)
+		}
+
+		if fd.MapValue().Message() != nil {
+			ev.message(value.Message(), entryTarget.field)
+		}
+	}
+}
+
+// Returns true if a single value is the zero value of its type.
+func isZeroValue(v protoreflect.Value, fd protoreflect.FieldDescriptor) bool {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return !v.Message().IsValid() || isEmptyMessage(v.Message())
+	case protoreflect.BytesKind:
+		return len(v.Bytes()) == 0
+	}
+
+	return v.Equal(fd.Default()) || v.Equal(protoreflect.ValueOf(reflectZero(fd.Kind())))
+}
+
+func isEmptyMessage(m protoreflect.Message) bool {
+	empty := true
+	m.Range(func(protoreflect.FieldDescriptor, protoreflect.Value) bool {
+		empty = false
+		return false
+	})
+	return empty
+}
+
+func reflectZero(kind protoreflect.Kind) any {
+	switch kind {
+	case protoreflect.BoolKind:
+		return false
+	case protoreflect.StringKind:
+		return ""
+	case protoreflect.EnumKind:
+		return protoreflect.EnumNumber(0)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return int32(0)
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return int64(0)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return uint32(0)
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return uint64(0)
+	case protoreflect.FloatKind:
+		return float32(0)
+	case protoreflect.DoubleKind:
+		return float64(0)
+	}
+
+	return nil
+}
+
+// Evaluates the rules for a single value (a singular field, or an item, key or value of a repeated or map field).
+func (ev *ruleEvaluator) value(v protoreflect.Value, fd protoreflect.FieldDescriptor, rules protoreflect.Message, target ruleTarget) {
+	if rules == nil {
+		return
+	}
+
+	if ignore := ignoreRule(rules); ignore == "ALWAYS" || (ignore != "" && isZeroValue(v, fd)) {
+		return
+	}
+
+	rules.Range(func(rfd protoreflect.FieldDescriptor, rv protoreflect.Value) bool {
+		if rfd.Message() == nil || rfd.IsList() {
+			return true
+		}
+
+		typed := rv.Message()
+		name := string(rfd.Name())
+
+		switch name {
+		case "string":
+			ev.stringRules(v.String(), typed, rfd, target)
+		case "bytes":
+			ev.bytesRules(v.Bytes(), typed, rfd, target)
+		case "bool":
+			ev.constRule(v, typed, rfd, target, name)
+		case "enum":
+			ev.enumRules(v.Enum(), fd.Enum(), typed, rfd, target)
+		case "any":
+			ev.anyRules(v.Message(), typed, rfd, target)
+		case "duration", "timestamp":
+			ev.orderedRules(v, typed, rfd, target, name)
+		case "repeated", "map":
+			// Evaluated with the field, since they apply to the whole list or map
+		default:
+			if _, isNumeric := numericRuleTypes[name]; isNumeric {
+				ev.orderedRules(v, typed, rfd, target, name)
+			}
+		}
+
+		return true
+	})
+}
+
+// The names of the rules for numeric types.
+var numericRuleTypes = map[string]bool{
+	"int32": true, "int64": true, "uint32": true, "uint64": true, "sint32": true, "sint64": true,
+	"fixed32": true, "fixed64": true, "sfixed32": true, "sfixed64": true, "float": true, "double": true,
+}
+
+// Evaluates the const rule, which is shared by all types.
+func (ev *ruleEvaluator) constRule(v protoreflect.Value, rules protoreflect.Message, typeField protoreflect.FieldDescriptor, target ruleTarget, typeName string) {
+	if !hasRule(rules, "const") {
+		return
+	}
+
+	constField := ruleField(rules, "const")
+	expected := rules.Get(constField)
+
+	if !valuesEqual(v, expected) {
+		ev.add(target, []protoreflect.FieldDescriptor{typeField, constField}, typeName+".const", fmt.Sprintf("value must equal %s", formatRuleValue(expected)))
+	}
+}
+
+// Compares two values of the same type, including messages (such as durations and timestamps).
+func valuesEqual(a, b protoreflect.Value) bool {
+	if am, isMessage := a.Interface().(protoreflect.Message); isMessage {
+		bm, isMessage := b.Interface().(protoreflect.Message)
+		return isMessage && proto.Equal(am.Interface(), bm.Interface())
+	}
+
+	return a.Equal(b)
+}
+
+// Evaluates the in and not_in rules, which are shared by most types.
+func (ev *ruleEvaluator) inRules(v protoreflect.Value, rules protoreflect.Message, typeField protoreflect.FieldDescriptor, target ruleTarget, typeName string) {
+	contains := func(list protoreflect.List) bool {
+		for i := range list.Len() {
+			if valuesEqual(v, list.Get(i)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if hasRule(rules, "in") {
+		inField := ruleField(rules, "in")
+		if list := rules.Get(inField).List(); !contains(list) {
+			ev.add(target, []protoreflect.FieldDescriptor{typeField, inField}, typeName+".in", fmt.Sprintf("value must be in list %s", formatRuleList(list)))
+		}
+	}
+
+	if hasRule(rules, "not_in") {
+		notInField := ruleField(rules, "not_in")
+		if list := rules.Get(notInField).List(); contains(list) {
+			ev.add(target, []protoreflect.FieldDescriptor{typeField, notInField}, typeName+".not_in", fmt.Sprintf("value must not be in list %s", formatRuleList(list)))
+		}
+	}
+}
+
+// Formats the value of a rule for the messages of the violations.
+func formatRuleValue(v protoreflect.Value) string {
+	if m, isMessage := v.Interface().(protoreflect.Message); isMessage {
+		seconds, nanos := secondsAndNanos(m)
+
+		if m.Descriptor().FullName() == "google.protobuf.Timestamp" {
+			return time.Unix(seconds, nanos).UTC().Format(time.RFC3339Nano)
+		}
+
+		return (time.Duration(seconds)*time.Second + time.Duration(nanos)).String()
+	}
+
+	if s, isString := v.Interface().(string); isString {
+		return strconv.Quote(s)
+	}
+
+	return fmt.Sprint(v.Interface())
+}
+
+func formatRuleList(list protoreflect.List) string {
+	items := make([]string, 0, list.Len())
+	for i := range list.Len() {
+		items = append(items, formatRuleValue(list.Get(i)))
+	}
+
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+// Returns the seconds and nanos of a duration or timestamp message.
+func secondsAndNanos(m protoreflect.Message) (int64, int64) {
+	fields := m.Descriptor().Fields()
+	return m.Get(fields.ByName("seconds")).Int(), m.Get(fields.ByName("nanos")).Int()
+}
+
+// Compares two numbers, durations or timestamps. The second result is false if either value is NaN.
+func compareOrdered(a, b protoreflect.Value) (int, bool) {
+	switch av := a.Interface().(type) {
+	case int32, int64:
+		return cmp.Compare(a.Int(), b.Int()), true
+	case uint32, uint64:
+		return cmp.Compare(a.Uint(), b.Uint()), true
+	case float32, float64:
+		af, bf := a.Float(), b.Float()
+		if math.IsNaN(af) || math.IsNaN(bf) {
+			return 0, false
+		}
+		return cmp.Compare(af, bf), true
+	case protoreflect.Message:
+		as, an := secondsAndNanos(av)
+		bs, bn := secondsAndNanos(b.Message())
+		if c := cmp.Compare(as, bs); c != 0 {
+			return c, true
+		}
+		return cmp.Compare(an, bn), true
+	}
+
+	return 0, false
+}
+
+// Evaluates the rules of numbers, durations and timestamps: const, the ranges defined by lt, lte, gt and gte, in, not_in, finite (for floats), lt_now, gt_now and within (for timestamps).
+func (ev *ruleEvaluator) orderedRules(v protoreflect.Value, rules protoreflect.Message, typeField protoreflect.FieldDescriptor, target ruleTarget, typeName string) {
+	ev.constRule(v, rules, typeField, target, typeName)
+	ev.inRules(v, rules, typeField, target, typeName)
+
+	rulePath := func(name protoreflect.Name) []protoreflect.FieldDescriptor {
+		return []protoreflect.FieldDescriptor{typeField, ruleField(rules, name)}
+	}
+
+	if hasRule(rules, "finite") && rules.Get(ruleField(rules, "finite")).Bool() {
+		if f := v.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+			ev.add(target, rulePath("finite"), typeName+".finite", "value must be finite")
+		}
+	}
+
+	var lower, upper protoreflect.Name
+	for _, name := range []protoreflect.Name{"gt", "gte"} {
+		if hasRule(rules, name) {
+			lower = name
+		}
+	}
+	for _, name := range []protoreflect.Name{"lt", "lte"} {
+		if hasRule(rules, name) {
+			upper = name
+		}
+	}
+
+	// Checks if the value satisfies a single bound
+	satisfies := func(name protoreflect.Name) bool {
+		c, ok := compareOrdered(v, rules.Get(ruleField(rules, name)))
+		if !ok {
+			return false
+		}
+
+		switch name {
+		case "gt":
+			return c > 0
+		case "gte":
+			return c >= 0
+		case "lt":
+			return c < 0
+		default:
+			return c <= 0
+		}
+	}
+
+	describe := map[protoreflect.Name]string{"gt": "greater than", "gte": "greater than or equal to", "lt": "less than", "lte": "less than or equal to"}
+	boundValue := func(name protoreflect.Name) string {
+		return formatRuleValue(rules.Get(ruleField(rules, name)))
+	}
+
+	switch {
+	case lower != "" && upper != "":
+		// When the lower bound is greater than the upper bound, the range is exclusive (the value must be outside of it)
+		c, _ := compareOrdered(rules.Get(ruleField(rules, upper)), rules.Get(ruleField(rules, lower)))
+		ruleID := fmt.Sprintf("%s.%s_%s", typeName, lower, upper)
+
+		if c > 0 || (c == 0 && lower == "gte" && upper == "lte") {
+			if !satisfies(lower) || !satisfies(upper) {
+				ev.add(target, rulePath(lower), ruleID, fmt.Sprintf("value must be %s %s and %s %s", describe[lower], boundValue(lower), describe[upper], boundValue(upper)))
+			}
+		} else if !satisfies(lower) && !satisfies(upper) {
+			ev.add(target, rulePath(lower), ruleID+"_exclusive", fmt.Sprintf("value must be %s %s or %s %s", describe[lower], boundValue(lower), describe[upper], boundValue(upper)))
+		}
+	case lower != "":
+		if !satisfies(lower) {
+			ev.add(target, rulePath(lower), fmt.Sprintf("%s.%s", typeName, lower), fmt.Sprintf("value must be %s %s", describe[lower], boundValue(lower)))
+		}
+	case upper != "":
+		if !satisfies(upper) {
+			ev.add(target, rulePath(upper), fmt.Sprintf("%s.%s", typeName, upper), fmt.Sprintf("value must be %s %s", describe[upper], boundValue(upper)))
+		}
+	}
+
+	if typeName != "timestamp" {
+		return
+	}
+
+	seconds, nanos := secondsAndNanos(v.Message())
+	ts := time.Unix(seconds, nanos)
+
+	if hasRule(rules, "lt_now") && rules.Get(ruleField(rules, "lt_now")).Bool() && !ts.Before(ev.now) {
+		ev.add(target, rulePath("lt_now"), "timestamp.lt_now", "value must be less than now")
+	}
+
+	if hasRule(rules, "gt_now") && rules.Get(ruleField(rules, "gt_now")).Bool() && !ts.After(ev.now) {
+		ev.add(target, rulePath("gt_now"), "timestamp.gt_now", "value must be greater than now")
+	}
+
+	if hasRule(rules, "within") {
+		ws, wn := secondsAndNanos(rules.Get(ruleField(rules, "within")).Message())
+		within := time.Duration(ws)*time.Second + time.Duration(wn)
+
+		if diff := ts.Sub(ev.now).Abs(); diff > within {
+			ev.add(target, rulePath("within"), "timestamp.within", fmt.Sprintf("value must be within %s of now", within))
+		}
+	}
+}
+
+func (ev *ruleEvaluator) enumRules(v protoreflect.EnumNumber, enum protoreflect.EnumDescriptor, rules protoreflect.Message, typeField protoreflect.FieldDescriptor, target ruleTarget) {
+	value := protoreflect.ValueOfInt32(int32(v))
+
+	if hasRule(rules, "const") {
+		if expected := rules.Get(ruleField(rules, "const")); expected.Int() != value.Int() {
+			ev.add(target, []protoreflect.FieldDescriptor{typeField, ruleField(rules, "const")}, "enum.const", fmt.Sprintf("value must equal %d", expected.Int()))
+		}
+	}
+
+	ev.inRules(value, rules, typeField, target, "enum")
+
+	if hasRule(rules, "defined_only") && rules.Get(ruleField(rules, "defined_only")).Bool() && enum.Values().ByNumber(v) == nil {
+		ev.add(target, []protoreflect.FieldDescriptor{typeField, ruleField(rules, "defined_only")}, "enum.defined_only", "value must be one of the defined enum values")
+	}
+}
+
+func (ev *ruleEvaluator) anyRules(m protoreflect.Message, rules protoreflect.Message, typeField protoreflect.FieldDescriptor, target ruleTarget) {
+	typeURL := protoreflect.ValueOfString(m.Get(m.Descriptor().Fields().ByName("type_url")).String())
+	ev.inRules(typeURL, rules, typeField, target, "any")
+}
+
+// Evaluates the length rules of strings and bytes. The unit is the name used in the messages (characters or bytes).
+func (ev *ruleEvaluator) lengthRules(length uint64, rules protoreflect.Message, typeField protoreflect.FieldDescriptor, target ruleTarget, typeName string, names [3]protoreflect.Name, unit string) {
+	checks := []struct {
+		name   protoreflect.Name
+		fails  func(limit uint64) bool
+		format string
+	}{
+		{names[0], func(limit uint64) bool { return length != limit }, "value length must be %d " + unit},
+		{names[1], func(limit uint64) bool { return length < limit }, "value length must be at least %d " + unit},
+		{names[2], func(limit uint64) bool { return length > limit }, "value length must be at most %d " + unit},
+	}
+
+	for _, check := range checks {
+		if !hasRule(rules, check.name) {
+			continue
+		}
+
+		if limit := rules.Get(ruleField(rules, check.name)).Uint(); check.fails(limit) {
+			ev.add(target, []protoreflect.FieldDescriptor{typeField, ruleField(rules, check.name)}, fmt.Sprintf("%s.%s", typeName, check.name), fmt.Sprintf(check.format, limit))
+		}
+	}
+}
+
+// The regular expressions for the string formats that are not covered by the standard library.
+var (
+	uuidRegex       = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	tuuidRegex      = regexp.MustCompile(`^[0-9a-fA-F]{32}$`)
+	ulidRegex       = regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Za-hjkmnp-tv-z]{25}$`)
+	hostnameLabel   = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?$`)
+	headerNameRegex = regexp.MustCompile(`^:?[0-9a-zA-Z!#$%&'*+\-.^_|~` + "`" + `]+$`)
+	// Header values cannot contain control characters other than horizontal tabs
+	headerValueRegex = regexp.MustCompile(`^[^\x00-\x08\x0A-\x1F\x7F]*$`)
+)
+
+func isHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > 253 {
+		return false
+	}
+
+	labels := strings.Split(s, ".")
+	for _, label := range labels {
+		if len(label) > 63 || !hostnameLabel.MatchString(label) {
+			return false
+		}
+	}
+
+	// The last label cannot be entirely numeric
+	_, err := strconv.Atoi(labels[len(labels)-1])
+	return err != nil
+}
+
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Name != "" || addr.Address != s {
+		return false
+	}
+
+	at := strings.LastIndex(s, "@")
+	return at > 0 && len(s[:at]) <= 64 && isHostname(s[at+1:])
+}
+
+func isIP(s string, version int) bool {
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return false
+	}
+
+	switch version {
+	case 4:
+		return addr.Is4()
+	case 6:
+		return addr.Is6()
+	}
+
+	return true
+}
+
+// Checks if a string is an address with a prefix length (such as 192.168.0.1/24). If strict is true, the address must be the network address of the prefix.
+func isIPPrefix(s string, version int, strict bool) bool {
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return false
+	}
+
+	if strict && prefix.Masked().Addr() != prefix.Addr() {
+		return false
+	}
+
+	switch version {
+	case 4:
+		return prefix.Addr().Is4()
+	case 6:
+		return prefix.Addr().Is6()
+	}
+
+	return true
+}
+
+func isURI(s string, absolute bool) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+
+	return !absolute || u.Scheme != ""
+}
+
+func isHostAndPort(s string) bool {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return false
+	}
+
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil || (len(port) > 1 && port[0] == '0') {
+		return false
+	}
+
+	return isHostname(host) || isIP(host, 0)
+}
+
+// The string formats that can be checked natively, by the name of their rule.
+var stringFormats = map[string]struct {
+	check       func(string) bool
+	description string
+}{
+	"email":               {isEmail, "email address"},
+	"hostname":            {isHostname, "hostname"},
+	"ip":                  {func(s string) bool { return isIP(s, 0) }, "IP address"},
+	"ipv4":                {func(s string) bool { return isIP(s, 4) }, "IPv4 address"},
+	"ipv6":                {func(s string) bool { return isIP(s, 6) }, "IPv6 address"},
+	"uri":                 {func(s string) bool { return isURI(s, true) }, "URI"},
+	"uri_ref":             {func(s string) bool { return isURI(s, false) }, "URI reference"},
+	"address":             {func(s string) bool { return isHostname(s) || isIP(s, 0) }, "hostname or IP address"},
+	"uuid":                {uuidRegex.MatchString, "UUID"},
+	"tuuid":               {tuuidRegex.MatchString, "trimmed UUID"},
+	"ulid":                {ulidRegex.MatchString, "ULID"},
+	"ip_with_prefixlen":   {func(s string) bool { return isIPPrefix(s, 0, false) }, "IP prefix"},
+	"ipv4_with_prefixlen": {func(s string) bool { return isIPPrefix(s, 4, false) }, "IPv4 address with prefix length"},
+	"ipv6_with_prefixlen": {func(s string) bool { return isIPPrefix(s, 6, false) }, "IPv6 address with prefix length"},
+	"ip_prefix":           {func(s string) bool { return isIPPrefix(s, 0, true) }, "IP prefix"},
+	"ipv4_prefix":         {func(s string) bool { return isIPPrefix(s, 4, true) }, "IPv4 prefix"},
+	"ipv6_prefix":         {func(s string) bool { return isIPPrefix(s, 6, true) }, "IPv6 prefix"},
+	"host_and_port":       {isHostAndPort, "host and port pair"},
+}
+
+func (ev *ruleEvaluator) stringRules(s string, rules protoreflect.Message, typeField protoreflect.FieldDescriptor, target ruleTarget) {
+	value := protoreflect.ValueOfString(s)
+	ev.constRule(value, rules, typeField, target, "string")
+
+	ev.lengthRules(uint64(utf8.RuneCountInString(s)), rules, typeField, target, "string", [3]protoreflect.Name{"len", "min_len", "max_len"}, "characters")
+	ev.lengthRules(uint64(len(s)), rules, typeField, target, "string", [3]protoreflect.Name{"len_bytes", "min_bytes", "max_bytes"}, "bytes")
+
+	ev.patternRules(s, rules, typeField, target, "string")
+	ev.inRules(value, rules, typeField, target, "string")
+
+	if hasRule(rules, "not_contains") {
+		if sub := rules.Get(ruleField(rules, "not_contains")).String(); strings.Contains(s, sub) {
+			ev.add(target, []protoreflect.FieldDescriptor{typeField, ruleField(rules, "not_contains")}, "string.not_contains", fmt.Sprintf("value must not contain substring %q", sub))
+		}
+	}
+
+	rules.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		name := string(fd.Name())
+
+		switch name {
+		case "well_known_regex":
+			ev.headerRule(s, rules, typeField, fd, v, target)
+			return true
+		}
+
+		format, isFormat := stringFormats[name]
+		if !isFormat || fd.Kind() != protoreflect.BoolKind || !v.Bool() {
+			return true
+		}
+
+		rulePath := []protoreflect.FieldDescriptor{typeField, fd}
+
+		switch {
+		case s == "":
+			ev.add(target, rulePath, "string."+name+"_empty", fmt.Sprintf("value is empty, which is not a valid %s", format.description))
+		case !format.check(s):
+			ev.add(target, rulePath, "string."+name, fmt.Sprintf("value must be a valid %s", format.description))
+		}
+
+		return true
+	})
+}
+
+// Evaluates the well-known regular expressions for HTTP header names and values.
+func (ev *ruleEvaluator) headerRule(s string, rules protoreflect.Message, typeField, fd protoreflect.FieldDescriptor, v protoreflect.Value, target ruleTarget) {
+	value := fd.Enum().Values().ByNumber(v.Enum())
+	if value == nil {
+		return
+	}
+
+	rulePath := []protoreflect.FieldDescriptor{typeField, fd}
+
+	switch {
+	case strings.HasSuffix(string(value.Name()), "HEADER_NAME"):
+		if s == "" {
+			ev.add(target, rulePath, "string.well_known_regex.header_name_empty", "value is empty, which is not a valid HTTP header name")
+		} else if !headerNameRegex.MatchString(s) {
+			ev.add(target, rulePath, "string.well_known_regex.header_name", "value must be a valid HTTP header name")
+		}
+	case strings.HasSuffix(string(value.Name()), "HEADER_VALUE"):
+		if !headerValueRegex.MatchString(s) {
+			ev.add(target, rulePath, "string.well_known_regex.header_value", "value must be a valid HTTP header value")
+		}
+	}
+}
+
+// Evaluates the pattern, prefix, suffix and contains rules of strings and bytes.
+func (ev *ruleEvaluator) patternRules(s string, rules protoreflect.Message, typeField protoreflect.FieldDescriptor, target ruleTarget, typeName string) {
+	rulePath := func(name protoreflect.Name) []protoreflect.FieldDescriptor {
+		return []protoreflect.FieldDescriptor{typeField, ruleField(rules, name)}
+	}
+
+	if hasRule(rules, "pattern") {
+		pattern := rules.Get(ruleField(rules, "pattern")).String()
+		re, err := regexp.Compile(pattern)
+
+		switch {
+		case err != nil:
+			ev.add(target, rulePath("pattern"), typeName+".pattern", fmt.Sprintf("invalid pattern %q: %v", pattern, err))
+		case !re.MatchString(s):
+			ev.add(target, rulePath("pattern"), typeName+".pattern", fmt.Sprintf("value does not match regex pattern %q", pattern))
+		}
+	}
+
+	affixes := []struct {
+		name    protoreflect.Name
+		check   func(s, affix string) bool
+		message string
+	}{
+		{"prefix", strings.HasPrefix, "value does not have prefix %s"},
+		{"suffix", strings.HasSuffix, "value does not have suffix %s"},
+		{"contains", strings.Contains, "value does not contain substring %s"},
+	}
+
+	for _, affix := range affixes {
+		if !hasRule(rules, affix.name) {
+			continue
+		}
+
+		ruleValue := rules.Get(ruleField(rules, affix.name))
+
+		var expected string
+		if typeName == "bytes" {
+			expected = string(ruleValue.Bytes())
+		} else {
+			expected = ruleValue.String()
+		}
+
+		if !affix.check(s, expected) {
+			ev.add(target, rulePath(affix.name), fmt.Sprintf("%s.%s", typeName, affix.name), fmt.Sprintf(affix.message, strconv.Quote(expected)))
+		}
+	}
+}
+
+func (ev *ruleEvaluator) bytesRules(b []byte, rules protoreflect.Message, typeField protoreflect.FieldDescriptor, target ruleTarget) {
+	value := protoreflect.ValueOfBytes(b)
+
+	if hasRule(rules, "const") {
+		if expected := rules.Get(ruleField(rules, "const")).Bytes(); string(expected) != string(b) {
+			ev.add(target, []protoreflect.FieldDescriptor{typeField, ruleField(rules, "const")}, "bytes.const", fmt.Sprintf("value must be %x", expected))
+		}
+	}
+
+	ev.lengthRules(uint64(len(b)), rules, typeField, target, "bytes", [3]protoreflect.Name{"len", "min_len", "max_len"}, "bytes")
+
+	if hasRule(rules, "pattern") && !utf8.Valid(b) {
+		ev.add(target, []protoreflect.FieldDescriptor{typeField, ruleField(rules, "pattern")}, "bytes.pattern", "value must be valid UTF-8 to apply regexp")
+	} else {
+		ev.patternRules(string(b), rules, typeField, target, "bytes")
+	}
+
+	contains := func(list protoreflect.List) bool {
+		for i := range list.Len() {
+			if string(list.Get(i).Bytes()) == string(value.Bytes()) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if hasRule(rules, "in") && !contains(rules.Get(ruleField(rules, "in")).List()) {
+		ev.add(target, []protoreflect.FieldDescriptor{typeField, ruleField(rules, "in")}, "bytes.in", "value must be in the list of allowed values")
+	}
+
+	if hasRule(rules, "not_in") && contains(rules.Get(ruleField(rules, "not_in")).List()) {
+		ev.add(target, []protoreflect.FieldDescriptor{typeField, ruleField(rules, "not_in")}, "bytes.not_in", "value must not be in the list of disallowed values")
+	}
+
+	ipRules := []struct {
+		name        protoreflect.Name
+		valid       bool
+		description string
+	}{
+		{"ip", len(b) == 4 || len(b) == 16, "IP address"},
+		{"ipv4", len(b) == 4, "IPv4 address"},
+		{"ipv6", len(b) == 16, "IPv6 address"},
+	}
+
+	for _, rule := range ipRules {
+		if !hasRule(rules, rule.name) || !rules.Get(ruleField(rules, rule.name)).Bool() {
+			continue
+		}
+
+		rulePath := []protoreflect.FieldDescriptor{typeField, ruleField(rules, rule.name)}
+
+		if len(b) == 0 {
+			ev.add(target, rulePath, fmt.Sprintf("bytes.%s_empty", rule.name), fmt.Sprintf("value is empty, which is not a valid %s", rule.description))
+		} else if !rule.valid {
+			ev.add(target, rulePath, fmt.Sprintf("bytes.%s", rule.name), fmt.Sprintf("value must be a valid %s", rule.description))
+		}
+	}
+}
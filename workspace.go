@@ -0,0 +1,276 @@
+package protoschema
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// A collection of proto packages that are processed and generated together.
+// The packages of a workspace can reference each other's messages and enums, and the converters of their messages call each other across packages.
+type Workspace struct {
+	packages []*ProtoPackage
+	// The rendered content of all the files in the workspace, mapped by their path. Used to resolve the imports between packages when building the descriptors.
+	sources map[string]string
+}
+
+// The constructor for a Workspace instance.
+func NewWorkspace() *Workspace {
+	return &Workspace{sources: make(map[string]string)}
+}
+
+// Creates a new package in the workspace and returns a pointer to it.
+func (w *Workspace) NewPackage(conf ProtoPackageConfig) *ProtoPackage {
+	if w.GetPackage(conf.Name) != nil {
+		log.Fatalf("The package %q is already defined in this workspace.", conf.Name)
+	}
+
+	p := NewProtoPackage(conf)
+	p.workspace = w
+	w.packages = append(w.packages, p)
+
+	return p
+}
+
+// Returns the packages in the workspace, in the order in which they were added.
+func (w *Workspace) GetPackages() []*ProtoPackage {
+	return slices.Clone(w.packages)
+}
+
+// Returns the package with the given name, or nil if it is not part of the workspace.
+func (w *Workspace) GetPackage(name string) *ProtoPackage {
+	for _, p := range w.packages {
+		if p.GetName() == name {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// Processes the files of all the packages and returns their data, mapped by the name of their package.
+// If any errors occur, they are printed and the program exits. To handle the errors directly, use TryBuildFiles.
+func (w *Workspace) BuildFiles() map[string][]FileData {
+	out, err := w.TryBuildFiles()
+
+	if err != nil {
+		fmt.Printf("  ❌ The following errors occurred:\n")
+		fmt.Print(err.Error())
+		os.Exit(1)
+	}
+
+	return out
+}
+
+// Processes the files of all the packages and returns their data (mapped by the name of their package), along with all the errors that occurred while processing the schemas.
+// Besides the errors in the single packages, this reports the import cycles between files and the conflicts between the converters of different packages.
+func (w *Workspace) TryBuildFiles() (map[string][]FileData, error) {
+	out := make(map[string][]FileData)
+	var err error
+
+	imports := make(map[string]Set)
+
+	for _, p := range w.packages {
+		filesData, pkgErr := p.TryBuildFiles()
+		if pkgErr != nil {
+			err = errors.Join(err, indentErrors(fmt.Sprintf("Errors in the package %q", p.GetName()), pkgErr))
+		}
+
+		out[p.GetName()] = filesData
+
+		for _, fileData := range filesData {
+			imports[strings.ToLower(p.getFilePath(fileData))] = fileData.Imports
+		}
+	}
+
+	err = errors.Join(err, checkImportCycles(imports), w.checkConverters())
+
+	if err != nil {
+		return out, err
+	}
+
+	for _, p := range w.packages {
+		for _, fileData := range out[p.GetName()] {
+			content, renderErr := p.renderFile(fileData)
+			if renderErr != nil {
+				return out, renderErr
+			}
+
+			w.sources[p.getFilePath(fileData)] = string(content)
+		}
+	}
+
+	return out, nil
+}
+
+// Processes all the packages and generates their files. Every package is processed before any file is generated, so that the references between packages can be resolved.
+// The buf files are generated once for each ProtoRoot, for all the packages in it that have the buf generation enabled.
+func (w *Workspace) Generate() error {
+	return w.GenerateFiles(w.BuildFiles())
+}
+
+// Generates the files of all the packages from the data returned by TryBuildFiles.
+func (w *Workspace) GenerateFiles(filesData map[string][]FileData) error {
+	var roots []string
+	rootPackages := make(map[string][]*ProtoPackage)
+	rootFiles := make(map[string][][]FileData)
+
+	for _, p := range w.packages {
+		if err := p.generate(filesData[p.GetName()]); err != nil {
+			return fmt.Errorf("Failed to generate the package %q: %w", p.GetName(), err)
+		}
+
+		if p.bufConfig.Generate {
+			root := filepath.Clean(p.protoRoot)
+			if _, exists := rootPackages[root]; !exists {
+				roots = append(roots, root)
+			}
+
+			rootPackages[root] = append(rootPackages[root], p)
+			rootFiles[root] = append(rootFiles[root], filesData[p.GetName()])
+		}
+	}
+
+	for _, root := range roots {
+		if err := writeBufFiles(rootPackages[root], rootFiles[root]); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range w.packages {
+		if err := p.saveManifest(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Checks that the packages that use the default converter write it in different go packages.
+func (w *Workspace) checkConverters() error {
+	var err error
+	dirs := make(map[string]string)
+	names := make(map[string]string)
+
+	for _, p := range w.packages {
+		if p.converterFunc != nil {
+			continue
+		}
+
+		dir := filepath.Clean(p.converterOutputDir)
+
+		if other, exists := dirs[dir]; exists {
+			err = errors.Join(err, fmt.Errorf("The packages %q and %q use the same converter output directory %q. Each package in a workspace must have its own converter package.", other, p.GetName(), dir))
+		} else if other, exists := names[p.converterPackage]; exists {
+			err = errors.Join(err, fmt.Errorf("The packages %q and %q use the same converter package name %q. Each package in a workspace must have a converter package with a different name.", other, p.GetName(), p.converterPackage))
+		}
+
+		dirs[dir] = p.GetName()
+		names[p.converterPackage] = p.GetName()
+	}
+
+	return err
+}
+
+// Detects the cycles in the imports between the given files, which are mapped by their path.
+func checkImportCycles(imports map[string]Set) error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int)
+	var stack []string
+	var err error
+
+	var visit func(file string)
+	visit = func(file string) {
+		state[file] = visiting
+		stack = append(stack, file)
+
+		var fileImports []string
+		for imp := range imports[file] {
+			fileImports = append(fileImports, strings.ToLower(imp))
+		}
+		slices.Sort(fileImports)
+
+		for _, imp := range fileImports {
+			if _, isLocal := imports[imp]; !isLocal || imp == file {
+				continue
+			}
+
+			switch state[imp] {
+			case unvisited:
+				visit(imp)
+			case visiting:
+				cycle := append(slices.Clone(stack[slices.Index(stack, imp):]), imp)
+				err = errors.Join(err, fmt.Errorf("Import cycle detected: %s", strings.Join(cycle, " -> ")))
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[file] = visited
+	}
+
+	for _, file := range slices.Sorted(maps.Keys(imports)) {
+		if state[file] == unvisited {
+			visit(file)
+		}
+	}
+
+	return err
+}
+
+// Returns the name of an element of the target package, as it should be referenced from the given package.
+// In a workspace, the name is fully qualified with a leading dot if the relative name could be resolved to a different package of the workspace, following the protobuf scoping rules (i.e. "common.v1.Item" referenced from "myapp.common.v1" or "myapp.v1", when "myapp.common" is a package in the workspace).
+func referenceName(from *ProtoPackage, target *ProtoPackage, name string) string {
+	fullName := target.GetName() + "." + name
+
+	if from == nil || from.workspace == nil {
+		return fullName
+	}
+
+	firstSegment, _, _ := strings.Cut(target.GetName(), ".")
+
+	for scope := from.GetName(); scope != ""; scope = parentScope(scope) {
+		candidate := scope + "." + firstSegment
+
+		for _, p := range from.workspace.packages {
+			if p.GetName() == candidate || strings.HasPrefix(p.GetName(), candidate+".") {
+				return "." + fullName
+			}
+		}
+	}
+
+	return fullName
+}
+
+// Returns the parent of a scope (i.e. "myapp.v1" -> "myapp"), or an empty string for the root scope.
+func parentScope(scope string) string {
+	if idx := strings.LastIndex(scope, "."); idx != -1 {
+		return scope[:idx]
+	}
+
+	return ""
+}
+
+// Returns true if both packages belong to the same workspace.
+func (p *ProtoPackage) sharesWorkspace(other *ProtoPackage) bool {
+	return p != nil && other != nil && p.workspace != nil && p.workspace == other.workspace
+}
+
+// Returns the go import path of the package's converter, if it can be determined from the GoModule and the (relative) ConverterOutputDir.
+func (p *ProtoPackage) getConverterImportPath() string {
+	if p.goModule == "" || filepath.IsAbs(p.converterOutputDir) {
+		return ""
+	}
+
+	return path.Join(p.goModule, filepath.ToSlash(p.converterOutputDir))
+}
@@ -0,0 +1,121 @@
+package protoschema_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	sb "github.com/Rick-Phoenix/protoschema"
+	"github.com/stretchr/testify/assert"
+)
+
+type Address struct {
+	City string `json:"city"`
+}
+
+type Customer struct {
+	Name      string     `json:"name"`
+	Address   *Address   `json:"address"`
+	Addresses []*Address `json:"addresses"`
+}
+
+func TestWorkspace(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+
+	workspace := sb.NewWorkspace()
+
+	app := workspace.NewPackage(sb.ProtoPackageConfig{
+		Name:               "myapp.v1",
+		GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/myappv1",
+		GoModule:           "github.com/Rick-Phoenix/protoschema",
+		ProtoRoot:          "proto",
+		ConverterOutputDir: "gen/myappconverter",
+		DescriptorSetFile:  "descriptors.binpb",
+	})
+
+	common := workspace.NewPackage(sb.ProtoPackageConfig{
+		Name:               "common.v1",
+		GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/commonv1",
+		GoModule:           "github.com/Rick-Phoenix/protoschema",
+		ProtoRoot:          "proto",
+		ConverterOutputDir: "gen/commonconverter",
+	})
+
+	// Shadows "common.v1" when referenced with a relative name from "myapp.v1"
+	workspace.NewPackage(sb.ProtoPackageConfig{
+		Name:               "myapp.common.v1",
+		GoPackage:          "github.com/Rick-Phoenix/protoschema/gen/myappcommonv1",
+		ProtoRoot:          "proto",
+		ConverterOutputDir: "gen/myappcommonconverter",
+	}).NewFile(sb.FileSchema{Name: "shadow"})
+
+	address := common.NewFile(sb.FileSchema{Name: "address"}).NewMessage(sb.MessageSchema{
+		Name:   "Address",
+		Fields: sb.FieldsMap{1: sb.String("city")},
+		Model:  &Address{},
+	})
+
+	app.NewFile(sb.FileSchema{Name: "customer"}).NewMessage(sb.MessageSchema{
+		Name: "Customer",
+		Fields: sb.FieldsMap{
+			1: sb.String("name"),
+			2: sb.MsgField("address", address),
+			3: sb.Repeated("addresses", sb.MsgField("address", address)),
+		},
+		Model: &Customer{},
+	})
+
+	assert.Same(t, common, workspace.GetPackage("common.v1"))
+	assert.Len(t, workspace.GetPackages(), 3)
+
+	assert.NoError(t, workspace.Generate())
+
+	customerFile, err := os.ReadFile(filepath.Join("proto", "myapp/v1/customer.proto"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(customerFile), `import "common/v1/address.proto";`)
+	assert.Contains(t, string(customerFile), ".common.v1.Address address = 2;")
+
+	converter, err := os.ReadFile(filepath.Join("gen/myappconverter", "myappconverter.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(converter), `"github.com/Rick-Phoenix/protoschema/gen/commonconverter"`)
+	assert.Contains(t, string(converter), "commonconverter.AddressToAddressMsg(Customer.Address)")
+	assert.Contains(t, string(converter), "commonconverter.AddresssToAddresssMsg(Customer.Addresses)")
+
+	commonConverter, err := os.ReadFile(filepath.Join("gen/commonconverter", "commonconverter.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(commonConverter), "func AddressToAddressMsg(")
+	assert.Contains(t, string(commonConverter), "func AddresssToAddresssMsg(")
+
+	_, err = os.Stat("descriptors.binpb")
+	assert.NoError(t, err)
+
+	newWorkspace := func() (*sb.Workspace, *sb.ProtoPackage, *sb.ProtoPackage) {
+		w := sb.NewWorkspace()
+		first := w.NewPackage(sb.ProtoPackageConfig{Name: "first.v1", GoPackage: "github.com/Rick-Phoenix/protoschema/gen/firstv1", ProtoRoot: t.TempDir(), ConverterOutputDir: "gen/firstconverter"})
+		second := w.NewPackage(sb.ProtoPackageConfig{Name: "second.v1", GoPackage: "github.com/Rick-Phoenix/protoschema/gen/secondv1", ProtoRoot: t.TempDir(), ConverterOutputDir: "gen/secondconverter"})
+		return w, first, second
+	}
+
+	shouldFail := map[string]func() *sb.Workspace{
+		"import cycle between packages": func() *sb.Workspace {
+			w, first, second := newWorkspace()
+			firstFile := first.NewFile(sb.FileSchema{Name: "a"})
+			secondFile := second.NewFile(sb.FileSchema{Name: "b"})
+			a := firstFile.NewMessage(sb.MessageSchema{Name: "A", Fields: sb.FieldsMap{1: sb.String("name")}})
+			b := secondFile.NewMessage(sb.MessageSchema{Name: "B", Fields: sb.FieldsMap{1: sb.MsgField("a", a)}})
+			firstFile.NewMessage(sb.MessageSchema{Name: "C", Fields: sb.FieldsMap{1: sb.MsgField("b", b)}})
+			return w
+		},
+		"same converter package": func() *sb.Workspace {
+			w, _, _ := newWorkspace()
+			w.NewPackage(sb.ProtoPackageConfig{Name: "third.v1", GoPackage: "github.com/Rick-Phoenix/protoschema/gen/thirdv1", ProtoRoot: t.TempDir(), ConverterOutputDir: "gen/other/firstconverter"})
+			return w
+		},
+	}
+
+	for name, build := range shouldFail {
+		_, err := build().TryBuildFiles()
+		assert.Error(t, err, name)
+	}
+}